@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func (app *application) loginHandler(w http.ResponseWriter, r *http.Request) {
+	var req service.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+
+	token, err := app.authService.Login(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			app.invalidCredentialsResponse(w, r)
+		case service.IsValidationError(err):
+			app.badRequestErrorResponse(w, r, err, err.Error())
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	token, _ := bearerToken(r)
+
+	if err := app.authService.Logout(r.Context(), token); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/khatibomar/gomania/internal/cache"
+)
+
+const (
+	defaultCacheKeysLimit = 100
+	maxCacheKeysLimit     = 1000
+)
+
+// listCacheKeysHandler lists cached keys under ?prefix=, so operators can see
+// what a prefix currently holds before purging it.
+func (app *application) listCacheKeysHandler(w http.ResponseWriter, r *http.Request) {
+	inspector, ok := app.cache.(cache.Inspector)
+	if !ok {
+		app.errorResponse(w, r, http.StatusNotImplemented, "the configured cache cannot list its keys")
+		return
+	}
+
+	limit := defaultCacheKeysLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCacheKeysLimit {
+			app.badRequestErrorResponse(w, r, errors.New("invalid limit"), "limit must be between 1 and 1000")
+			return
+		}
+		limit = n
+	}
+
+	keys := inspector.Keys(r.URL.Query().Get("prefix"))
+	total := len(keys)
+	if total > limit {
+		keys = keys[:limit]
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"keys": keys, "count": total}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// purgeCacheKeysHandler removes every cached key under ?prefix=. A prefix is
+// required so an empty query string cannot wipe the whole cache.
+func (app *application) purgeCacheKeysHandler(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		app.badRequestErrorResponse(w, r, errors.New("missing prefix"), "prefix is required")
+		return
+	}
+
+	invalidator, ok := app.cache.(cache.PrefixInvalidator)
+	if !ok {
+		app.errorResponse(w, r, http.StatusNotImplemented, "the configured cache cannot purge by prefix")
+		return
+	}
+
+	purged := invalidator.InvalidatePrefix(prefix)
+	app.logger.Info("Cache prefix purged", "prefix", prefix, "purged", purged, "user", app.contextGetUser(r).Email)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"prefix": prefix, "purged": purged}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
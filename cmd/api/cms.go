@@ -2,8 +2,8 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/khatibomar/gomania/internal/service"
@@ -18,12 +18,7 @@ func (app *application) createProgramHandler(w http.ResponseWriter, r *http.Requ
 
 	program, err := app.programService.CreateProgram(r.Context(), req)
 	if err != nil {
-		var errAlreadyExists *service.ErrAlreadyExists
-		if errors.As(err, &errAlreadyExists) {
-			app.conflictResponse(w, r, errAlreadyExists.Error())
-			return
-		}
-		app.serverErrorResponse(w, r, err)
+		app.programErrorResponse(w, r, err)
 		return
 	}
 
@@ -32,14 +27,34 @@ func (app *application) createProgramHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// listProgramsHandler lists programs in every status, unless narrowed down
+// with ?status.
 func (app *application) listProgramsHandler(w http.ResponseWriter, r *http.Request) {
-	programs, err := app.programService.ListPrograms(r.Context())
+	filter, err := app.readProgramFilter(r)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.badRequestErrorResponse(w, r, err, err.Error())
+		return
+	}
+
+	app.listPrograms(w, r, filter)
+}
+
+func (app *application) listPrograms(w http.ResponseWriter, r *http.Request, filter service.ProgramFilter) {
+	page, err := app.readPageRequest(r)
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "limit must be an integer")
 		return
 	}
 
-	if err := app.writeJSON(w, http.StatusOK, envelope{"programs": programs}, nil); err != nil {
+	req := service.ListProgramsRequest{ProgramFilter: filter, PageRequest: page}
+	programs, err := app.programService.ListPrograms(r.Context(), req)
+	if err != nil {
+		app.listErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"programs": programs.Items, "next_cursor": programs.NextCursor}
+	if err := app.writeJSON(w, http.StatusOK, env, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
@@ -52,13 +67,14 @@ func (app *application) getProgramHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	program, err := app.programService.GetProgram(r.Context(), id)
+	program, err := app.programService.GetProgramForEdit(r.Context(), id)
 	if err != nil {
-		app.notFoundResponse(w, r)
+		app.programErrorResponse(w, r, err)
 		return
 	}
 
-	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, nil); err != nil {
+	headers := http.Header{"ETag": {programETag(program.Version)}}
+	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, headers); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
@@ -77,14 +93,49 @@ func (app *application) updateProgramHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	req.ID = id
+	req.UserID = app.contextGetUser(r).ID.Bytes
+	req.IfMatch = readIfMatch(r)
 
 	program, err := app.programService.UpdateProgram(r.Context(), req)
 	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	headers := http.Header{"ETag": {programETag(program.Version)}}
+	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, headers); err != nil {
 		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// patchProgramHandler applies a JSON Merge Patch to a program, so that
+// editors only send the fields they change.
+func (app *application) patchProgramHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
 		return
 	}
 
-	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, nil); err != nil {
+	req := service.PatchProgramRequest{
+		ID:      id,
+		UserID:  app.contextGetUser(r).ID.Bytes,
+		IfMatch: readIfMatch(r),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req.Patch); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+
+	program, err := app.programService.PatchProgram(r.Context(), req)
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	headers := http.Header{"ETag": {programETag(program.Version)}}
+	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, headers); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
@@ -99,13 +150,75 @@ func (app *application) deleteProgramHandler(w http.ResponseWriter, r *http.Requ
 
 	err = app.programService.DeleteProgram(r.Context(), id)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.programErrorResponse(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (app *application) listTrashHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := app.readPageRequest(r)
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "limit must be an integer")
+		return
+	}
+
+	programs, err := app.programService.ListTrash(r.Context(), page)
+	if err != nil {
+		app.listErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"programs": programs.Items, "next_cursor": programs.NextCursor}
+	if err := app.writeJSON(w, http.StatusOK, env, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) restoreProgramHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	program, err := app.programService.RestoreProgram(r.Context(), id)
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) setProgramStatusHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	var req service.SetProgramStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ID = id
+
+	program, err := app.programService.SetProgramStatus(r.Context(), req)
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
 // Category handlers
 func (app *application) createCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	var req service.CategoryRequest
@@ -116,12 +229,7 @@ func (app *application) createCategoryHandler(w http.ResponseWriter, r *http.Req
 
 	category, err := app.programService.CreateCategory(r.Context(), req)
 	if err != nil {
-		var errAlreadyExists *service.ErrAlreadyExists
-		if errors.As(err, &errAlreadyExists) {
-			app.conflictResponse(w, r, errAlreadyExists.Error())
-			return
-		}
-		app.serverErrorResponse(w, r, err)
+		app.categoryErrorResponse(w, r, err)
 		return
 	}
 
@@ -142,6 +250,101 @@ func (app *application) listCategoriesHandler(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func (app *application) categoryTreeHandler(w http.ResponseWriter, r *http.Request) {
+	tree, err := app.programService.GetCategoryTree(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"categories": tree}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid category ID")
+		return
+	}
+
+	var req service.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ID = id
+
+	category, err := app.programService.UpdateCategory(r.Context(), req)
+	if err != nil {
+		app.categoryErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"category": category}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteCategoryHandler takes the orphan policy from the query string:
+// ?policy=reject (the default), ?policy=nullify, or
+// ?policy=reassign&target_id=<id>.
+func (app *application) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid category ID")
+		return
+	}
+
+	req := service.DeleteCategoryRequest{ID: id, Policy: service.OrphanReject, UserID: app.contextGetUser(r).ID.Bytes}
+	query := r.URL.Query()
+	if policy := query.Get("policy"); policy != "" {
+		req.Policy = service.OrphanPolicy(policy)
+	}
+	if target := query.Get("target_id"); target != "" {
+		req.TargetID, err = uuid.Parse(target)
+		if err != nil {
+			app.badRequestErrorResponse(w, r, err, "invalid target category ID")
+			return
+		}
+	}
+
+	if _, err := app.programService.DeleteCategory(r.Context(), req); err != nil {
+		app.categoryErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) mergeCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid category ID")
+		return
+	}
+
+	var req service.MergeCategoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ID = id
+	req.UserID = app.contextGetUser(r).ID.Bytes
+
+	category, moved, err := app.programService.MergeCategories(r.Context(), req)
+	if err != nil {
+		app.categoryErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"category": category, "moved_programs": moved}
+	if err := app.writeJSON(w, http.StatusOK, env, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
 func (app *application) getProgramsByCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := uuid.Parse(idStr)
@@ -150,13 +353,29 @@ func (app *application) getProgramsByCategoryHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	programs, err := app.programService.GetProgramsByCategory(r.Context(), id)
+	page, err := app.readPageRequest(r)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.badRequestErrorResponse(w, r, err, "limit must be an integer")
+		return
+	}
+
+	req := service.CategoryProgramsRequest{CategoryID: id, PageRequest: page}
+	if v := r.URL.Query().Get("include_descendants"); v != "" {
+		req.IncludeDescendants, err = strconv.ParseBool(v)
+		if err != nil {
+			app.badRequestErrorResponse(w, r, err, "include_descendants must be true or false")
+			return
+		}
+	}
+
+	programs, err := app.programService.GetProgramsByCategory(r.Context(), req)
+	if err != nil {
+		app.listErrorResponse(w, r, err)
 		return
 	}
 
-	if err := app.writeJSON(w, http.StatusOK, envelope{"programs": programs}, nil); err != nil {
+	env := envelope{"programs": programs.Items, "next_cursor": programs.NextCursor}
+	if err := app.writeJSON(w, http.StatusOK, env, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
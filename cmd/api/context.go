@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+type contextKey string
+
+const userContextKey = contextKey("user")
+
+func (app *application) contextSetUser(r *http.Request, user *database.GetUserForTokenRow) *http.Request {
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	return r.WithContext(ctx)
+}
+
+// contextGetUser returns the authenticated user, or nil for anonymous requests.
+func (app *application) contextGetUser(r *http.Request) *database.GetUserForTokenRow {
+	user, _ := r.Context().Value(userContextKey).(*database.GetUserForTokenRow)
+	return user
+}
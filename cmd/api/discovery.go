@@ -1,61 +1,85 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/khatibomar/gomania/internal/service"
+	"github.com/khatibomar/gomania/internal/sources"
 )
 
+// discoveryHandler lists or searches the programs visible to the public, which
+// are only ever the published ones whatever status is asked for.
 func (app *application) discoveryHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := app.readProgramFilter(r)
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, err.Error())
+		return
+	}
+	filter.Status = service.StatusPublished
+
 	searchQuery := r.URL.Query().Get("q")
 	if searchQuery != "" {
-		app.searchProgramsHandler(w, r, searchQuery)
+		app.searchProgramsHandler(w, r, searchQuery, filter)
 		return
 	}
 
-	app.listProgramsHandler(w, r)
+	app.listPrograms(w, r, filter)
 }
 
-func (app *application) searchProgramsHandler(w http.ResponseWriter, r *http.Request, query string) {
+func (app *application) searchProgramsHandler(w http.ResponseWriter, r *http.Request, query string, filter service.ProgramFilter) {
+	page, err := app.readPageRequest(r)
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "limit must be an integer")
+		return
+	}
+
 	req := service.SearchRequest{
-		Query: query,
+		Query:         query,
+		ProgramFilter: filter,
+		PageRequest:   page,
 	}
 
 	programs, err := app.programService.SearchPrograms(r.Context(), req)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.listErrorResponse(w, r, err)
+		return
+	}
+
+	facets, err := app.programService.SearchTagFacets(r.Context(), req)
+	if err != nil {
+		app.listErrorResponse(w, r, err)
 		return
 	}
 
 	response := map[string]any{
-		"query":   query,
-		"results": programs,
-		"count":   len(programs),
+		"query":       query,
+		"results":     programs.Items,
+		"count":       len(programs.Items),
+		"next_cursor": programs.NextCursor,
+		"facets":      map[string]any{"tags": facets},
 		"sources": map[string]any{
 			"local": map[string]any{
-				"count": len(programs),
+				"count": len(programs.Items),
 			},
 		},
 	}
 
-	// If no local results found, search external sources
-	if len(programs) == 0 {
+	// If no local results found, search external sources. Later pages that
+	// come back empty just mean the local results are exhausted.
+	if len(programs.Items) == 0 && page.Cursor == "" {
 		app.logger.Info("No local results found, searching external sources", "query", query)
 
-		externalResults, err := app.sourcesManager.SearchAllSources(r.Context(), query, 10)
-		if err != nil {
-			app.logger.Error("Failed to search external sources", "query", query, "error", err)
-		} else {
-			response["sources"].(map[string]any)["external"] = externalResults
-
-			// Count total external results
-			totalExternal := 0
-			for _, podcasts := range externalResults {
-				totalExternal += len(podcasts)
-			}
-			response["external_count"] = totalExternal
+		external := app.sourcesManager.SearchAllSources(r.Context(), query, 10)
+		failed := external.Failed()
+		if len(failed) > 0 {
+			app.logger.Warn("Some external sources failed", "query", query, "sources", failed)
 		}
+
+		response["sources"].(map[string]any)["external"] = external.Sources
+		response["external_count"] = external.Count()
+		response["external_failed"] = failed
 	}
 
 	if err := app.writeJSON(w, http.StatusOK, envelope{"search": response}, nil); err != nil {
@@ -66,13 +90,13 @@ func (app *application) searchProgramsHandler(w http.ResponseWriter, r *http.Req
 func (app *application) searchExternalSourcesHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
-		app.badRequestErrorResponse(w, r, nil, "search query is required")
+		app.badRequestErrorResponse(w, r, errors.New("search query is required"), "search query is required")
 		return
 	}
 
 	sourceName := r.URL.Query().Get("source")
 	if sourceName == "" {
-		app.badRequestErrorResponse(w, r, nil, "source parameter is required")
+		app.badRequestErrorResponse(w, r, errors.New("source parameter is required"), "source parameter is required")
 		return
 	}
 
@@ -88,6 +112,10 @@ func (app *application) searchExternalSourcesHandler(w http.ResponseWriter, r *h
 
 	podcasts, err := app.sourcesManager.SearchBySource(r.Context(), sourceName, query, limit)
 	if err != nil {
+		if errors.Is(err, sources.ErrUnknownSource) {
+			app.badRequestErrorResponse(w, r, err, err.Error())
+			return
+		}
 		app.serverErrorResponse(w, r, err)
 		return
 	}
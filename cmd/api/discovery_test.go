@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+func TestSearchExternalSourcesHandler_BadRequest(t *testing.T) {
+	app := newTestApplication(t)
+	app.sourcesManager = sources.NewManager(time.Second)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"source=itunes", "search query is required"},
+		{"q=tech", "source parameter is required"},
+		{"q=tech&source=nowhere", "unknown source"},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/external/search?"+tt.query, nil)
+
+		app.searchExternalSourcesHandler(rr, r)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, tt.query, rr.Code)
+		}
+		if msg, _ := readError(t, rr).(string); !strings.HasPrefix(msg, tt.want) {
+			t.Errorf("Expected %q for %s, got %q", tt.want, tt.query, msg)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func (app *application) createEpisodeHandler(w http.ResponseWriter, r *http.Request) {
+	programID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	var req service.CreateEpisodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ProgramID = programID
+
+	episode, err := app.episodeService.CreateEpisode(r.Context(), req)
+	if err != nil {
+		app.episodeErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"episode": episode}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listEpisodesHandler(w http.ResponseWriter, r *http.Request) {
+	programID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	if _, err := app.programService.GetProgram(r.Context(), programID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	episodes, err := app.episodeService.ListEpisodes(r.Context(), programID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"episodes": episodes}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) getEpisodeHandler(w http.ResponseWriter, r *http.Request) {
+	programID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	episodeID, err := uuid.Parse(r.PathValue("episode_id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid episode ID")
+		return
+	}
+
+	episode, err := app.episodeService.GetEpisode(r.Context(), programID, episodeID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"episode": episode}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateEpisodeHandler(w http.ResponseWriter, r *http.Request) {
+	programID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	episodeID, err := uuid.Parse(r.PathValue("episode_id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid episode ID")
+		return
+	}
+
+	var req service.UpdateEpisodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ID = episodeID
+	req.ProgramID = programID
+
+	episode, err := app.episodeService.UpdateEpisode(r.Context(), req)
+	if err != nil {
+		app.episodeErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"episode": episode}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteEpisodeHandler(w http.ResponseWriter, r *http.Request) {
+	programID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	episodeID, err := uuid.Parse(r.PathValue("episode_id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid episode ID")
+		return
+	}
+
+	err = app.episodeService.DeleteEpisode(r.Context(), programID, episodeID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEpisodeHandlers_InvalidIDs(t *testing.T) {
+	app := newTestApplication(t)
+	handlers := map[string]http.HandlerFunc{
+		http.MethodGet:    app.getEpisodeHandler,
+		http.MethodPut:    app.updateEpisodeHandler,
+		http.MethodDelete: app.deleteEpisodeHandler,
+	}
+	tests := []struct {
+		programID string
+		episodeID string
+		want      string
+	}{
+		{"not-a-uuid", uuid.NewString(), "invalid program ID"},
+		{uuid.NewString(), "not-a-uuid", "invalid episode ID"},
+	}
+
+	for method, handler := range handlers {
+		for _, tt := range tests {
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(method, "/v1/cms/programs/"+tt.programID+"/episodes/"+tt.episodeID, strings.NewReader(`{}`))
+			r.SetPathValue("id", tt.programID)
+			r.SetPathValue("episode_id", tt.episodeID)
+
+			handler(rr, r)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, method, rr.Code)
+			}
+			if msg := readError(t, rr); msg != tt.want {
+				t.Errorf("Expected %q for %s, got %v", tt.want, method, msg)
+			}
+		}
+	}
+}
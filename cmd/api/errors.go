@@ -2,7 +2,11 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/khatibomar/gomania/internal/service"
 )
 
 func (app *application) logError(r *http.Request, err error) {
@@ -37,6 +41,13 @@ func (app *application) badRequestErrorResponse(w http.ResponseWriter, r *http.R
 	app.errorResponse(w, r, http.StatusBadRequest, message)
 }
 
+func (app *application) badGatewayResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logError(r, err)
+
+	message := "the upstream source could not be reached or returned an invalid response"
+	app.errorResponse(w, r, http.StatusBadGateway, message)
+}
+
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "the requested resource could not be found"
 	app.logError(r, errors.New(message))
@@ -47,3 +58,135 @@ func (app *application) conflictResponse(w http.ResponseWriter, r *http.Request,
 	app.logError(r, errors.New(message)) // Log the conflict as an error
 	app.errorResponse(w, r, http.StatusConflict, message)
 }
+
+func (app *application) preconditionFailedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logError(r, err)
+
+	message := "the resource has changed since you fetched it, please fetch it again and retry"
+	app.errorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
+func (app *application) invalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	message := "invalid authentication credentials"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (app *application) invalidAuthenticationTokenResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "invalid or missing authentication token"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (app *application) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "you must be authenticated to access this resource"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+// listErrorResponse maps errors returned by paginated service calls to a response.
+func (app *application) listErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCursor):
+		app.badRequestErrorResponse(w, r, err, "invalid cursor")
+	case errors.Is(err, service.ErrInvalidTag), errors.Is(err, service.ErrInvalidFilter):
+		app.badRequestErrorResponse(w, r, err, err.Error())
+	case service.IsValidationError(err):
+		app.badRequestErrorResponse(w, r, err, listValidationMessage(err))
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listValidationMessage describes the parameter of a list request that failed
+// validation.
+func listValidationMessage(err error) string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return err.Error()
+	}
+
+	switch fe := errs[0]; fe.Field() {
+	case "Limit":
+		return fmt.Sprintf("limit must be between 1 and %d", service.MaxPageLimit)
+	case "Cursor":
+		return "invalid cursor"
+	case "Query":
+		if fe.Tag() == "max" {
+			return "query too long"
+		}
+		return "search query is required"
+	default:
+		return err.Error()
+	}
+}
+
+// episodeErrorResponse maps errors returned when changing an episode to a
+// response.
+func (app *application) episodeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var errAlreadyExists *service.ErrAlreadyExists
+	switch {
+	case errors.As(err, &errAlreadyExists):
+		app.conflictResponse(w, r, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		app.notFoundResponse(w, r)
+	case service.IsValidationError(err):
+		app.badRequestErrorResponse(w, r, err, err.Error())
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// programErrorResponse maps errors returned when changing a program to a
+// response.
+func (app *application) programErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var errAlreadyExists *service.ErrAlreadyExists
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+	case errors.As(err, &errAlreadyExists):
+		app.conflictResponse(w, r, err.Error())
+	case errors.Is(err, service.ErrEditConflict):
+		app.preconditionFailedResponse(w, r, err)
+	case errors.Is(err, service.ErrInvalidPublishAt), errors.Is(err, service.ErrInvalidPatch), service.IsValidationError(err):
+		app.badRequestErrorResponse(w, r, err, err.Error())
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// tagErrorResponse maps errors returned when managing tags to a response.
+func (app *application) tagErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var errAlreadyExists *service.ErrAlreadyExists
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+	case errors.As(err, &errAlreadyExists):
+		app.conflictResponse(w, r, err.Error())
+	case errors.Is(err, service.ErrInvalidTag), service.IsValidationError(err):
+		app.badRequestErrorResponse(w, r, err, err.Error())
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// categoryErrorResponse maps errors returned when changing a category to a response.
+func (app *application) categoryErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var errAlreadyExists *service.ErrAlreadyExists
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+	case errors.As(err, &errAlreadyExists), errors.Is(err, service.ErrCategoryNotEmpty):
+		app.conflictResponse(w, r, err.Error())
+	case errors.Is(err, service.ErrSameCategory), errors.Is(err, service.ErrCategoryCycle), service.IsValidationError(err):
+		app.badRequestErrorResponse(w, r, err, err.Error())
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
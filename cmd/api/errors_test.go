@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func TestListErrorResponse_Validation(t *testing.T) {
+	app := newTestApplication(t)
+	validate := validator.New()
+
+	tests := []struct {
+		name string
+		req  any
+		want string
+	}{
+		{"limit", service.PageRequest{Limit: service.MaxPageLimit + 1}, "limit must be between 1 and 100"},
+		{"cursor", service.PageRequest{Cursor: strings.Repeat("a", 1501)}, "invalid cursor"},
+		{"long query", service.SearchRequest{Query: strings.Repeat("q", 101)}, "query too long"},
+		{"empty query", service.SearchRequest{}, "search query is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.req)
+			if !service.IsValidationError(err) {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+
+			rr := httptest.NewRecorder()
+			app.listErrorResponse(rr, httptest.NewRequest(http.MethodGet, "/v1/discovery/programs", nil), err)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			if msg := readError(t, rr); msg != tt.want {
+				t.Errorf("Expected %q, got %v", tt.want, msg)
+			}
+		})
+	}
+}
+
+func TestProgramErrorResponse(t *testing.T) {
+	app := newTestApplication(t)
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: program is at version 4", service.ErrEditConflict), http.StatusPreconditionFailed},
+		{fmt.Errorf("%w: program not found", service.ErrNotFound), http.StatusNotFound},
+		{&service.ErrAlreadyExists{Message: "exists"}, http.StatusConflict},
+		{fmt.Errorf("%w: not an object", service.ErrInvalidPatch), http.StatusBadRequest},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		app.programErrorResponse(rr, httptest.NewRequest(http.MethodPut, "/v1/cms/programs/1", nil), tt.err)
+
+		if rr.Code != tt.want {
+			t.Errorf("Expected status %d for %v, got %d", tt.want, tt.err, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/feed"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func (app *application) programFeedHandler(w http.ResponseWriter, r *http.Request) {
+	programID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	program, err := app.programService.GetProgram(r.Context(), programID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// Feeds are public, so programs that are not published do not have one.
+	if service.ProgramStatus(program.Status) != service.StatusPublished {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	episodes, err := app.episodeService.ListPublishedEpisodes(r.Context(), programID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	feedURL := strings.TrimRight(app.config.publicURL, "/") + r.URL.Path
+
+	var buf bytes.Buffer
+	if err := feed.New(program, episodes, feedURL).Write(&buf); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		// The status is already sent, so the client can only be told by the
+		// connection dropping.
+		app.logError(r, err)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+// programETag is the entity tag of a program at version.
+func programETag(version int32) string {
+	return `"` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+// readIfMatch returns the program versions listed in the If-Match header, or
+// nil when there is no header or it is "*". If-Match compares entity tags
+// strongly, so weak and malformed tags never match and are left out; a
+// header made of nothing else yields an empty list.
+func readIfMatch(r *http.Request) []int32 {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		return nil
+	}
+
+	versions := []int32{}
+	for _, value := range values {
+		for tag := range strings.SplitSeq(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" {
+				return nil
+			}
+			if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+				continue
+			}
+			version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 32)
+			if err != nil {
+				continue
+			}
+			versions = append(versions, int32(version))
+		}
+	}
+	return versions
+}
+
+// readPageRequest extracts the limit and cursor query parameters used by
+// paginated list endpoints. Range checks are left to the service layer.
+func (app *application) readPageRequest(r *http.Request) (service.PageRequest, error) {
+	qs := r.URL.Query()
+
+	page := service.PageRequest{
+		Cursor: qs.Get("cursor"),
+	}
+
+	if limitStr := qs.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return service.PageRequest{}, err
+		}
+		page.Limit = limit
+	}
+
+	return page, nil
+}
+
+// readProgramFilter extracts the filter and sort query parameters of program
+// listings. Dates may be RFC 3339 timestamps or plain YYYY-MM-DD days. Range
+// checks are left to the service layer.
+func (app *application) readProgramFilter(r *http.Request) (service.ProgramFilter, error) {
+	qs := r.URL.Query()
+
+	filter := service.ProgramFilter{
+		Language: qs.Get("language"),
+		Tags:     qs["tag"],
+		Status:   service.ProgramStatus(qs.Get("status")),
+		Sort:     service.ProgramSort(qs.Get("sort")),
+	}
+
+	if v := qs.Get("category"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return service.ProgramFilter{}, errors.New("category must be a category ID")
+		}
+		filter.CategoryID = &id
+	}
+
+	// Parameters are checked in a fixed order, so the same query always
+	// reports the same error.
+	durations := []struct {
+		name string
+		dst  *int
+	}{
+		{"min_duration", &filter.MinDuration},
+		{"max_duration", &filter.MaxDuration},
+	}
+	for _, d := range durations {
+		if v := qs.Get(d.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return service.ProgramFilter{}, fmt.Errorf("%s must be an integer", d.name)
+			}
+			*d.dst = n
+		}
+	}
+
+	dates := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+	}
+	for _, d := range dates {
+		if v := qs.Get(d.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				t, err = time.Parse(time.DateOnly, v)
+			}
+			if err != nil {
+				return service.ProgramFilter{}, fmt.Errorf("%s must be a date (YYYY-MM-DD) or an RFC 3339 timestamp", d.name)
+			}
+			*d.dst = t
+		}
+	}
+
+	return filter, nil
+}
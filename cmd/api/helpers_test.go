@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// newTestApplication returns an application without a database, for
+// handlers and middleware that answer before reaching a service.
+func newTestApplication(t *testing.T) *application {
+	t.Helper()
+
+	return &application{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// readError decodes the error message of an errorResponse.
+func readError(t *testing.T, rr *httptest.ResponseRecorder) any {
+	t.Helper()
+
+	var body struct {
+		Error any `json:"error"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	return body.Error
+}
+
+func TestReadProgramFilter_ErrorOrder(t *testing.T) {
+	app := newTestApplication(t)
+
+	// Every invalid parameter is wrong here; the first one checked wins.
+	for range 20 {
+		r := httptest.NewRequest(http.MethodGet, "/v1/programs?max_duration=y&min_duration=x&created_before=b&created_after=a", nil)
+		_, err := app.readProgramFilter(r)
+		if err == nil || err.Error() != "min_duration must be an integer" {
+			t.Fatalf("Expected the min_duration error, got %v", err)
+		}
+	}
+}
+
+func TestProgramETag(t *testing.T) {
+	if got := programETag(7); got != `"7"` {
+		t.Errorf(`Expected "7", got %s`, got)
+	}
+}
+
+func TestReadIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   []int32
+	}{
+		{"no header", nil, nil},
+		{"strong tag", []string{`"7"`}, []int32{7}},
+		{"our own ETag", []string{programETag(12)}, []int32{12}},
+		{"comma separated", []string{`"7", "8"`}, []int32{7, 8}},
+		{"repeated header", []string{`"7"`, `"8"`}, []int32{7, 8}},
+		{"any", []string{"*"}, nil},
+		{"any in a list", []string{`"7", *`}, nil},
+		{"weak tag", []string{`W/"7"`}, []int32{}},
+		{"weak and strong", []string{`W/"7", "8"`}, []int32{8}},
+		{"unquoted", []string{"7"}, []int32{}},
+		{"not a version", []string{`"abc"`}, []int32{}},
+		{"empty", []string{""}, []int32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/v1/cms/programs/1", nil)
+			for _, v := range tt.header {
+				r.Header.Add("If-Match", v)
+			}
+
+			got := readIfMatch(r)
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/khatibomar/gomania/internal/service"
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+func (app *application) importProgramHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Source     string `json:"source"`
+		ExternalID string `json:"external_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	if input.Source == "" || input.ExternalID == "" {
+		app.badRequestErrorResponse(w, r, errors.New("source and external_id are required"), "source and external_id are required")
+		return
+	}
+
+	podcast, err := app.sourcesManager.GetPodcast(r.Context(), input.Source, input.ExternalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sources.ErrUnknownSource):
+			app.badRequestErrorResponse(w, r, err, err.Error())
+		case errors.Is(err, sources.ErrPodcastNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.badGatewayResponse(w, r, err)
+		}
+		return
+	}
+
+	program, err := app.programService.ImportProgram(r.Context(), input.Source, *podcast, app.contextGetUser(r).ID.Bytes)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImport) {
+			app.badRequestErrorResponse(w, r, err, err.Error())
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if program.Inserted {
+		status = http.StatusCreated
+	}
+
+	if err := app.writeJSON(w, status, envelope{"program": program}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
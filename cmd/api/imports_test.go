@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImportProgramHandler_MissingFields(t *testing.T) {
+	app := newTestApplication(t)
+
+	for _, body := range []string{`{}`, `{"source":"itunes"}`, `{"external_id":"123"}`} {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/v1/cms/import", strings.NewReader(body))
+
+		app.importProgramHandler(rr, r)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, body, rr.Code)
+		}
+		if msg := readError(t, rr); msg != "source and external_id are required" {
+			t.Errorf("Expected a missing field error for %s, got %v", body, msg)
+		}
+	}
+}
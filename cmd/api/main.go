@@ -2,25 +2,57 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"flag"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/cache/pgnotify"
 	"github.com/khatibomar/gomania/internal/service"
 	"github.com/khatibomar/gomania/internal/sources"
 	"github.com/khatibomar/gomania/internal/sources/itunes"
+	"github.com/khatibomar/gomania/internal/sources/rss"
 )
 
 type config struct {
-	port int
-	env  string
-	cors struct {
+	port      int
+	env       string
+	publicURL string
+	cors      struct {
 		trustedOrigins []string
 	}
+	auth struct {
+		tokenTTL time.Duration
+	}
+	cache struct {
+		backend    string
+		sync       bool
+		ttl        time.Duration
+		freshFor   time.Duration
+		maxEntries int
+		maxBytes   int64
+	}
+	redis struct {
+		addr string
+		db   int
+	}
+	sources struct {
+		timeout  time.Duration
+		rssFeeds []string
+	}
+	publish struct {
+		interval time.Duration
+	}
+	trash struct {
+		retention time.Duration
+	}
 }
 
 type application struct {
@@ -28,13 +60,34 @@ type application struct {
 	config         config
 	logger         *slog.Logger
 	db             *pgxpool.Pool
+	cache          cache.Cache
 	programService *service.ProgramService
+	episodeService *service.EpisodeService
+	authService    *service.AuthService
+	userService    *service.UserService
 	sourcesManager *sources.Manager
 }
 
 func parseFlags(cfg *config) {
 	flag.IntVar(&cfg.port, "port", 4000, "API server port")
 	flag.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
+	flag.StringVar(&cfg.publicURL, "public-url", "http://localhost:4000", "Public base URL of the API, used in published podcast feeds")
+	flag.DurationVar(&cfg.auth.tokenTTL, "auth-token-ttl", 24*time.Hour, "Lifetime of CMS session tokens")
+	flag.StringVar(&cfg.cache.backend, "cache-backend", "memory", "Cache backend (memory|redis)")
+	flag.BoolVar(&cfg.cache.sync, "cache-sync", true, "Broadcast memory cache invalidations to other replicas through Postgres LISTEN/NOTIFY")
+	flag.DurationVar(&cfg.cache.ttl, "cache-ttl", 15*time.Minute, "Lifetime of cached entries")
+	flag.DurationVar(&cfg.cache.freshFor, "cache-fresh-for", 5*time.Minute, "Age after which cached reads are refreshed in the background while still being served (0 = never)")
+	flag.IntVar(&cfg.cache.maxEntries, "cache-max-entries", 10000, "Maximum number of cached entries (0 = unlimited)")
+	flag.Int64Var(&cfg.cache.maxBytes, "cache-max-bytes", 64<<20, "Approximate memory budget for the cache in bytes (0 = unlimited)")
+	flag.StringVar(&cfg.redis.addr, "redis-addr", "localhost:6379", "Redis address used when -cache-backend=redis")
+	flag.IntVar(&cfg.redis.db, "redis-db", 0, "Redis logical database used when -cache-backend=redis")
+	flag.DurationVar(&cfg.publish.interval, "publish-interval", time.Minute, "Longest wait between checks for scheduled programs that are due to be published (0 = disabled)")
+	flag.DurationVar(&cfg.trash.retention, "trash-retention", 30*24*time.Hour, "How long deleted programs stay in the trash before they are purged (0 = forever)")
+	flag.DurationVar(&cfg.sources.timeout, "source-timeout", sources.DefaultSourceTimeout, "Per-source timeout for external searches")
+	flag.Func("rss-feeds", "Podcast RSS/Atom feed URLs to offer as an external source (space separated)", func(val string) error {
+		cfg.sources.rssFeeds = strings.Fields(val)
+		return nil
+	})
 	flag.Func("cors-trusted-origins", "Trusted CORS origins (space separated)", func(val string) error {
 		cfg.cors.trustedOrigins = strings.Fields(val)
 		return nil
@@ -73,18 +126,45 @@ func main() {
 	}
 	defer pool.Close()
 
-	programService := service.NewProgramService(pool, logger)
+	appCache, err := openCache(ctx, cfg, pool, logger)
+	if err != nil {
+		log.Fatalf("Failed to open cache: %v", err)
+	}
+	defer appCache.Close()
 
-	sourcesManager := sources.NewManager()
+	loader := cache.NewLoader(appCache, cache.LoaderOptions{
+		FreshFor: cfg.cache.freshFor,
+		Logger:   logger,
+	})
+
+	programService := service.NewProgramServiceWithLoader(pool, logger, loader)
+	if cfg.publish.interval > 0 {
+		go programService.RunPublishScheduler(ctx, cfg.publish.interval)
+	}
+	if cfg.trash.retention > 0 {
+		go programService.RunTrashPurge(ctx, cfg.trash.retention)
+	}
+	episodeService := service.NewEpisodeServiceWithLoader(pool, logger, loader)
+	authService := service.NewAuthService(pool, logger, cfg.auth.tokenTTL)
+	userService := service.NewUserService(pool, logger)
+
+	sourcesManager := sources.NewManager(cfg.sources.timeout)
 	itunesClient := itunes.NewClient()
 	sourcesManager.RegisterClient(itunesClient)
+	if len(cfg.sources.rssFeeds) > 0 {
+		sourcesManager.RegisterClient(rss.NewClient(cfg.sources.rssFeeds))
+	}
 
 	app := &application{
 		ctx:            ctx,
 		config:         cfg,
 		logger:         logger,
 		db:             pool,
+		cache:          appCache,
 		programService: programService,
+		episodeService: episodeService,
+		authService:    authService,
+		userService:    userService,
 		sourcesManager: sourcesManager,
 	}
 
@@ -92,3 +172,40 @@ func main() {
 		log.Fatalf("failed to start listening on server: %v", err)
 	}
 }
+
+// openCache builds the cache backend selected by -cache-backend. The memory
+// cache is private to this process, so unless -cache-sync is disabled its
+// invalidations are relayed to other replicas through Postgres. Redis is
+// shared by every replica, so CMS writes are visible everywhere at once.
+func openCache(ctx context.Context, cfg config, pool *pgxpool.Pool, logger *slog.Logger) (cache.Cache, error) {
+	switch cfg.cache.backend {
+	case "memory":
+		c := cache.NewWithOptions(cache.Options{
+			TTL:        cfg.cache.ttl,
+			MaxEntries: cfg.cache.maxEntries,
+			MaxBytes:   cfg.cache.maxBytes,
+		})
+		expvar.Publish("cache", expvar.Func(func() any { return c.Stats() }))
+		if !cfg.cache.sync {
+			return c, nil
+		}
+		synced := pgnotify.New(pool, c, logger)
+		go synced.Listen(ctx)
+		return synced, nil
+	case "redis":
+		c, err := cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.redis.addr,
+			Password: os.Getenv("GOMANIA_REDIS_PASSWORD"),
+			DB:       cfg.redis.db,
+			TTL:      cfg.cache.ttl,
+			Logger:   logger,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Using redis cache", "addr", cfg.redis.addr, "db", cfg.redis.db)
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.cache.backend)
+	}
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
+
+	"github.com/khatibomar/gomania/internal/service"
 )
 
 func (app *application) enableCORS(next http.Handler) http.Handler {
@@ -15,6 +19,8 @@ func (app *application) enableCORS(next http.Handler) http.Handler {
 		if origin != "" {
 			if slices.Contains(app.config.cors.trustedOrigins, origin) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
+				// Editors send a program's ETag back in If-Match.
+				w.Header().Set("Access-Control-Expose-Headers", "ETag")
 			}
 		}
 
@@ -49,3 +55,54 @@ func (app *application) logRequest(next http.Handler) http.Handler {
 		)
 	})
 }
+
+// authenticate resolves the bearer token, if any, to a user and stores it in
+// the request context. Requests without a token continue anonymously; routes
+// that need a user are wrapped with requireAuthenticatedUser.
+func (app *application) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		token, ok := bearerToken(r)
+		if !ok {
+			if r.Header.Get("Authorization") != "" {
+				app.invalidAuthenticationTokenResponse(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := app.authService.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidToken) {
+				app.invalidAuthenticationTokenResponse(w, r)
+				return
+			}
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, app.contextSetUser(r, user))
+	})
+}
+
+func (app *application) requireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if app.contextGetUser(r) == nil {
+			app.authenticationRequiredResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer abc123", "abc123", true},
+		{"bearer abc123", "abc123", true},
+		{"", "", false},
+		{"Bearer", "", false},
+		{"Bearer ", "", false},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"abc123", "", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/v1/cms/programs", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+
+		token, ok := bearerToken(r)
+		if token != tt.token || ok != tt.ok {
+			t.Errorf("Expected %q, %v for %q, got %q, %v", tt.token, tt.ok, tt.header, token, ok)
+		}
+	}
+}
+
+func TestAuthenticate_WithoutToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"anonymous", "", http.StatusOK},
+		{"malformed", "Bearer", http.StatusUnauthorized},
+		{"other scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			var anonymous bool
+			handler := app.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				anonymous = app.contextGetUser(r) == nil
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/v1/cms/programs", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			handler.ServeHTTP(rr, r)
+
+			if rr.Code != tt.want {
+				t.Fatalf("Expected status %d, got %d", tt.want, rr.Code)
+			}
+			if tt.want == http.StatusOK && !anonymous {
+				t.Error("Expected the request to go on without a user")
+			}
+			if tt.want == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("Expected a Bearer challenge, got %q", rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
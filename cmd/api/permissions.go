@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+type permission string
+
+const (
+	permissionContentRead   permission = "content:read"
+	permissionContentWrite  permission = "content:write"
+	permissionContentDelete permission = "content:delete"
+	permissionUsersManage   permission = "users:manage"
+	permissionCacheManage   permission = "cache:manage"
+)
+
+// rolePermissions maps each CMS role to what it may do. Content covers
+// programs, episodes and categories.
+var rolePermissions = map[string][]permission{
+	service.RoleViewer: {
+		permissionContentRead,
+	},
+	service.RoleEditor: {
+		permissionContentRead,
+		permissionContentWrite,
+	},
+	service.RoleAdmin: {
+		permissionContentRead,
+		permissionContentWrite,
+		permissionContentDelete,
+		permissionUsersManage,
+		permissionCacheManage,
+	},
+}
+
+func hasPermission(role string, p permission) bool {
+	return slices.Contains(rolePermissions[role], p)
+}
+
+// requirePermission only lets authenticated users whose role grants p through.
+func (app *application) requirePermission(p permission, next http.HandlerFunc) http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		user := app.contextGetUser(r)
+
+		if !hasPermission(user.Role, p) {
+			app.notPermittedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return app.requireAuthenticatedUser(fn)
+}
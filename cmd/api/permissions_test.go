@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/khatibomar/gomania/internal/database"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func TestHasPermission(t *testing.T) {
+	permissions := []permission{
+		permissionContentRead,
+		permissionContentWrite,
+		permissionContentDelete,
+		permissionUsersManage,
+		permissionCacheManage,
+	}
+	granted := map[string][]bool{
+		service.RoleViewer: {true, false, false, false, false},
+		service.RoleEditor: {true, true, false, false, false},
+		service.RoleAdmin:  {true, true, true, true, true},
+		"owner":            {false, false, false, false, false},
+		"":                 {false, false, false, false, false},
+	}
+
+	for role, want := range granted {
+		for i, p := range permissions {
+			if got := hasPermission(role, p); got != want[i] {
+				t.Errorf("Expected role %q to have %s: %v, got %v", role, p, want[i], got)
+			}
+		}
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name string
+		user *database.GetUserForTokenRow
+		want int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"viewer", &database.GetUserForTokenRow{Role: service.RoleViewer}, http.StatusForbidden},
+		{"editor", &database.GetUserForTokenRow{Role: service.RoleEditor}, http.StatusOK},
+		{"admin", &database.GetUserForTokenRow{Role: service.RoleAdmin}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			handler := app.requirePermission(permissionContentWrite, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/v1/cms/programs", nil)
+			if tt.user != nil {
+				r = app.contextSetUser(r, tt.user)
+			}
+
+			handler(rr, r)
+
+			if rr.Code != tt.want {
+				t.Fatalf("Expected status %d, got %d", tt.want, rr.Code)
+			}
+			if tt.want == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("Expected a Bearer challenge, got %q", rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+// readRevision parses a revision number, which counts from 1.
+func readRevision(s string) (int32, error) {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || n < 1 {
+		return 0, errors.New("revision must be a positive integer")
+	}
+	return int32(n), nil
+}
+
+func (app *application) listProgramRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	revisions, err := app.programService.ListProgramRevisions(r.Context(), id)
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"revisions": revisions}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) getProgramRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	revision, err := readRevision(r.PathValue("revision"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, err.Error())
+		return
+	}
+
+	rev, err := app.programService.GetProgramRevision(r.Context(), id, revision)
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"revision": rev}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) diffProgramRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	from, err := readRevision(r.URL.Query().Get("from"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "from must be a revision number")
+		return
+	}
+	to, err := readRevision(r.URL.Query().Get("to"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "to must be a revision number")
+		return
+	}
+
+	diff, err := app.programService.DiffProgramRevisions(r.Context(), id, from, to)
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"diff": diff}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) rollbackProgramHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	revision, err := readRevision(r.PathValue("revision"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, err.Error())
+		return
+	}
+
+	program, err := app.programService.RollbackProgram(r.Context(), service.RollbackProgramRequest{
+		ID:       id,
+		Revision: revision,
+		UserID:   app.contextGetUser(r).ID.Bytes,
+	})
+	if err != nil {
+		app.programErrorResponse(w, r, err)
+		return
+	}
+
+	headers := http.Header{"ETag": {programETag(program.Version)}}
+	if err := app.writeJSON(w, http.StatusOK, envelope{"program": program}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -12,24 +12,69 @@ func (app *application) routes() http.Handler {
 	mux.HandleFunc("GET /v1/healthcheck", app.healthcheckHandler)
 	mux.Handle("GET /debug/vars", expvar.Handler())
 
+	// authentication
+	mux.HandleFunc("POST /v1/auth/login", app.loginHandler)
+	mux.HandleFunc("POST /v1/auth/logout", app.requireAuthenticatedUser(app.logoutHandler))
+
 	// CMS Programs
-	mux.HandleFunc("POST /v1/cms/programs", app.createProgramHandler)
-	mux.HandleFunc("GET /v1/cms/programs", app.listProgramsHandler)
-	mux.HandleFunc("GET /v1/cms/programs/{id}", app.getProgramHandler)
-	mux.HandleFunc("PUT /v1/cms/programs/{id}", app.updateProgramHandler)
-	mux.HandleFunc("DELETE /v1/cms/programs/{id}", app.deleteProgramHandler)
+	mux.HandleFunc("POST /v1/cms/programs", app.requirePermission(permissionContentWrite, app.createProgramHandler))
+	mux.HandleFunc("GET /v1/cms/programs", app.requirePermission(permissionContentRead, app.listProgramsHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}", app.requirePermission(permissionContentRead, app.getProgramHandler))
+	mux.HandleFunc("PUT /v1/cms/programs/{id}", app.requirePermission(permissionContentWrite, app.updateProgramHandler))
+	mux.HandleFunc("PATCH /v1/cms/programs/{id}", app.requirePermission(permissionContentWrite, app.patchProgramHandler))
+	mux.HandleFunc("DELETE /v1/cms/programs/{id}", app.requirePermission(permissionContentDelete, app.deleteProgramHandler))
+	mux.HandleFunc("PUT /v1/cms/programs/{id}/status", app.requirePermission(permissionContentWrite, app.setProgramStatusHandler))
+	mux.HandleFunc("POST /v1/cms/programs/{id}/restore", app.requirePermission(permissionContentDelete, app.restoreProgramHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}/revisions", app.requirePermission(permissionContentRead, app.listProgramRevisionsHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}/revisions/diff", app.requirePermission(permissionContentRead, app.diffProgramRevisionsHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}/revisions/{revision}", app.requirePermission(permissionContentRead, app.getProgramRevisionHandler))
+	mux.HandleFunc("POST /v1/cms/programs/{id}/revisions/{revision}/rollback", app.requirePermission(permissionContentWrite, app.rollbackProgramHandler))
+	mux.HandleFunc("GET /v1/cms/trash", app.requirePermission(permissionContentRead, app.listTrashHandler))
+
+	// CMS Episodes
+	mux.HandleFunc("POST /v1/cms/programs/{id}/episodes", app.requirePermission(permissionContentWrite, app.createEpisodeHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}/episodes", app.requirePermission(permissionContentRead, app.listEpisodesHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}/episodes/{episode_id}", app.requirePermission(permissionContentRead, app.getEpisodeHandler))
+	mux.HandleFunc("PUT /v1/cms/programs/{id}/episodes/{episode_id}", app.requirePermission(permissionContentWrite, app.updateEpisodeHandler))
+	mux.HandleFunc("DELETE /v1/cms/programs/{id}/episodes/{episode_id}", app.requirePermission(permissionContentDelete, app.deleteEpisodeHandler))
 
 	// CMS Categories
-	mux.HandleFunc("POST /v1/cms/categories", app.createCategoryHandler)
-	mux.HandleFunc("GET /v1/cms/categories", app.listCategoriesHandler)
-	mux.HandleFunc("GET /v1/cms/categories/{id}/programs", app.getProgramsByCategoryHandler)
+	mux.HandleFunc("POST /v1/cms/categories", app.requirePermission(permissionContentWrite, app.createCategoryHandler))
+	mux.HandleFunc("GET /v1/cms/categories", app.requirePermission(permissionContentRead, app.listCategoriesHandler))
+	mux.HandleFunc("GET /v1/cms/categories/tree", app.requirePermission(permissionContentRead, app.categoryTreeHandler))
+	mux.HandleFunc("GET /v1/cms/categories/{id}/programs", app.requirePermission(permissionContentRead, app.getProgramsByCategoryHandler))
+	mux.HandleFunc("PUT /v1/cms/categories/{id}", app.requirePermission(permissionContentWrite, app.updateCategoryHandler))
+	mux.HandleFunc("DELETE /v1/cms/categories/{id}", app.requirePermission(permissionContentDelete, app.deleteCategoryHandler))
+	mux.HandleFunc("POST /v1/cms/categories/{id}/merge", app.requirePermission(permissionContentDelete, app.mergeCategoryHandler))
+
+	// CMS Tags
+	mux.HandleFunc("GET /v1/cms/tags", app.requirePermission(permissionContentRead, app.listTagsHandler))
+	mux.HandleFunc("POST /v1/cms/tags", app.requirePermission(permissionContentWrite, app.createTagHandler))
+	mux.HandleFunc("DELETE /v1/cms/tags/{id}", app.requirePermission(permissionContentDelete, app.deleteTagHandler))
+	mux.HandleFunc("GET /v1/cms/programs/{id}/tags", app.requirePermission(permissionContentRead, app.listProgramTagsHandler))
+	mux.HandleFunc("POST /v1/cms/programs/{id}/tags", app.requirePermission(permissionContentWrite, app.attachProgramTagsHandler))
+	mux.HandleFunc("DELETE /v1/cms/programs/{id}/tags/{tag}", app.requirePermission(permissionContentWrite, app.detachProgramTagHandler))
+
+	// CMS Import
+	mux.HandleFunc("POST /v1/cms/import", app.requirePermission(permissionContentWrite, app.importProgramHandler))
+
+	// CMS Users
+	mux.HandleFunc("POST /v1/cms/users", app.requirePermission(permissionUsersManage, app.createUserHandler))
+	mux.HandleFunc("GET /v1/cms/users", app.requirePermission(permissionUsersManage, app.listUsersHandler))
+	mux.HandleFunc("PUT /v1/cms/users/{id}/role", app.requirePermission(permissionUsersManage, app.updateUserRoleHandler))
+	mux.HandleFunc("DELETE /v1/cms/users/{id}", app.requirePermission(permissionUsersManage, app.deleteUserHandler))
+
+	// CMS Cache
+	mux.HandleFunc("GET /v1/cms/cache/keys", app.requirePermission(permissionCacheManage, app.listCacheKeysHandler))
+	mux.HandleFunc("DELETE /v1/cms/cache/keys", app.requirePermission(permissionCacheManage, app.purgeCacheKeysHandler))
 
 	// discovery
 	mux.HandleFunc("GET /v1/programs", app.discoveryHandler)
+	mux.HandleFunc("GET /v1/programs/{id}/feed.xml", app.programFeedHandler)
 
 	// external sources
 	mux.HandleFunc("GET /v1/external/search", app.searchExternalSourcesHandler)
 	mux.HandleFunc("GET /v1/external/sources", app.listExternalSourcesHandler)
 
-	return app.logRequest(app.recoverPanic(app.enableCORS(mux)))
+	return app.logRequest(app.recoverPanic(app.enableCORS(app.authenticate(mux))))
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func (app *application) listTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := app.programService.ListTags(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) createTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+
+	tag, err := app.programService.CreateTag(r.Context(), req)
+	if err != nil {
+		app.tagErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"tag": tag}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid tag ID")
+		return
+	}
+
+	if err := app.programService.DeleteTag(r.Context(), id); err != nil {
+		app.tagErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) listProgramTagsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	tags, err := app.programService.GetProgramTags(r.Context(), id)
+	if err != nil {
+		app.tagErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) attachProgramTagsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	var req service.AttachProgramTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ProgramID = id
+
+	tags, err := app.programService.AttachProgramTags(r.Context(), req)
+	if err != nil {
+		app.tagErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) detachProgramTagHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid program ID")
+		return
+	}
+
+	if err := app.programService.DetachProgramTag(r.Context(), id, r.PathValue("tag")); err != nil {
+		app.tagErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
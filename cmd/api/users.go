@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/service"
+)
+
+func (app *application) createUserHandler(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+
+	user, err := app.userService.CreateUser(r.Context(), req)
+	if err != nil {
+		var errAlreadyExists *service.ErrAlreadyExists
+		switch {
+		case errors.As(err, &errAlreadyExists):
+			app.conflictResponse(w, r, errAlreadyExists.Error())
+		case service.IsValidationError(err):
+			app.badRequestErrorResponse(w, r, err, err.Error())
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"user": user}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listUsersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := app.userService.ListUsers(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"users": users}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid user ID")
+		return
+	}
+
+	var req service.UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid request body")
+		return
+	}
+	req.ID = id
+
+	// Stop admins from locking themselves out of user management.
+	if app.contextGetUser(r).ID.Bytes == id && req.Role != service.RoleAdmin {
+		app.conflictResponse(w, r, "you cannot remove your own admin role")
+		return
+	}
+
+	user, err := app.userService.UpdateUserRole(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			app.notFoundResponse(w, r)
+		case service.IsValidationError(err):
+			app.badRequestErrorResponse(w, r, err, err.Error())
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		app.badRequestErrorResponse(w, r, err, "invalid user ID")
+		return
+	}
+
+	if app.contextGetUser(r).ID.Bytes == id {
+		app.conflictResponse(w, r, "you cannot delete your own account")
+		return
+	}
+
+	err = app.userService.DeleteUser(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
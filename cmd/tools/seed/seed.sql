@@ -152,14 +152,16 @@ SET
     language = EXCLUDED.language,
     duration = EXCLUDED.duration;
 
--- Insert a sample user for CMS access
+-- Insert a sample user for CMS access (password: gomania-admin)
 INSERT INTO
-    users (email, password_hash)
+    users (email, password_hash, role)
 VALUES
     (
         'admin@gomania.com',
-        '$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy'
+        '$2a$12$0yfz0x4M2L8Bvhi7snvZ2OIpbXFhIKYUgEs7h0AmPsH1SXAYL2Co2',
+        'admin'
     ) ON CONFLICT (email) DO
 UPDATE
 SET
-    password_hash = EXCLUDED.password_hash;
+    password_hash = EXCLUDED.password_hash,
+    role = EXCLUDED.role;
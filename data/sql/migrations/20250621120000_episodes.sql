@@ -0,0 +1,34 @@
+-- migrate:up
+-- Episodes belong to a program and carry the publishable audio
+CREATE TABLE episodes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    program_id UUID NOT NULL REFERENCES programs (id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    audio_url TEXT NOT NULL,
+    duration INTEGER, -- in seconds
+    season_number INTEGER,
+    episode_number INTEGER,
+    published_at TIMESTAMP
+    WITH
+        TIME ZONE,
+    created_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for listing a program's episodes ordered by publish date
+CREATE INDEX idx_episodes_program_published_at ON episodes (program_id, published_at DESC);
+
+-- A program cannot have two episodes with the same season/episode number
+CREATE UNIQUE INDEX idx_episodes_program_season_episode ON episodes (program_id, season_number, episode_number);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_episodes_program_season_episode;
+
+DROP INDEX IF EXISTS idx_episodes_program_published_at;
+
+DROP TABLE IF EXISTS episodes;
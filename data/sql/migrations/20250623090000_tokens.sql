@@ -0,0 +1,21 @@
+-- migrate:up
+-- Opaque session tokens issued to CMS users. Only the SHA-256 hash of the
+-- token is stored; the plaintext is returned to the client once at login.
+CREATE TABLE tokens (
+    hash BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expiry TIMESTAMP
+    WITH
+        TIME ZONE NOT NULL,
+    created_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for revoking all tokens of a user
+CREATE INDEX idx_tokens_user_id ON tokens (user_id);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_tokens_user_id;
+
+DROP TABLE IF EXISTS tokens;
@@ -0,0 +1,18 @@
+-- migrate:up
+-- Roles a CMS user can hold. Permissions per role are enforced by the API.
+CREATE TABLE roles (
+    name VARCHAR(20) PRIMARY KEY
+);
+
+INSERT INTO roles (name) VALUES ('admin'), ('editor'), ('viewer');
+
+ALTER TABLE users
+ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'viewer' REFERENCES roles (name);
+
+-- Users created before roles existed had full CMS access; keep it that way.
+UPDATE users SET role = 'admin';
+
+-- migrate:down
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+DROP TABLE IF EXISTS roles;
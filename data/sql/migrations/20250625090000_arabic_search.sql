@@ -0,0 +1,59 @@
+-- migrate:up
+-- Arabic normalization used for search. Keep in sync with internal/arabic:
+--   * strip harakat, Quranic marks and tatweel
+--   * fold أ إ آ ٱ to ا, ؤ to و, ئ and ى to ي, ة to ه
+--   * lowercase and collapse whitespace
+CREATE FUNCTION normalize_arabic (input TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE RETURNS NULL ON NULL INPUT
+AS $$
+    SELECT btrim(
+        regexp_replace(
+            lower(
+                translate(
+                    regexp_replace(input, '[\u0610-\u061A\u064B-\u065F\u0670\u06D6-\u06ED\u0640]', '', 'g'),
+                    'أإآٱؤئىة',
+                    'ااااوييه'
+                )
+            ),
+            '\s+', ' ', 'g'
+        )
+    );
+$$;
+
+-- Normalized copies of the searchable text, maintained by Postgres
+ALTER TABLE programs
+ADD COLUMN search_title TEXT GENERATED ALWAYS AS (normalize_arabic (title)) STORED,
+ADD COLUMN search_description TEXT GENERATED ALWAYS AS (normalize_arabic (description)) STORED;
+
+ALTER TABLE categories
+ADD COLUMN search_name TEXT GENERATED ALWAYS AS (normalize_arabic (name)) STORED;
+
+-- Move the trigram indexes onto the normalized columns
+DROP INDEX IF EXISTS idx_programs_title_gin;
+
+DROP INDEX IF EXISTS idx_programs_description_gin;
+
+CREATE INDEX idx_programs_search_title_gin ON programs USING gin (search_title gin_trgm_ops);
+
+CREATE INDEX idx_programs_search_description_gin ON programs USING gin (search_description gin_trgm_ops);
+
+CREATE INDEX idx_categories_search_name_gin ON categories USING gin (search_name gin_trgm_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_categories_search_name_gin;
+
+DROP INDEX IF EXISTS idx_programs_search_description_gin;
+
+DROP INDEX IF EXISTS idx_programs_search_title_gin;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS search_name;
+
+ALTER TABLE programs
+DROP COLUMN IF EXISTS search_description,
+DROP COLUMN IF EXISTS search_title;
+
+DROP FUNCTION IF EXISTS normalize_arabic (TEXT);
+
+CREATE INDEX idx_programs_title_gin ON programs USING gin (title gin_trgm_ops);
+
+CREATE INDEX idx_programs_description_gin ON programs USING gin (description gin_trgm_ops);
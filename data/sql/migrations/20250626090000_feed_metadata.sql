@@ -0,0 +1,21 @@
+-- migrate:up
+-- Channel-level metadata needed to publish a program as a podcast feed
+ALTER TABLE programs
+ADD COLUMN author VARCHAR(255),
+ADD COLUMN owner_email VARCHAR(255),
+ADD COLUMN image_url TEXT,
+ADD COLUMN locked BOOLEAN NOT NULL DEFAULT false;
+
+-- Optional transcript published alongside an episode's audio
+ALTER TABLE episodes
+ADD COLUMN transcript_url TEXT;
+
+-- migrate:down
+ALTER TABLE episodes
+DROP COLUMN IF EXISTS transcript_url;
+
+ALTER TABLE programs
+DROP COLUMN IF EXISTS locked,
+DROP COLUMN IF EXISTS image_url,
+DROP COLUMN IF EXISTS owner_email,
+DROP COLUMN IF EXISTS author;
@@ -0,0 +1,19 @@
+-- migrate:up
+-- Where an imported program came from, so re-importing updates it in place
+ALTER TABLE programs
+ADD COLUMN external_url TEXT,
+ADD COLUMN source VARCHAR(50),
+ADD COLUMN external_id VARCHAR(255);
+
+-- NULLs never conflict, so locally created programs are unaffected
+ALTER TABLE programs
+ADD CONSTRAINT programs_source_external_id_key UNIQUE (source, external_id);
+
+-- migrate:down
+ALTER TABLE programs
+DROP CONSTRAINT IF EXISTS programs_source_external_id_key;
+
+ALTER TABLE programs
+DROP COLUMN IF EXISTS external_id,
+DROP COLUMN IF EXISTS source,
+DROP COLUMN IF EXISTS external_url;
@@ -0,0 +1,51 @@
+-- migrate:up
+-- Categories form a tree, e.g. تعليم > لغات > إنجليزي. Top-level categories
+-- have no parent.
+ALTER TABLE categories
+ADD COLUMN parent_id UUID REFERENCES categories (id),
+ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+-- Reject a parent that is the category itself or one of its descendants,
+-- which would detach the subtree from the root in a loop
+CREATE FUNCTION categories_prevent_cycle () RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF EXISTS (
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id FROM categories WHERE id = NEW.parent_id
+            UNION
+            SELECT c.id, c.parent_id
+            FROM categories c
+            JOIN ancestors a ON c.id = a.parent_id
+        )
+        SELECT 1 FROM ancestors WHERE id = NEW.id
+    ) THEN
+        RAISE EXCEPTION 'category % cannot be its own ancestor', NEW.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'categories_parent_cycle';
+    END IF;
+
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER categories_prevent_cycle
+BEFORE INSERT OR UPDATE OF parent_id ON categories
+FOR EACH ROW EXECUTE FUNCTION categories_prevent_cycle ();
+
+-- migrate:down
+DROP TRIGGER IF EXISTS categories_prevent_cycle ON categories;
+
+DROP FUNCTION IF EXISTS categories_prevent_cycle ();
+
+DROP INDEX IF EXISTS idx_categories_parent_id;
+
+ALTER TABLE categories
+DROP CONSTRAINT IF EXISTS categories_parent_not_self,
+DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,27 @@
+-- migrate:up
+-- Free-form keywords that classify programs beyond their single category.
+-- Names are stored lowercased, so "AI" and "ai" are the same tag.
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    created_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE program_tags (
+    program_id UUID NOT NULL REFERENCES programs (id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (program_id, tag_id)
+);
+
+-- The primary key covers lookups by program; this one covers filtering and
+-- counting by tag
+CREATE INDEX idx_program_tags_tag_id ON program_tags (tag_id);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_program_tags_tag_id;
+
+DROP TABLE IF EXISTS program_tags;
+
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,25 @@
+-- migrate:up
+-- Programs move through draft -> scheduled -> published -> archived, and only
+-- published programs are public. Existing programs were already public, so
+-- they start out published; new programs start as drafts.
+ALTER TABLE programs
+ADD COLUMN status TEXT NOT NULL DEFAULT 'published',
+ADD COLUMN publish_at TIMESTAMPTZ,
+ADD CONSTRAINT programs_status_valid CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+ADD CONSTRAINT programs_scheduled_publish_at CHECK (status <> 'scheduled' OR publish_at IS NOT NULL);
+
+UPDATE programs SET publish_at = created_at;
+
+ALTER TABLE programs ALTER COLUMN status SET DEFAULT 'draft';
+
+-- The publish scheduler looks for scheduled programs that are due
+CREATE INDEX idx_programs_scheduled ON programs (publish_at) WHERE status = 'scheduled';
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_programs_scheduled;
+
+ALTER TABLE programs
+DROP CONSTRAINT IF EXISTS programs_scheduled_publish_at,
+DROP CONSTRAINT IF EXISTS programs_status_valid,
+DROP COLUMN IF EXISTS publish_at,
+DROP COLUMN IF EXISTS status;
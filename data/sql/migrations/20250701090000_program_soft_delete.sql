@@ -0,0 +1,14 @@
+-- migrate:up
+-- Deleted programs go to the trash first, and are purged for good once they
+-- have been there longer than the retention period
+ALTER TABLE programs
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- The trash listing and the purge only look at deleted programs
+CREATE INDEX idx_programs_deleted_at ON programs (deleted_at DESC, id DESC) WHERE deleted_at IS NOT NULL;
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_programs_deleted_at;
+
+ALTER TABLE programs
+DROP COLUMN IF EXISTS deleted_at;
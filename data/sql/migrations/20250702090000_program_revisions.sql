@@ -0,0 +1,22 @@
+-- migrate:up
+-- Every edit of a program's fields is kept as a numbered revision. snapshot
+-- holds the fields after the edit and changes the fields it changed, as
+-- {"field": {"from": ..., "to": ...}}. Revision 1 records the program as it
+-- was before its first recorded edit.
+CREATE TABLE program_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    program_id UUID NOT NULL REFERENCES programs (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    user_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    snapshot JSONB NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    -- The revision this one rolled the program back to, if any
+    rolled_back_to INTEGER,
+    created_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (program_id, revision)
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS program_revisions;
@@ -0,0 +1,27 @@
+-- migrate:up
+-- version counts the changes to a program's row. The CMS hands it out as the
+-- program's ETag, and updates can require it to be unchanged since the
+-- program was read.
+ALTER TABLE programs
+ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Bump it on every update, whichever query makes it
+CREATE FUNCTION bump_program_version () RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER programs_bump_version BEFORE UPDATE ON programs
+FOR EACH ROW EXECUTE FUNCTION bump_program_version ();
+
+-- migrate:down
+DROP TRIGGER IF EXISTS programs_bump_version ON programs;
+
+DROP FUNCTION IF EXISTS bump_program_version;
+
+ALTER TABLE programs
+DROP COLUMN IF EXISTS version;
@@ -0,0 +1,21 @@
+-- name: GetUserByEmail :one
+SELECT id, email, password_hash
+FROM users
+WHERE email = $1;
+
+-- name: GetUserForToken :one
+SELECT u.id, u.email, u.role, u.created_at
+FROM users u
+JOIN tokens t ON t.user_id = u.id
+WHERE t.hash = $1
+  AND t.expiry > CURRENT_TIMESTAMP;
+
+-- name: CreateToken :exec
+INSERT INTO tokens (hash, user_id, expiry)
+VALUES ($1, $2, $3);
+
+-- name: DeleteToken :exec
+DELETE FROM tokens WHERE hash = $1;
+
+-- name: DeleteExpiredTokens :exec
+DELETE FROM tokens WHERE expiry <= CURRENT_TIMESTAMP;
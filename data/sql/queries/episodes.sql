@@ -0,0 +1,79 @@
+-- name: GetEpisode :one
+SELECT
+    id,
+    program_id,
+    title,
+    description,
+    audio_url,
+    duration,
+    season_number,
+    episode_number,
+    published_at,
+    transcript_url
+FROM episodes
+WHERE id = $1 AND program_id = $2
+  AND EXISTS (SELECT 1 FROM programs p WHERE p.id = program_id AND p.deleted_at IS NULL);
+
+-- name: ListEpisodesByProgram :many
+SELECT
+    id,
+    program_id,
+    title,
+    description,
+    audio_url,
+    duration,
+    season_number,
+    episode_number,
+    published_at,
+    transcript_url
+FROM episodes
+WHERE program_id = $1
+  AND EXISTS (SELECT 1 FROM programs p WHERE p.id = program_id AND p.deleted_at IS NULL)
+ORDER BY published_at DESC NULLS LAST, created_at DESC;
+
+-- name: ListPublishedEpisodesByProgram :many
+SELECT
+    id,
+    program_id,
+    title,
+    description,
+    audio_url,
+    duration,
+    season_number,
+    episode_number,
+    published_at,
+    transcript_url
+FROM episodes
+WHERE program_id = $1
+  AND published_at IS NOT NULL
+  AND published_at <= CURRENT_TIMESTAMP
+  AND EXISTS (SELECT 1 FROM programs p WHERE p.id = program_id AND p.deleted_at IS NULL)
+ORDER BY published_at DESC;
+
+-- name: CreateEpisode :one
+INSERT INTO episodes (program_id, title, description, audio_url, duration, season_number, episode_number, published_at, transcript_url)
+SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+FROM programs
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, program_id, title, description, audio_url, duration, season_number, episode_number, published_at, transcript_url;
+
+-- name: UpdateEpisode :one
+UPDATE episodes
+SET
+    title = $3,
+    description = $4,
+    audio_url = $5,
+    duration = $6,
+    season_number = $7,
+    episode_number = $8,
+    published_at = $9,
+    transcript_url = $10,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND program_id = $2
+  AND EXISTS (SELECT 1 FROM programs p WHERE p.id = program_id AND p.deleted_at IS NULL)
+RETURNING id, program_id, title, description, audio_url, duration, season_number, episode_number, published_at, transcript_url;
+
+-- name: DeleteEpisode :execrows
+DELETE FROM episodes
+WHERE id = $1 AND program_id = $2
+  AND EXISTS (SELECT 1 FROM programs p WHERE p.id = program_id AND p.deleted_at IS NULL);
@@ -0,0 +1,43 @@
+-- name: GetImportedProgramForUpdate :one
+-- Locks the program previously imported from a source, even from the trash.
+SELECT
+    id,
+    title,
+    description,
+    category_id,
+    language,
+    duration,
+    author,
+    owner_email,
+    image_url,
+    locked,
+    updated_at,
+    version
+FROM programs
+WHERE source = $1 AND external_id = $2
+FOR UPDATE;
+
+-- name: UpsertCategoryByName :one
+-- The no-op update makes RETURNING yield the id of an existing category too.
+INSERT INTO categories (name)
+VALUES ($1)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id;
+
+-- name: UpsertImportedProgram :one
+-- Imported programs are published straight away. A re-import keeps the
+-- program's status, and takes it out of the trash.
+INSERT INTO programs (title, description, category_id, duration, author, image_url, external_url, source, external_id, status, publish_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'published', CURRENT_TIMESTAMP)
+ON CONFLICT (source, external_id) DO UPDATE
+SET
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    category_id = EXCLUDED.category_id,
+    duration = EXCLUDED.duration,
+    author = EXCLUDED.author,
+    image_url = EXCLUDED.image_url,
+    external_url = EXCLUDED.external_url,
+    deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING id, title, description, category_id, language, duration, author, image_url, external_url, source, external_id, (xmax = 0)::boolean AS inserted;
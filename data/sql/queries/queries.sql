@@ -6,71 +6,163 @@ SELECT
     p.language,
     p.duration,
     p.category_id,
-    c.name as category_name
+    c.name as category_name,
+    p.author,
+    p.owner_email,
+    p.image_url,
+    p.locked,
+    p.external_url,
+    p.source,
+    p.external_id,
+    p.status,
+    p.publish_at,
+    p.version
 FROM programs p
 LEFT JOIN categories c ON p.category_id = c.id
-WHERE p.id = $1;
+WHERE p.id = $1 AND p.deleted_at IS NULL;
 
--- name: ListPrograms :many
-SELECT
-    p.id,
-    p.title,
-    p.description,
-    p.language,
-    p.duration,
-    p.category_id,
-    c.name as category_name
-FROM programs p
-LEFT JOIN categories c ON p.category_id = c.id
-ORDER BY p.created_at DESC;
+-- name: CreateProgram :one
+INSERT INTO programs (title, description, category_id, language, duration, author, owner_email, image_url, locked, status, publish_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, title, description, language, duration, author, owner_email, image_url, locked, status, publish_at;
+
+-- name: UpdateProgram :one
+-- Unless if_match is NULL, the program is only updated if its version is
+-- one of those listed.
+UPDATE programs
+SET
+    title = sqlc.arg('title'),
+    description = sqlc.arg('description'),
+    category_id = sqlc.arg('category_id'),
+    language = sqlc.arg('language'),
+    duration = sqlc.arg('duration'),
+    author = sqlc.arg('author'),
+    owner_email = sqlc.arg('owner_email'),
+    image_url = sqlc.arg('image_url'),
+    locked = sqlc.arg('locked'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('if_match')::int[] IS NULL OR version = ANY(sqlc.narg('if_match')::int[]))
+RETURNING id, title, description, language, duration, author, owner_email, image_url, locked, version;
+
+-- name: SetProgramStatus :one
+UPDATE programs
+SET status = $2, publish_at = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, status, publish_at;
+
+-- name: PublishDuePrograms :many
+-- Publishes every scheduled program whose publish_at has passed. Programs
+-- in the trash wait until they are restored.
+UPDATE programs
+SET status = 'published', updated_at = CURRENT_TIMESTAMP
+WHERE status = 'scheduled' AND publish_at <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+RETURNING id, category_id;
+
+-- name: NextScheduledPublish :one
+-- NULL when no program is scheduled.
+SELECT min(publish_at)::timestamptz AS publish_at
+FROM programs
+WHERE status = 'scheduled' AND deleted_at IS NULL;
+
+-- name: SoftDeleteProgram :execrows
+-- Moves a program to the trash.
+UPDATE programs
+SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreProgram :one
+UPDATE programs
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, category_id;
 
--- name: SearchPrograms :many
+-- name: ListDeletedPrograms :many
+-- Lists the trash, most recently deleted first.
 SELECT
     p.id,
     p.title,
-    p.description,
-    p.language,
-    p.duration,
     p.category_id,
-    c.name as category_name
+    c.name as category_name,
+    p.status,
+    p.deleted_at
 FROM programs p
 LEFT JOIN categories c ON p.category_id = c.id
-WHERE p.title ILIKE '%' || $1 || '%'
-   OR p.description ILIKE '%' || $1 || '%'
-   OR c.name ILIKE '%' || $1 || '%'
-ORDER BY p.created_at DESC;
-
--- name: CreateProgram :one
-INSERT INTO programs (title, description, category_id, language, duration)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, title, description, language, duration;
-
--- name: UpdateProgram :one
-UPDATE programs
-SET
-    title = $2,
-    description = $3,
-    category_id = $4,
-    language = $5,
-    duration = $6,
-    updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, title, description, language, duration;
+WHERE p.deleted_at IS NOT NULL
+  AND (sqlc.narg('cursor_deleted_at')::timestamptz IS NULL
+   OR (p.deleted_at, p.id) < (sqlc.narg('cursor_deleted_at')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY p.deleted_at DESC, p.id DESC
+LIMIT sqlc.arg('page_limit');
 
--- name: DeleteProgram :exec
-DELETE FROM programs WHERE id = $1;
+-- name: PurgeDeletedPrograms :execrows
+-- Deletes programs trashed before deleted_before for good, along with their
+-- episodes and tags.
+DELETE FROM programs
+WHERE deleted_at < sqlc.arg('deleted_before');
 
 -- name: GetCategories :many
-SELECT id, name
+SELECT id, name, parent_id
 FROM categories
 ORDER BY name;
 
 -- name: CreateCategory :one
-INSERT INTO categories (name)
-VALUES ($1)
-RETURNING id, name;
+INSERT INTO categories (name, parent_id)
+VALUES ($1, $2)
+RETURNING id, name, parent_id;
+
+-- name: GetCategory :one
+SELECT id, name, parent_id
+FROM categories
+WHERE id = $1;
+
+-- name: UpdateCategory :one
+-- The parent only changes when set_parent is true.
+UPDATE categories
+SET
+    name = sqlc.arg('name'),
+    parent_id = CASE WHEN sqlc.arg('set_parent')::bool THEN sqlc.narg('parent_id')::uuid ELSE parent_id END
+WHERE id = sqlc.arg('id')
+RETURNING id, name, parent_id;
+
+-- name: DeleteCategory :execrows
+DELETE FROM categories WHERE id = $1;
+
+-- name: ReparentChildCategories :exec
+-- Moves the children of a category up to the category's own parent.
+UPDATE categories
+SET parent_id = (SELECT parent.parent_id FROM categories parent WHERE parent.id = sqlc.arg('category_id'))
+WHERE parent_id = sqlc.arg('category_id');
+
+-- name: CountProgramsInCategory :one
+SELECT count(*) FROM programs WHERE category_id = $1 AND deleted_at IS NULL;
+
+-- name: UncategorizeDeletedPrograms :exec
+-- Trashed programs do not keep a category from being deleted. They lose it
+-- instead.
+UPDATE programs
+SET category_id = NULL
+WHERE category_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: ReassignCategoryPrograms :many
+-- A NULL to_category_id leaves the programs without a category.
+UPDATE programs
+SET
+    category_id = sqlc.narg('to_category_id'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE category_id = sqlc.arg('from_category_id')
+RETURNING id;
 
 -- name: GetProgramsByCategory :many
+-- With include_descendants, programs in every subcategory are listed too.
+WITH RECURSIVE tree AS (
+    SELECT id FROM categories WHERE id = sqlc.arg('category_id')
+    UNION
+    SELECT sub.id
+    FROM categories sub
+    JOIN tree t ON sub.parent_id = t.id
+    WHERE sqlc.arg('include_descendants')::boolean
+)
 SELECT
     p.id,
     p.title,
@@ -78,8 +170,13 @@ SELECT
     p.language,
     p.duration,
     p.category_id,
-    c.name as category_name
+    c.name as category_name,
+    p.created_at
 FROM programs p
 JOIN categories c ON p.category_id = c.id
-WHERE c.id = $1
-ORDER BY p.created_at DESC;
+WHERE p.category_id IN (SELECT id FROM tree)
+  AND p.deleted_at IS NULL
+  AND (sqlc.narg('cursor_created_at')::timestamptz IS NULL
+   OR (p.created_at, p.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg('page_limit');
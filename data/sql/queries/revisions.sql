@@ -0,0 +1,83 @@
+-- name: GetProgramForUpdate :one
+-- Locks a program's row until the end of the transaction, so its revisions
+-- are numbered in order.
+SELECT
+    id,
+    title,
+    description,
+    category_id,
+    language,
+    duration,
+    author,
+    owner_email,
+    image_url,
+    locked,
+    updated_at,
+    version
+FROM programs
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: GetCategoryProgramsForUpdate :many
+-- Locks the programs in a category, including those in the trash, before
+-- they are moved out of it.
+SELECT
+    id,
+    title,
+    description,
+    category_id,
+    language,
+    duration,
+    author,
+    owner_email,
+    image_url,
+    locked,
+    updated_at,
+    version
+FROM programs
+WHERE category_id = $1
+FOR UPDATE;
+
+-- name: HasProgramRevisions :one
+SELECT EXISTS (SELECT 1 FROM program_revisions WHERE program_id = $1);
+
+-- name: CreateProgramRevision :one
+-- A NULL created_at means now.
+INSERT INTO program_revisions (program_id, revision, user_id, snapshot, changes, rolled_back_to, created_at)
+SELECT
+    sqlc.arg('program_id'),
+    COALESCE(max(revision), 0) + 1,
+    sqlc.narg('user_id'),
+    sqlc.arg('snapshot'),
+    sqlc.arg('changes'),
+    sqlc.narg('rolled_back_to'),
+    COALESCE(sqlc.narg('created_at')::timestamptz, CURRENT_TIMESTAMP)
+FROM program_revisions
+WHERE program_id = sqlc.arg('program_id')
+RETURNING revision;
+
+-- name: ListProgramRevisions :many
+SELECT
+    r.revision,
+    r.user_id,
+    u.email AS user_email,
+    r.changes,
+    r.rolled_back_to,
+    r.created_at
+FROM program_revisions r
+LEFT JOIN users u ON u.id = r.user_id
+WHERE r.program_id = $1
+ORDER BY r.revision DESC;
+
+-- name: GetProgramRevision :one
+SELECT
+    r.revision,
+    r.user_id,
+    u.email AS user_email,
+    r.snapshot,
+    r.changes,
+    r.rolled_back_to,
+    r.created_at
+FROM program_revisions r
+LEFT JOIN users u ON u.id = r.user_id
+WHERE r.program_id = $1 AND r.revision = $2;
@@ -0,0 +1,43 @@
+-- name: ListTags :many
+-- Programs in the trash are not counted.
+SELECT t.id, t.name, count(p.id) AS program_count
+FROM tags t
+LEFT JOIN program_tags pt ON pt.tag_id = t.id
+LEFT JOIN programs p ON p.id = pt.program_id AND p.deleted_at IS NULL
+GROUP BY t.id
+ORDER BY t.name;
+
+-- name: CreateTag :one
+INSERT INTO tags (name)
+VALUES ($1)
+RETURNING id, name;
+
+-- name: DeleteTag :one
+DELETE FROM tags WHERE id = $1
+RETURNING name;
+
+-- name: UpsertTags :many
+-- The no-op update makes RETURNING yield the ids of existing tags too.
+INSERT INTO tags (name)
+SELECT unnest(sqlc.arg('names')::text[])
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id;
+
+-- name: AttachProgramTags :exec
+INSERT INTO program_tags (program_id, tag_id)
+SELECT sqlc.arg('program_id'), unnest(sqlc.arg('tag_ids')::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: DetachProgramTag :execrows
+DELETE FROM program_tags pt
+USING tags t
+WHERE pt.tag_id = t.id
+  AND pt.program_id = $1
+  AND t.name = $2;
+
+-- name: ListProgramTags :many
+SELECT t.id, t.name
+FROM tags t
+JOIN program_tags pt ON pt.tag_id = t.id
+WHERE pt.program_id = $1
+ORDER BY t.name;
@@ -0,0 +1,18 @@
+-- name: ListUsers :many
+SELECT id, email, role, created_at
+FROM users
+ORDER BY created_at;
+
+-- name: CreateUser :one
+INSERT INTO users (email, password_hash, role)
+VALUES ($1, $2, $3)
+RETURNING id, email, role, created_at;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING id, email, role, created_at;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1;
@@ -0,0 +1,70 @@
+// Package arabic normalizes Arabic text so that spelling variants which
+// readers treat as the same word compare equal.
+//
+// The rules here must stay in sync with the normalize_arabic SQL function
+// (see data/sql/migrations), which is used to build the search columns that
+// normalized queries are matched against.
+package arabic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// letterFolds maps letter variants to the form used for matching.
+var letterFolds = map[rune]rune{
+	'أ': 'ا', // alef with hamza above
+	'إ': 'ا', // alef with hamza below
+	'آ': 'ا', // alef with madda
+	'ٱ': 'ا', // alef wasla
+	'ؤ': 'و', // waw with hamza
+	'ئ': 'ي', // yaa with hamza
+	'ى': 'ي', // alef maqsura
+	'ة': 'ه', // taa marbuta
+}
+
+// isIgnorable reports whether r carries no meaning for matching: harakat
+// (tashkeel), Quranic annotation marks and tatweel.
+func isIgnorable(r rune) bool {
+	switch {
+	case r >= 0x0610 && r <= 0x061A:
+		return true
+	case r >= 0x064B && r <= 0x065F:
+		return true
+	case r == 0x0670:
+		return true
+	case r >= 0x06D6 && r <= 0x06ED:
+		return true
+	case r == 0x0640: // tatweel
+		return true
+	}
+	return false
+}
+
+// Normalize folds alef/hamza variants, taa marbuta and alef maqsura, strips
+// diacritics and tatweel, lowercases Latin letters and collapses whitespace.
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	space := false
+	for _, r := range s {
+		if isIgnorable(r) {
+			continue
+		}
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		if folded, ok := letterFolds[r]; ok {
+			r = folded
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
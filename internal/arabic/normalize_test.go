@@ -0,0 +1,40 @@
+package arabic
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"hamza above", "أحمد", "احمد"},
+		{"hamza below", "إسلام", "اسلام"},
+		{"madda", "آمال", "امال"},
+		{"taa marbuta", "مدرسة", "مدرسه"},
+		{"alef maqsura", "مصطفى", "مصطفي"},
+		{"hamza on waw and yaa", "مؤمن بئر", "مومن بير"},
+		{"tashkeel", "مُحَمَّدٌ", "محمد"},
+		{"tatweel", "تقـــنية", "تقنيه"},
+		{"latin lowercase", "Tech Podcast", "tech podcast"},
+		{"whitespace collapse", "  برنامج \t  تقني  ", "برنامج تقني"},
+		{"empty", "", ""},
+		{"only diacritics", "ًٌٍ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_Idempotent(t *testing.T) {
+	in := "الأَخْبَار اليَوْمِيّة"
+	once := Normalize(in)
+	if twice := Normalize(once); twice != once {
+		t.Errorf("Expected Normalize to be idempotent, got %q then %q", once, twice)
+	}
+}
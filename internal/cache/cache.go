@@ -23,6 +23,79 @@ type Cache interface {
 	Close()
 }
 
+// TTLSetter is implemented by caches that accept a lifetime per entry.
+type TTLSetter interface {
+	// SetWithTTL stores an item that expires after ttl
+	SetWithTTL(key string, value any, ttl time.Duration)
+}
+
+// SetWithTTL stores an item for ttl if c supports per-entry lifetimes, and
+// with c's default lifetime otherwise. A ttl of zero or less also uses the
+// default.
+func SetWithTTL(c Cache, key string, value any, ttl time.Duration) {
+	if s, ok := c.(TTLSetter); ok && ttl > 0 {
+		s.SetWithTTL(key, value, ttl)
+		return
+	}
+	c.Set(key, value)
+}
+
+// Tagger is implemented by caches that index entries by tag, so everything
+// derived from one record can be dropped without knowing the keys.
+type Tagger interface {
+	// SetTagged stores an item under tags. A ttl of zero or less uses the
+	// cache's default.
+	SetTagged(key string, value any, ttl time.Duration, tags ...string)
+	// InvalidateTags removes every entry carrying any of tags and returns how
+	// many were removed
+	InvalidateTags(tags ...string) int
+}
+
+// SetTagged stores an item under tags if c supports them. Caches that do not
+// keep a tag index fall back to SetWithTTL, and InvalidateTags clears them
+// entirely, so correctness does not depend on the backend.
+func SetTagged(c Cache, key string, value any, ttl time.Duration, tags ...string) {
+	if t, ok := c.(Tagger); ok {
+		t.SetTagged(key, value, ttl, tags...)
+		return
+	}
+	SetWithTTL(c, key, value, ttl)
+}
+
+// InvalidateTags removes every entry carrying any of tags. On a cache without
+// a tag index it clears everything.
+func InvalidateTags(c Cache, tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	if t, ok := c.(Tagger); ok {
+		t.InvalidateTags(tags...)
+		return
+	}
+	c.Clear()
+}
+
+// KeyInfo describes one cached entry.
+type KeyInfo struct {
+	Key       string    `json:"key"`
+	Bytes     int64     `json:"bytes"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// Inspector is implemented by caches that can list their keys.
+type Inspector interface {
+	// Keys lists the live entries whose key starts with prefix, sorted by key
+	Keys(prefix string) []KeyInfo
+}
+
+// PrefixInvalidator is implemented by caches that can drop a whole key
+// prefix, which unlike a pattern matches keys containing any character.
+type PrefixInvalidator interface {
+	// InvalidatePrefix removes every entry whose key starts with prefix and
+	// returns how many were removed
+	InvalidatePrefix(prefix string) int
+}
+
 // NewMemoryCache creates a new memory cache instance
 func NewMemoryCache(ttl time.Duration) Cache {
 	return New(ttl)
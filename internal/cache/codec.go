@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Codec turns cached values into bytes and back for backends that live out
+// of process. Unmarshal must return the same concrete type that was passed to
+// Marshal, because callers type-assert what they read from the cache.
+type Codec interface {
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte) (any, error)
+}
+
+// GobCodec encodes values with encoding/gob. Every concrete type stored
+// through it must be registered with Register first.
+type GobCodec struct{}
+
+// Marshal encodes value together with its registered type name
+func (GobCodec) Marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", value, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a value produced by Marshal
+func (GobCodec) Unmarshal(data []byte) (any, error) {
+	var value any
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode cached value: %w", err)
+	}
+	return value, nil
+}
+
+// Register makes the concrete types of values known to GobCodec. Register
+// pointers for values cached by pointer, so reads return the same type.
+func Register(values ...any) {
+	for _, v := range values {
+		gob.Register(v)
+	}
+}
@@ -0,0 +1,245 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultLoadTimeout bounds a load once it is shared by several callers or
+// runs in the background, since no single caller's context owns it.
+const DefaultLoadTimeout = 10 * time.Second
+
+// LoaderOptions configures a Loader.
+type LoaderOptions struct {
+	// FreshFor is how long a loaded value is served as is. Once it passes, the
+	// value is still returned, but a background load refreshes it
+	// (stale-while-revalidate) until the cache's own TTL drops it. Zero
+	// disables revalidation: values are fresh until the cache expires them.
+	FreshFor time.Duration
+	// LoadTimeout bounds each load. Defaults to DefaultLoadTimeout.
+	LoadTimeout time.Duration
+	// Logger receives background refresh failures. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// LoadFunc produces the value for a key on a cache miss.
+type LoadFunc func(ctx context.Context) (any, error)
+
+// taggedLoadFunc also returns the tags to store the value under.
+type taggedLoadFunc func(ctx context.Context) (any, []string, error)
+
+// Loader reads through a Cache: on a miss it calls a load function and
+// stores the result. Concurrent misses for the same key share a single load,
+// so an expiring hot key causes one query rather than one per request.
+// Errors are returned to every waiting caller and are never cached.
+type Loader struct {
+	cache Cache
+	opts  LoaderOptions
+
+	mu       sync.Mutex
+	inflight map[string]*call
+	// generation counts invalidations made through Cache. A load that began
+	// in an earlier generation may have read what the invalidation dropped,
+	// so its value is returned to its callers but not stored.
+	generation uint64
+	// storing is held for reading while a load stores its value and for
+	// writing while the generation moves, so no store straddles the two.
+	storing sync.RWMutex
+}
+
+// loaded is what a Loader stores in the cache: the value plus the time after
+// which it should be revalidated.
+type loaded struct {
+	Value      any
+	FreshUntil time.Time
+}
+
+func init() {
+	Register(&loaded{})
+}
+
+// call is one in-flight load shared by every caller waiting on the key.
+type call struct {
+	done       chan struct{}
+	generation uint64
+	value      any
+	err        error
+}
+
+// NewLoader creates a read-through loader on top of c
+func NewLoader(c Cache, opts LoaderOptions) *Loader {
+	if opts.LoadTimeout <= 0 {
+		opts.LoadTimeout = DefaultLoadTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Loader{
+		cache:    c,
+		opts:     opts,
+		inflight: make(map[string]*call),
+	}
+}
+
+// Cache returns the cache the loader reads through, for invalidation. Loads
+// already running when an entry is invalidated through it do not store their
+// results, and later callers start a fresh load instead of joining them.
+func (l *Loader) Cache() Cache {
+	return &loaderCache{Cache: l.cache, loader: l}
+}
+
+// Load returns the cached value for key, calling load on a miss. A stale
+// value is returned immediately while one background load refreshes it.
+//
+// load runs detached from ctx so that one caller giving up does not fail the
+// others sharing it; ctx only bounds how long this caller waits.
+func (l *Loader) Load(ctx context.Context, key string, load LoadFunc) (any, error) {
+	return l.load(ctx, key, 0, func(ctx context.Context) (any, []string, error) {
+		value, err := load(ctx)
+		return value, nil, err
+	})
+}
+
+// load is Load with a lifetime for the stored entry, where zero uses the
+// cache's, and tags chosen by the load function.
+func (l *Loader) load(ctx context.Context, key string, ttl time.Duration, load taggedLoadFunc) (any, error) {
+	if cached, found := l.cache.Get(key); found {
+		if e, ok := cached.(*loaded); ok {
+			if l.opts.FreshFor > 0 && time.Now().After(e.FreshUntil) {
+				l.start(ctx, key, ttl, load)
+			}
+			return e.Value, nil
+		}
+		// Not written by a Loader; replace it.
+		l.cache.Delete(key)
+	}
+
+	c := l.start(ctx, key, ttl, load)
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// start joins the in-flight load for key, or begins one.
+func (l *Loader) start(ctx context.Context, key string, ttl time.Duration, load taggedLoadFunc) *call {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if c, ok := l.inflight[key]; ok && c.generation == l.generation {
+		return c
+	}
+
+	c := &call{done: make(chan struct{}), generation: l.generation}
+	l.inflight[key] = c
+	go l.run(context.WithoutCancel(ctx), key, ttl, load, c)
+	return c
+}
+
+func (l *Loader) run(ctx context.Context, key string, ttl time.Duration, load taggedLoadFunc, c *call) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("cache load for %s panicked: %v", key, r)
+		}
+		if c.err != nil {
+			l.opts.Logger.Debug("Cache load failed", "key", key, "error", c.err)
+		}
+
+		l.mu.Lock()
+		if l.inflight[key] == c {
+			delete(l.inflight, key)
+		}
+		l.mu.Unlock()
+		close(c.done)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, l.opts.LoadTimeout)
+	defer cancel()
+
+	var tags []string
+	c.value, tags, c.err = load(ctx)
+	if c.err != nil {
+		return
+	}
+
+	l.storing.RLock()
+	defer l.storing.RUnlock()
+	l.mu.Lock()
+	current := c.generation == l.generation
+	l.mu.Unlock()
+	if current {
+		l.store(key, c.value, ttl, tags...)
+	}
+}
+
+// invalidated starts a new generation. It waits for stores already under way,
+// which the invalidation that follows then removes.
+func (l *Loader) invalidated() {
+	l.storing.Lock()
+	defer l.storing.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+}
+
+// store writes value in the format Load reads.
+func (l *Loader) store(key string, value any, ttl time.Duration, tags ...string) {
+	SetTagged(l.cache, key, &loaded{Value: value, FreshUntil: time.Now().Add(l.opts.FreshFor)}, ttl, tags...)
+}
+
+// loaderCache is the view of a Loader's cache returned by Cache. Every
+// invalidation through it starts a new load generation first.
+type loaderCache struct {
+	Cache
+	loader *Loader
+}
+
+// SetWithTTL stores an item that expires after ttl, if the cache supports it
+func (c *loaderCache) SetWithTTL(key string, value any, ttl time.Duration) {
+	SetWithTTL(c.Cache, key, value, ttl)
+}
+
+// SetTagged stores an item under tags, if the cache supports them
+func (c *loaderCache) SetTagged(key string, value any, ttl time.Duration, tags ...string) {
+	SetTagged(c.Cache, key, value, ttl, tags...)
+}
+
+// InvalidateTags removes every entry carrying any of tags. The count is zero
+// on a cache without a tag index, which is cleared instead.
+func (c *loaderCache) InvalidateTags(tags ...string) int {
+	c.loader.invalidated()
+	if t, ok := c.Cache.(Tagger); ok {
+		return t.InvalidateTags(tags...)
+	}
+	c.Cache.Clear()
+	return 0
+}
+
+// Delete removes an item from the cache
+func (c *loaderCache) Delete(key string) {
+	c.loader.invalidated()
+	c.Cache.Delete(key)
+}
+
+// Clear removes all items from the cache
+func (c *loaderCache) Clear() {
+	c.loader.invalidated()
+	c.Cache.Clear()
+}
+
+// InvalidatePattern removes all cache entries that match a pattern
+func (c *loaderCache) InvalidatePattern(pattern string) {
+	c.loader.invalidated()
+	c.Cache.InvalidatePattern(pattern)
+}
+
+// Load is the typed form of (*Loader).Load. A cached value of another type,
+// such as one written by an older release, is dropped and reloaded.
+func Load[T any](ctx context.Context, l *Loader, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	return NewTyped(l, TypedOptions[T]{}).Load(ctx, key, load)
+}
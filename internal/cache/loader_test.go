@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLoader(t *testing.T, opts LoaderOptions) (*Loader, *Memory) {
+	t.Helper()
+
+	c := New(time.Minute)
+	t.Cleanup(c.Close)
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewLoader(c, opts), c
+}
+
+func TestLoader_CoalescesConcurrentMisses(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "programs", nil
+	}
+
+	const callers = 50
+	var wg sync.WaitGroup
+	var ready sync.WaitGroup
+	ready.Add(callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ready.Done()
+			got, err := Load(context.Background(), l, "programs:list:20:", load)
+			if err != nil || got != "programs" {
+				t.Errorf("Expected programs, got %q (%v)", got, err)
+			}
+		}()
+	}
+	ready.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("Expected 1 load, got %d", n)
+	}
+
+	// The result is cached, so later reads do not load again.
+	if _, err := Load(context.Background(), l, "programs:list:20:", load); err != nil {
+		t.Fatal(err)
+	}
+	if n := loads.Load(); n != 1 {
+		t.Errorf("Expected a cache hit, got %d loads", n)
+	}
+}
+
+func TestLoader_ErrorsAreNotCached(t *testing.T) {
+	l, c := newTestLoader(t, LoaderOptions{})
+
+	errBoom := errors.New("boom")
+	_, err := Load(context.Background(), l, "program:1", func(ctx context.Context) (string, error) {
+		return "", errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Expected errBoom, got %v", err)
+	}
+	if _, found := c.Get("program:1"); found {
+		t.Error("Expected a failed load not to be cached")
+	}
+
+	got, err := Load(context.Background(), l, "program:1", func(ctx context.Context) (string, error) {
+		return "program", nil
+	})
+	if err != nil || got != "program" {
+		t.Errorf("Expected program after a failed load, got %q (%v)", got, err)
+	}
+}
+
+func TestLoader_StaleWhileRevalidate(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{FreshFor: 20 * time.Millisecond})
+
+	var loads atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	load := func(ctx context.Context) (int32, error) {
+		n := loads.Add(1)
+		if n > 1 {
+			refreshed <- struct{}{}
+		}
+		return n, nil
+	}
+
+	if got, _ := Load(context.Background(), l, "categories:list", load); got != 1 {
+		t.Fatalf("Expected first load to return 1, got %d", got)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	// Stale: the old value comes back at once and a refresh starts.
+	if got, _ := Load(context.Background(), l, "categories:list", load); got != 1 {
+		t.Errorf("Expected the stale value 1, got %d", got)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a background refresh")
+	}
+
+	// Wait for the refreshed value to be stored.
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, _ := Load(context.Background(), l, "categories:list", load)
+		if got == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the refreshed value 2, got %d", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLoader_InvalidationDuringLoad(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+
+	var loads atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		if loads.Add(1) == 1 {
+			close(started)
+			<-release
+			return "stale", nil
+		}
+		return "fresh", nil
+	}
+
+	first := make(chan string)
+	go func() {
+		got, _ := Load(context.Background(), l, "program:1", load)
+		first <- got
+	}()
+	<-started
+
+	// The first load read before the invalidation, so a caller arriving
+	// after it must not join it.
+	InvalidateTags(l.Cache(), "program:1")
+	if got, _ := Load(context.Background(), l, "program:1", load); got != "fresh" {
+		t.Errorf("Expected a new load after the invalidation, got %q", got)
+	}
+
+	close(release)
+	if got := <-first; got != "stale" {
+		t.Errorf("Expected the first caller to get its own load, got %q", got)
+	}
+
+	// Nor may it overwrite the fresh value once it finishes.
+	if got, _ := Load(context.Background(), l, "program:1", load); got != "fresh" {
+		t.Errorf("Expected the fresh value to stay cached, got %q", got)
+	}
+	if n := loads.Load(); n != 2 {
+		t.Errorf("Expected 2 loads, got %d", n)
+	}
+}
+
+func TestLoader_CallerCancellationDoesNotFailOthers(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+
+	release := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		select {
+		case <-release:
+			return "value", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := Load(ctx, l, "key", load)
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	second := make(chan string, 1)
+	go func() {
+		v, _ := Load(context.Background(), l, "key", load)
+		second <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to get context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if v := <-second; v != "value" {
+		t.Errorf("Expected the other caller to get value, got %q", v)
+	}
+}
+
+func TestLoader_ReplacesEntriesOfAnotherType(t *testing.T) {
+	l, c := newTestLoader(t, LoaderOptions{})
+
+	c.Set("program:1", "written without the loader")
+	l.cache.Set("program:2", &loaded{Value: 42})
+
+	for _, key := range []string{"program:1", "program:2"} {
+		got, err := Load(context.Background(), l, key, func(ctx context.Context) (string, error) {
+			return "program", nil
+		})
+		if err != nil || got != "program" {
+			t.Errorf("%s: expected program, got %q (%v)", key, got, err)
+		}
+	}
+}
+
+func TestLoader_PanicBecomesError(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+
+	_, err := Load(context.Background(), l, "key", func(ctx context.Context) (string, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Error("Expected a panicking load to return an error")
+	}
+}
+
+func TestLoader_Redis(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+	l := NewLoader(c, LoaderOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	want := &redisTestProgram{ID: "1", Title: "Fnjan"}
+	load := func(ctx context.Context) (*redisTestProgram, error) {
+		return want, nil
+	}
+	if _, err := Load(context.Background(), l, "program:1", load); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read back through a fresh loader so the value really comes from Redis.
+	l = NewLoader(c, LoaderOptions{Logger: l.opts.Logger})
+	got, err := Load(context.Background(), l, "program:1", func(ctx context.Context) (*redisTestProgram, error) {
+		t.Error("Expected a cache hit")
+		return nil, nil
+	})
+	if err != nil || got.Title != want.Title {
+		t.Errorf("Expected %+v, got %+v (%v)", want, got, err)
+	}
+}
@@ -1,7 +1,10 @@
 package cache
 
 import (
+	"container/list"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,21 +20,114 @@ func (e *Entry) IsExpired() bool {
 	return time.Now().After(e.ExpiresAt)
 }
 
-// Memory provides thread-safe in-memory caching with TTL support
+// Options configures a Memory cache. Zero limits mean unbounded.
+type Options struct {
+	// TTL is how long an entry stays valid after it is set.
+	TTL time.Duration
+	// MaxEntries caps the number of entries held at once.
+	MaxEntries int
+	// MaxBytes caps the approximate memory held by keys and values. Sizes are
+	// estimated (see Sizeof), so treat this as a budget rather than a hard limit.
+	MaxBytes int64
+	// StatsPrefixes are the key prefixes counted separately in Stats. A key
+	// belongs to the longest prefix it equals or that is followed by ':' in
+	// it; other keys are counted under OtherPrefix. Defaults to
+	// DefaultStatsPrefixes.
+	StatsPrefixes []string
+}
+
+// DefaultStatsPrefixes groups the keys built in utils.go.
+var DefaultStatsPrefixes = []string{
+	"program",
+	"programs:list",
+	"programs:search",
+	"programs:category",
+	"programs:facets",
+	"categories",
+	"tags",
+	"episode",
+	"episodes",
+}
+
+// OtherPrefix collects keys that match none of the configured prefixes.
+const OtherPrefix = "other"
+
+// PrefixStats counts cache activity for one key prefix. Entries and Bytes
+// are current values; the rest are totals since the cache was created.
+type PrefixStats struct {
+	Hits        uint64
+	Misses      uint64
+	Sets        uint64
+	Evictions   uint64
+	Expirations uint64
+	Entries     int
+	Bytes       int64
+}
+
+// Stats is a snapshot of a Memory cache's size and activity counters, in
+// total and per key prefix.
+type Stats struct {
+	Entries     int
+	Bytes       int64
+	Hits        uint64
+	Misses      uint64
+	Sets        uint64
+	Evictions   uint64
+	Expirations uint64
+	Prefixes    map[string]PrefixStats
+}
+
+// item is the value stored in the LRU list.
+type item struct {
+	key    string
+	prefix string
+	tags   []string
+	entry  Entry
+	size   int64
+}
+
+// Memory provides thread-safe in-memory caching with TTL support. When a
+// limit is configured, the least recently used entries are evicted to stay
+// within it.
 type Memory struct {
-	mu    sync.RWMutex
-	items map[string]*Entry
-	ttl   time.Duration
+	mu    sync.Mutex
+	items map[string]*list.Element
+	lru   *list.List // front is most recently used
+	opts  Options
+	bytes int64
+	tags  map[string]map[*list.Element]struct{} // tag -> entries carrying it
+	stats map[string]*PrefixStats
 	stop  chan struct{}
 }
 
 // New creates a new memory cache instance with the specified TTL
 func New(ttl time.Duration) *Memory {
+	return NewWithOptions(Options{TTL: ttl})
+}
+
+// NewWithOptions creates a new memory cache instance with size limits
+func NewWithOptions(opts Options) *Memory {
+	if opts.StatsPrefixes == nil {
+		opts.StatsPrefixes = DefaultStatsPrefixes
+	}
+	// Longest first, so "programs:search" wins over "programs".
+	opts.StatsPrefixes = append([]string(nil), opts.StatsPrefixes...)
+	sort.Slice(opts.StatsPrefixes, func(i, j int) bool {
+		return len(opts.StatsPrefixes[i]) > len(opts.StatsPrefixes[j])
+	})
+
 	cache := &Memory{
-		items: make(map[string]*Entry),
-		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		lru:   list.New(),
+		opts:  opts,
+		tags:  make(map[string]map[*list.Element]struct{}),
+		stats: make(map[string]*PrefixStats, len(opts.StatsPrefixes)+1),
 		stop:  make(chan struct{}),
 	}
+	for _, prefix := range opts.StatsPrefixes {
+		cache.stats[prefix] = &PrefixStats{}
+	}
+	cache.stats[OtherPrefix] = &PrefixStats{}
 
 	// Start background cleanup goroutine
 	go cache.cleanup()
@@ -41,53 +137,111 @@ func New(ttl time.Duration) *Memory {
 
 // Set stores an item in the cache
 func (c *Memory) Set(key string, value any) {
+	c.SetWithTTL(key, value, c.opts.TTL)
+}
+
+// SetWithTTL stores an item that expires after ttl instead of the cache's TTL
+func (c *Memory) SetWithTTL(key string, value any, ttl time.Duration) {
+	c.SetTagged(key, value, ttl)
+}
+
+// SetTagged stores an item under tags, so InvalidateTags can find it without
+// scanning. A ttl of zero or less uses the cache's TTL.
+func (c *Memory) SetTagged(key string, value any, ttl time.Duration, tags ...string) {
+	if ttl <= 0 {
+		ttl = c.opts.TTL
+	}
+	size := int64(len(key)) + Sizeof(value)
+	prefix := c.prefixOf(key)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.items[key] = &Entry{
-		Data:      value,
-		ExpiresAt: time.Now().Add(c.ttl),
+	stats := c.stats[prefix]
+	stats.Sets++
+
+	if el, exists := c.items[key]; exists {
+		c.removeElement(el)
+	}
+
+	// An entry larger than the whole budget would only evict everything else.
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		stats.Evictions++
+		return
+	}
+
+	it := &item{
+		key:    key,
+		prefix: prefix,
+		tags:   tags,
+		entry:  Entry{Data: value, ExpiresAt: time.Now().Add(ttl)},
+		size:   size,
 	}
+	el := c.lru.PushFront(it)
+	c.items[key] = el
+	c.bytes += size
+	for _, tag := range tags {
+		entries, ok := c.tags[tag]
+		if !ok {
+			entries = make(map[*list.Element]struct{})
+			c.tags[tag] = entries
+		}
+		entries[el] = struct{}{}
+	}
+	stats.Entries++
+	stats.Bytes += size
+
+	c.evict()
 }
 
 // Get retrieves an item from the cache
 func (c *Memory) Get(key string) (any, bool) {
-	c.mu.RLock()
-	entry, exists := c.items[key]
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, exists := c.items[key]
 	if !exists {
-		c.mu.RUnlock()
+		c.stats[c.prefixOf(key)].Misses++
 		return nil, false
 	}
 
-	if entry.IsExpired() {
-		c.mu.RUnlock()
-		// Upgrade to write lock to remove expired entry
-		c.mu.Lock()
-		// Double-check after acquiring write lock
-		if entry, exists := c.items[key]; exists && entry.IsExpired() {
-			delete(c.items, key)
-		}
-		c.mu.Unlock()
+	it := el.Value.(*item)
+	stats := c.stats[it.prefix]
+	if it.entry.IsExpired() {
+		c.removeElement(el)
+		stats.Expirations++
+		stats.Misses++
 		return nil, false
 	}
 
-	data := entry.Data
-	c.mu.RUnlock()
-	return data, true
+	c.lru.MoveToFront(el)
+	stats.Hits++
+	return it.entry.Data, true
 }
 
 // Delete removes an item from the cache
 func (c *Memory) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.items, key)
+
+	if el, exists := c.items[key]; exists {
+		c.removeElement(el)
+	}
 }
 
 // Clear removes all items from the cache
 func (c *Memory) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.items = make(map[string]*Entry)
+
+	c.items = make(map[string]*list.Element)
+	c.lru.Init()
+	c.tags = make(map[string]map[*list.Element]struct{})
+	c.bytes = 0
+	for _, stats := range c.stats {
+		stats.Entries = 0
+		stats.Bytes = 0
+	}
 }
 
 // InvalidatePattern removes all cache entries that match a pattern
@@ -95,11 +249,82 @@ func (c *Memory) InvalidatePattern(pattern string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for key := range c.items {
+	for key, el := range c.items {
 		if match, _ := filepath.Match(pattern, key); match {
-			delete(c.items, key)
+			c.removeElement(el)
+		}
+	}
+}
+
+// InvalidateTags removes every entry carrying any of tags and returns how
+// many were removed. It only visits the affected entries.
+func (c *Memory) InvalidateTags(tags ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for _, tag := range tags {
+		for el := range c.tags[tag] {
+			c.removeElement(el)
+			removed++
 		}
 	}
+	return removed
+}
+
+// InvalidatePrefix removes every entry whose key starts with prefix and
+// returns how many were removed
+func (c *Memory) InvalidatePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Keys lists the live entries whose key starts with prefix, sorted by key
+func (c *Memory) Keys(prefix string) []KeyInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]KeyInfo, 0)
+	for key, el := range c.items {
+		it := el.Value.(*item)
+		if !strings.HasPrefix(key, prefix) || it.entry.IsExpired() {
+			continue
+		}
+		keys = append(keys, KeyInfo{Key: key, Bytes: it.size, ExpiresAt: it.entry.ExpiresAt})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys
+}
+
+// Stats returns the current size of the cache and its activity counters,
+// in total and per key prefix.
+func (c *Memory) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		Entries:  len(c.items),
+		Bytes:    c.bytes,
+		Prefixes: make(map[string]PrefixStats, len(c.stats)),
+	}
+	for prefix, p := range c.stats {
+		stats.Prefixes[prefix] = *p
+		stats.Hits += p.Hits
+		stats.Misses += p.Misses
+		stats.Sets += p.Sets
+		stats.Evictions += p.Evictions
+		stats.Expirations += p.Expirations
+	}
+	return stats
 }
 
 // Close stops the cleanup goroutine
@@ -107,6 +332,57 @@ func (c *Memory) Close() {
 	close(c.stop)
 }
 
+// evict drops least recently used entries until the cache is within its
+// limits. Callers must hold c.mu.
+func (c *Memory) evict() {
+	for c.overLimit() {
+		el := c.lru.Back()
+		if el == nil {
+			return
+		}
+		c.removeElement(el)
+		c.stats[el.Value.(*item).prefix].Evictions++
+	}
+}
+
+func (c *Memory) overLimit() bool {
+	if c.opts.MaxEntries > 0 && len(c.items) > c.opts.MaxEntries {
+		return true
+	}
+	return c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes
+}
+
+// removeElement unlinks an entry. Callers must hold c.mu.
+func (c *Memory) removeElement(el *list.Element) {
+	it := el.Value.(*item)
+	c.lru.Remove(el)
+	delete(c.items, it.key)
+	c.bytes -= it.size
+	for _, tag := range it.tags {
+		if entries, ok := c.tags[tag]; ok {
+			delete(entries, el)
+			if len(entries) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+
+	stats := c.stats[it.prefix]
+	stats.Entries--
+	stats.Bytes -= it.size
+}
+
+// prefixOf returns the stats prefix key belongs to. The prefix list is
+// fixed after construction, so this needs no lock.
+func (c *Memory) prefixOf(key string) string {
+	for _, prefix := range c.opts.StatsPrefixes {
+		if key == prefix || (strings.HasPrefix(key, prefix) && key[len(prefix)] == ':') {
+			return prefix
+		}
+	}
+	return OtherPrefix
+}
+
 // cleanup runs periodically to remove expired entries
 func (c *Memory) cleanup() {
 	ticker := time.NewTicker(time.Minute * 5) // Clean up every 5 minutes
@@ -116,9 +392,10 @@ func (c *Memory) cleanup() {
 		select {
 		case <-ticker.C:
 			c.mu.Lock()
-			for key, entry := range c.items {
-				if entry.IsExpired() {
-					delete(c.items, key)
+			for _, el := range c.items {
+				if it := el.Value.(*item); it.entry.IsExpired() {
+					c.removeElement(el)
+					c.stats[it.prefix].Expirations++
 				}
 			}
 			c.mu.Unlock()
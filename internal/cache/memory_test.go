@@ -2,6 +2,7 @@ package cache
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -204,6 +205,240 @@ func TestMemoryCache_DifferentTypes(t *testing.T) {
 }
 
 // Benchmark tests
+func TestMemoryCache_MaxEntriesEvictsLRU(t *testing.T) {
+	cache := NewWithOptions(Options{TTL: time.Minute, MaxEntries: 2})
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	// Touch key1 so key2 becomes the least recently used entry
+	cache.Get("key1")
+	cache.Set("key3", "value3")
+
+	if _, found := cache.Get("key2"); found {
+		t.Error("Expected key2 to be evicted")
+	}
+	for _, key := range []string{"key1", "key3"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("Expected %s to still be cached", key)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 {
+		t.Errorf("Expected 2 entries, got %d", stats.Entries)
+	}
+	if stats.Evictions != 1 {
+		t.Errorf("Expected 1 eviction, got %d", stats.Evictions)
+	}
+}
+
+func TestMemoryCache_MaxBytes(t *testing.T) {
+	budget := 3 * Sizeof(strings.Repeat("x", 100))
+	cache := NewWithOptions(Options{TTL: time.Minute, MaxBytes: budget})
+	defer cache.Close()
+
+	for i := range 10 {
+		cache.Set(fmt.Sprintf("k%d", i), strings.Repeat("x", 100))
+	}
+
+	stats := cache.Stats()
+	if stats.Bytes > budget {
+		t.Errorf("Expected at most %d bytes, got %d", budget, stats.Bytes)
+	}
+	if stats.Entries == 0 || stats.Entries >= 10 {
+		t.Errorf("Expected some but not all entries to be kept, got %d", stats.Entries)
+	}
+	if stats.Evictions == 0 {
+		t.Error("Expected evictions to be counted")
+	}
+	if _, found := cache.Get("k9"); !found {
+		t.Error("Expected the most recent entry to be kept")
+	}
+
+	// A value larger than the whole budget is rejected outright
+	cache.Set("huge", strings.Repeat("x", int(budget)))
+	if _, found := cache.Get("huge"); found {
+		t.Error("Expected oversized entry not to be cached")
+	}
+}
+
+func TestMemoryCache_BytesTracking(t *testing.T) {
+	cache := New(time.Minute)
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Set("key1", "value1") // overwrite must not double count
+	cache.Set("key2", []string{"a", "b"})
+	if cache.Stats().Bytes <= 0 {
+		t.Error("Expected tracked bytes to be positive")
+	}
+
+	cache.Delete("key1")
+	cache.InvalidatePattern("key*")
+	if stats := cache.Stats(); stats.Bytes != 0 || stats.Entries != 0 {
+		t.Errorf("Expected empty cache, got %+v", stats)
+	}
+}
+
+func TestMemoryCache_PrefixStats(t *testing.T) {
+	cache := NewWithOptions(Options{TTL: time.Minute, MaxEntries: 3})
+	defer cache.Close()
+
+	cache.Set("program:1", "p1")
+	cache.Set("programs:search:q:20:", "results")
+	cache.Set("categories:list", "cats")
+
+	cache.Get("program:1")             // hit
+	cache.Get("program:2")             // miss
+	cache.Get("programs:search:q:20:") // hit
+	cache.Get("programs:search:x:20:") // miss
+	cache.Get("unrelated")             // miss
+
+	// Evicts categories:list, the least recently used
+	cache.Set("programs:list:20:", "page")
+
+	stats := cache.Stats()
+
+	program := stats.Prefixes["program"]
+	if program.Sets != 1 || program.Hits != 1 || program.Misses != 1 || program.Entries != 1 {
+		t.Errorf("Unexpected program stats: %+v", program)
+	}
+	search := stats.Prefixes["programs:search"]
+	if search.Sets != 1 || search.Hits != 1 || search.Misses != 1 || search.Entries != 1 || search.Bytes <= 0 {
+		t.Errorf("Unexpected programs:search stats: %+v", search)
+	}
+	if categories := stats.Prefixes["categories"]; categories.Evictions != 1 || categories.Entries != 0 || categories.Bytes != 0 {
+		t.Errorf("Unexpected categories stats: %+v", categories)
+	}
+	if other := stats.Prefixes[OtherPrefix]; other.Misses != 1 {
+		t.Errorf("Unexpected other stats: %+v", other)
+	}
+
+	if stats.Hits != 2 || stats.Misses != 3 || stats.Sets != 4 || stats.Evictions != 1 || stats.Entries != 3 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+}
+
+func TestMemoryCache_PrefixStatsExpirations(t *testing.T) {
+	cache := NewWithOptions(Options{TTL: 20 * time.Millisecond})
+	defer cache.Close()
+
+	cache.Set("categories:list", "cats")
+	time.Sleep(40 * time.Millisecond)
+	cache.Get("categories:list")
+
+	categories := cache.Stats().Prefixes["categories"]
+	if categories.Expirations != 1 || categories.Misses != 1 || categories.Entries != 0 || categories.Bytes != 0 {
+		t.Errorf("Unexpected categories stats: %+v", categories)
+	}
+}
+
+func TestMemoryCache_KeysAndInvalidatePrefix(t *testing.T) {
+	cache := New(time.Minute)
+	defer cache.Close()
+
+	cache.Set("programs:search:b/c:20:", "results")
+	cache.Set("programs:search:a:20:", "results")
+	cache.Set("programs:list:20:", "page")
+
+	keys := cache.Keys("programs:search:")
+	if len(keys) != 2 || keys[0].Key != "programs:search:a:20:" || keys[1].Key != "programs:search:b/c:20:" {
+		t.Fatalf("Expected both search keys in order, got %+v", keys)
+	}
+	if keys[0].Bytes <= 0 || keys[0].ExpiresAt.IsZero() {
+		t.Errorf("Expected size and expiry to be reported, got %+v", keys[0])
+	}
+
+	// Unlike a pattern, a prefix also matches keys containing '/'.
+	if n := cache.InvalidatePrefix("programs:search:"); n != 2 {
+		t.Errorf("Expected 2 keys purged, got %d", n)
+	}
+	if len(cache.Keys("programs:search:")) != 0 {
+		t.Error("Expected no search keys after purge")
+	}
+	if _, found := cache.Get("programs:list:20:"); !found {
+		t.Error("Expected programs:list:20: to remain")
+	}
+}
+
+func TestMemoryCache_InvalidateTags(t *testing.T) {
+	cache := New(time.Minute)
+	defer cache.Close()
+
+	cache.SetTagged("program:1", "one", 0, "program:1", "category:a")
+	cache.SetTagged("programs:list:20:", "page", 0, "programs:list", "program:1", "program:2")
+	cache.SetTagged("programs:category:b:20:", "page", 0, "category:b", "program:2")
+	cache.Set("categories:list", "cats")
+
+	if n := cache.InvalidateTags("program:1"); n != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", n)
+	}
+	for _, key := range []string{"program:1", "programs:list:20:"} {
+		if _, found := cache.Get(key); found {
+			t.Errorf("Expected %s to be invalidated", key)
+		}
+	}
+	for _, key := range []string{"programs:category:b:20:", "categories:list"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("Expected %s to remain", key)
+		}
+	}
+
+	// The list page was removed, so program:2 now only marks one entry.
+	if n := cache.InvalidateTags("program:2", "missing"); n != 1 {
+		t.Errorf("Expected 1 entry removed, got %d", n)
+	}
+	if len(cache.tags) != 0 {
+		t.Errorf("Expected the tag index to be empty, got %v", cache.tags)
+	}
+}
+
+func TestMemoryCache_TagIndexFollowsEntries(t *testing.T) {
+	cache := NewWithOptions(Options{TTL: time.Minute, MaxEntries: 1})
+	defer cache.Close()
+
+	cache.SetTagged("a", 1, 0, "old")
+	cache.SetTagged("a", 2, 0, "new")
+	if n := cache.InvalidateTags("old"); n != 0 {
+		t.Errorf("Expected an overwritten entry to drop its old tags, removed %d", n)
+	}
+
+	cache.SetTagged("b", 3, 0, "new")
+	if _, found := cache.tags["new"]; !found || len(cache.tags["new"]) != 1 {
+		t.Errorf("Expected the evicted entry to leave the index, got %v", cache.tags)
+	}
+
+	cache.Delete("b")
+	cache.SetTagged("c", 4, 0, "other")
+	cache.Clear()
+	if len(cache.tags) != 0 {
+		t.Errorf("Expected Delete and Clear to empty the index, got %v", cache.tags)
+	}
+}
+
+func TestSizeof(t *testing.T) {
+	type row struct {
+		Title string
+		Tags  []string
+	}
+
+	small := Sizeof(row{Title: "a"})
+	large := Sizeof(row{Title: strings.Repeat("a", 1000), Tags: []string{"x", "y"}})
+	if large-small < 1000 {
+		t.Errorf("Expected size to grow with content, got small=%d large=%d", small, large)
+	}
+
+	rows := make([]*row, 10)
+	for i := range rows {
+		rows[i] = &row{Title: strings.Repeat("a", 100)}
+	}
+	if Sizeof(rows) < 1000 {
+		t.Errorf("Expected pointed-to rows to be counted, got %d", Sizeof(rows))
+	}
+}
+
 func BenchmarkMemoryCache_Set(b *testing.B) {
 	cache := New(time.Minute)
 	defer cache.Close()
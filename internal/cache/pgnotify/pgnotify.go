@@ -0,0 +1,242 @@
+// Package pgnotify keeps per-replica caches consistent by broadcasting
+// invalidations through Postgres LISTEN/NOTIFY. Each replica keeps its own
+// local cache; deletes, pattern invalidations and clears are applied locally
+// and published with pg_notify, and every other replica applies them to its
+// own copy.
+package pgnotify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/khatibomar/gomania/internal/cache"
+)
+
+// Channel is the NOTIFY channel invalidations are published on.
+const Channel = "gomania_cache_invalidation"
+
+const (
+	publishTimeout    = 2 * time.Second
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+const (
+	opDelete  = "delete"
+	opPattern = "pattern"
+	opPrefix  = "prefix"
+	opTags    = "tags"
+	opClear   = "clear"
+)
+
+// event is the JSON payload of one notification. Origin identifies the
+// replica that published it, so a replica does not re-apply its own events.
+type event struct {
+	Origin string   `json:"origin"`
+	Op     string   `json:"op"`
+	Key    string   `json:"key,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+var _ cache.Cache = (*Cache)(nil)
+
+// Cache wraps a local cache and broadcasts its invalidations. Reads and
+// writes only touch the local cache.
+type Cache struct {
+	cache.Cache
+
+	pool    *pgxpool.Pool
+	logger  *slog.Logger
+	origin  string
+	publish func(ctx context.Context, payload string) error
+}
+
+// New wraps local so that invalidations reach every replica sharing pool's
+// database. Call Listen to receive the other replicas' invalidations.
+func New(pool *pgxpool.Pool, local cache.Cache, logger *slog.Logger) *Cache {
+	c := &Cache{
+		Cache:  local,
+		pool:   pool,
+		logger: logger,
+		origin: uuid.NewString(),
+	}
+	c.publish = c.notify
+	return c
+}
+
+// SetWithTTL stores an item locally with its own lifetime, if the local
+// cache supports one
+func (c *Cache) SetWithTTL(key string, value any, ttl time.Duration) {
+	cache.SetWithTTL(c.Cache, key, value, ttl)
+}
+
+// SetTagged stores an item locally under tags, if the local cache supports
+// them
+func (c *Cache) SetTagged(key string, value any, ttl time.Duration, tags ...string) {
+	cache.SetTagged(c.Cache, key, value, ttl, tags...)
+}
+
+// InvalidateTags removes tagged entries locally and on every other replica.
+// The count is for the local cache only.
+func (c *Cache) InvalidateTags(tags ...string) int {
+	removed := 0
+	if t, ok := c.Cache.(cache.Tagger); ok {
+		removed = t.InvalidateTags(tags...)
+	} else {
+		c.Cache.Clear()
+	}
+	c.broadcast(event{Op: opTags, Tags: tags})
+	return removed
+}
+
+// Delete removes an item locally and on every other replica
+func (c *Cache) Delete(key string) {
+	c.Cache.Delete(key)
+	c.broadcast(event{Op: opDelete, Key: key})
+}
+
+// InvalidatePattern removes matching entries locally and on every other replica
+func (c *Cache) InvalidatePattern(pattern string) {
+	c.Cache.InvalidatePattern(pattern)
+	c.broadcast(event{Op: opPattern, Key: pattern})
+}
+
+// InvalidatePrefix removes entries under prefix locally and on every other
+// replica. The count is for the local cache only.
+func (c *Cache) InvalidatePrefix(prefix string) int {
+	removed := 0
+	if p, ok := c.Cache.(cache.PrefixInvalidator); ok {
+		removed = p.InvalidatePrefix(prefix)
+	}
+	c.broadcast(event{Op: opPrefix, Key: prefix})
+	return removed
+}
+
+// Keys lists the local cache's entries under prefix
+func (c *Cache) Keys(prefix string) []cache.KeyInfo {
+	if i, ok := c.Cache.(cache.Inspector); ok {
+		return i.Keys(prefix)
+	}
+	return []cache.KeyInfo{}
+}
+
+// Clear empties the local cache and every other replica's
+func (c *Cache) Clear() {
+	c.Cache.Clear()
+	c.broadcast(event{Op: opClear})
+}
+
+// Listen applies invalidations published by other replicas until ctx is
+// done. It holds one connection taken out of the pool for its whole life and
+// reconnects with backoff if that connection drops. Notifications sent while
+// disconnected are lost, so the local cache is cleared after reconnecting.
+func (c *Cache) Listen(ctx context.Context) {
+	delay := minReconnectDelay
+	reconnected := false
+
+	for {
+		err := c.listen(ctx, func() {
+			if reconnected {
+				c.logger.Info("Cache invalidation listener reconnected, clearing local cache")
+				c.Cache.Clear()
+			}
+			reconnected = true
+			delay = minReconnectDelay
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.logger.Error("Cache invalidation listener failed", "error", err, "retry_in", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// listen runs one LISTEN session. onListening is called once the session is
+// subscribed.
+func (c *Cache) listen(ctx context.Context, onListening func()) error {
+	pooled, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The session keeps LISTEN state, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", Channel, err)
+	}
+	onListening()
+	c.logger.Info("Listening for cache invalidations", "channel", Channel)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		c.handle(n.Payload)
+	}
+}
+
+// handle applies one notification payload to the local cache.
+func (c *Cache) handle(payload string) {
+	var e event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		c.logger.Warn("Ignoring malformed cache invalidation", "payload", payload, "error", err)
+		return
+	}
+	if e.Origin == c.origin {
+		return
+	}
+
+	switch e.Op {
+	case opDelete:
+		c.Cache.Delete(e.Key)
+	case opPattern:
+		c.Cache.InvalidatePattern(e.Key)
+	case opTags:
+		cache.InvalidateTags(c.Cache, e.Tags...)
+	case opPrefix:
+		if p, ok := c.Cache.(cache.PrefixInvalidator); ok {
+			p.InvalidatePrefix(e.Key)
+		}
+	case opClear:
+		c.Cache.Clear()
+	default:
+		c.logger.Warn("Ignoring unknown cache invalidation", "op", e.Op)
+		return
+	}
+	c.logger.Debug("Applied cache invalidation", "op", e.Op, "key", e.Key, "tags", e.Tags, "origin", e.Origin)
+}
+
+func (c *Cache) broadcast(e event) {
+	e.Origin = c.origin
+	payload, err := json.Marshal(e)
+	if err != nil {
+		c.logger.Error("Failed to encode cache invalidation", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := c.publish(ctx, string(payload)); err != nil {
+		c.logger.Error("Failed to publish cache invalidation", "op", e.Op, "key", e.Key, "error", err)
+	}
+}
+
+func (c *Cache) notify(ctx context.Context, payload string) error {
+	_, err := c.pool.Exec(ctx, "SELECT pg_notify($1, $2)", Channel, payload)
+	return err
+}
@@ -0,0 +1,155 @@
+package pgnotify
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/khatibomar/gomania/internal/cache"
+)
+
+// newReplicas builds caches that deliver notifications to each other in
+// process, the way Postgres delivers them to every listener.
+func newReplicas(t *testing.T, n int) ([]*Cache, []*cache.Memory) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	replicas := make([]*Cache, n)
+	locals := make([]*cache.Memory, n)
+	publish := func(_ context.Context, payload string) error {
+		for _, r := range replicas {
+			r.handle(payload)
+		}
+		return nil
+	}
+
+	for i := range replicas {
+		locals[i] = cache.New(time.Minute)
+		t.Cleanup(locals[i].Close)
+		replicas[i] = New(nil, locals[i], logger)
+		replicas[i].publish = publish
+	}
+	return replicas, locals
+}
+
+func TestDeleteReachesOtherReplicas(t *testing.T) {
+	replicas, locals := newReplicas(t, 2)
+
+	replicas[0].Set("program:1", "old")
+	replicas[1].Set("program:1", "old")
+
+	replicas[0].Delete("program:1")
+
+	for i, local := range locals {
+		if _, found := local.Get("program:1"); found {
+			t.Errorf("replica %d still has program:1", i)
+		}
+	}
+}
+
+func TestInvalidatePatternReachesOtherReplicas(t *testing.T) {
+	replicas, locals := newReplicas(t, 2)
+
+	replicas[1].Set("programs:list:20:", "page")
+	replicas[1].Set("programs:search:q:20:", "results")
+
+	replicas[0].InvalidatePattern("programs:list:*")
+
+	if _, found := locals[1].Get("programs:list:20:"); found {
+		t.Error("Expected programs:list:20: to be invalidated on replica 1")
+	}
+	if _, found := locals[1].Get("programs:search:q:20:"); !found {
+		t.Error("Expected programs:search:q:20: to remain on replica 1")
+	}
+}
+
+func TestInvalidatePrefixReachesOtherReplicas(t *testing.T) {
+	replicas, locals := newReplicas(t, 2)
+
+	replicas[0].Set("programs:search:a/b:20:", "mine")
+	replicas[1].Set("programs:search:c:20:", "theirs")
+	replicas[1].Set("categories:list", "cats")
+
+	if n := replicas[0].InvalidatePrefix("programs:search:"); n != 1 {
+		t.Errorf("Expected 1 local key purged, got %d", n)
+	}
+
+	for i, local := range locals {
+		if keys := local.Keys("programs:search:"); len(keys) != 0 {
+			t.Errorf("replica %d still has %v", i, keys)
+		}
+	}
+	if _, found := locals[1].Get("categories:list"); !found {
+		t.Error("Expected categories:list to remain on replica 1")
+	}
+}
+
+func TestInvalidateTagsReachesOtherReplicas(t *testing.T) {
+	replicas, locals := newReplicas(t, 2)
+
+	for _, r := range replicas {
+		r.SetTagged("program:1", "one", 0, cache.ProgramTag("1"))
+		r.SetTagged("programs:list:20:", "page", 0, cache.TagProgramsList, cache.ProgramTag("2"))
+	}
+
+	if n := replicas[0].InvalidateTags(cache.ProgramTag("1")); n != 1 {
+		t.Errorf("Expected 1 local entry removed, got %d", n)
+	}
+
+	for i, local := range locals {
+		if _, found := local.Get("program:1"); found {
+			t.Errorf("replica %d still has program:1", i)
+		}
+		if _, found := local.Get("programs:list:20:"); !found {
+			t.Errorf("replica %d lost programs:list:20:", i)
+		}
+	}
+}
+
+func TestClearReachesOtherReplicas(t *testing.T) {
+	replicas, locals := newReplicas(t, 2)
+
+	replicas[1].Set("key1", "value1")
+	replicas[0].Clear()
+
+	if stats := locals[1].Stats(); stats.Entries != 0 {
+		t.Errorf("Expected replica 1 to be empty, got %d entries", stats.Entries)
+	}
+}
+
+func TestSetStaysLocal(t *testing.T) {
+	replicas, locals := newReplicas(t, 2)
+
+	replicas[0].Set("key1", "value1")
+
+	if _, found := locals[1].Get("key1"); found {
+		t.Error("Expected Set not to be broadcast")
+	}
+}
+
+func TestOwnEventsAreSkipped(t *testing.T) {
+	replicas, locals := newReplicas(t, 1)
+
+	// An event that comes back to its origin must not undo a Set made after it.
+	payload := `{"origin":"` + replicas[0].origin + `","op":"delete","key":"key1"}`
+	replicas[0].Set("key1", "value1")
+	replicas[0].handle(payload)
+
+	if _, found := locals[0].Get("key1"); !found {
+		t.Error("Expected a replica to ignore its own notification")
+	}
+}
+
+func TestMalformedPayloadIsIgnored(t *testing.T) {
+	replicas, locals := newReplicas(t, 1)
+
+	replicas[0].Set("key1", "value1")
+	replicas[0].handle("not json")
+	replicas[0].handle(`{"origin":"other","op":"explode","key":"key1"}`)
+
+	if _, found := locals[0].Get("key1"); !found {
+		t.Error("Expected malformed notifications to be ignored")
+	}
+}
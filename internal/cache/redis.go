@@ -0,0 +1,611 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRedisPrefix namespaces every key this application writes, so Clear
+// and pattern invalidation never touch keys that belong to someone else.
+const DefaultRedisPrefix = "gomania:"
+
+// redisTagNamespace follows the key prefix for the sets that index tags, and
+// redisRetiredNamespace for tag sets that are being invalidated. '~' sorts
+// after the letters used by cache keys, so these sets never share a prefix
+// with them.
+const (
+	redisTagNamespace     = "~tag:"
+	redisRetiredNamespace = "~retired:"
+)
+
+const (
+	defaultRedisPoolSize = 10
+	defaultRedisTimeout  = 2 * time.Second
+	redisScanCount       = "500"
+	// redisTagAttempts bounds how often a tagged write is retried when its
+	// tag sets change under it.
+	redisTagAttempts = 5
+)
+
+// RedisOptions configures a Redis cache.
+type RedisOptions struct {
+	// Addr is the host:port of the server.
+	Addr string
+	// Password, when set, is sent with AUTH on every new connection.
+	Password string
+	// DB selects the logical database.
+	DB int
+	// TTL is how long an entry stays valid after it is set.
+	TTL time.Duration
+	// Prefix is prepended to every key. Defaults to DefaultRedisPrefix.
+	Prefix string
+	// PoolSize caps how many idle connections are kept. Defaults to 10.
+	PoolSize int
+	// Timeout bounds dialing and each command round trip. Defaults to 2s.
+	Timeout time.Duration
+	// Codec serializes values. Defaults to GobCodec.
+	Codec Codec
+	// Logger receives errors, since Cache methods cannot return them.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Redis is a Cache stored in a Redis-compatible server, so every replica of
+// the API shares one view. It speaks RESP directly and keeps a small pool of
+// connections.
+//
+// A cache is an optimisation, so failures are logged and reported as misses
+// rather than surfaced to callers.
+type Redis struct {
+	opts RedisOptions
+	pool chan *redisConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewRedis connects to the server described by opts and checks that it
+// answers PING.
+func NewRedis(opts RedisOptions) (*Redis, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultRedisPrefix
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = defaultRedisPoolSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRedisTimeout
+	}
+	if opts.Codec == nil {
+		opts.Codec = GobCodec{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	c := &Redis{
+		opts: opts,
+		pool: make(chan *redisConn, opts.PoolSize),
+	}
+
+	if _, err := c.do("PING"); err != nil {
+		return nil, fmt.Errorf("failed to reach redis at %s: %w", opts.Addr, err)
+	}
+	return c, nil
+}
+
+// Set stores an item in the cache
+func (c *Redis) Set(key string, value any) {
+	c.SetWithTTL(key, value, c.opts.TTL)
+}
+
+// SetWithTTL stores an item that expires after ttl instead of the cache's TTL
+func (c *Redis) SetWithTTL(key string, value any, ttl time.Duration) {
+	c.SetTagged(key, value, ttl)
+}
+
+// SetTagged stores an item and adds its key to a set per tag. Tag sets live
+// at least as long as their longest-lived member; stale members left behind
+// by expired entries are harmless and go when the tag is invalidated.
+//
+// The entry and its tags are written in one MULTI/EXEC transaction, so an
+// entry is never visible without its tags.
+func (c *Redis) SetTagged(key string, value any, ttl time.Duration, tags ...string) {
+	if ttl <= 0 {
+		ttl = c.opts.TTL
+	}
+	data, err := c.opts.Codec.Marshal(value)
+	if err != nil {
+		c.opts.Logger.Error("Failed to encode cache entry", "key", key, "error", err)
+		return
+	}
+
+	set := []string{"SET", c.opts.Prefix + key, string(data)}
+	if ttl > 0 {
+		set = append(set, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	if len(tags) == 0 {
+		_, err = c.do(set...)
+	} else {
+		err = c.setTagged(set, c.opts.Prefix+key, ttl, tags)
+	}
+	if err != nil {
+		c.opts.Logger.Error("Failed to set cache entry", "key", key, "error", err)
+	}
+}
+
+// setTagged runs set together with adding key to every tag set. The tag sets
+// are watched while their lifetimes are read; if one changes before EXEC,
+// for instance because it was invalidated, the transaction is aborted and
+// tried again.
+func (c *Redis) setTagged(set []string, key string, ttl time.Duration, tags []string) error {
+	conn, err := c.get()
+	if err != nil {
+		return err
+	}
+
+	for range redisTagAttempts {
+		stored, err := c.trySetTagged(conn, set, key, ttl, tags)
+		if err != nil {
+			// The connection may be left inside WATCH or MULTI.
+			conn.Close()
+			return err
+		}
+		if stored {
+			c.put(conn)
+			return nil
+		}
+	}
+
+	c.put(conn)
+	return errors.New("tag sets kept changing during the write")
+}
+
+func (c *Redis) trySetTagged(conn *redisConn, set []string, key string, ttl time.Duration, tags []string) (bool, error) {
+	tagKeys := make([]string, len(tags))
+	for i, tag := range tags {
+		tagKeys[i] = c.tagKey(tag)
+	}
+	if _, err := conn.do(c.opts.Timeout, append([]string{"WATCH"}, tagKeys...)...); err != nil {
+		return false, err
+	}
+
+	cmds := [][]string{set}
+	for _, tagKey := range tagKeys {
+		// PTTL is -2 for a new set and -1 for one that never expires.
+		reply, err := conn.do(c.opts.Timeout, "PTTL", tagKey)
+		if err != nil {
+			return false, err
+		}
+		current, _ := reply.(int64)
+
+		cmds = append(cmds, []string{"SADD", tagKey, key})
+		switch {
+		case ttl <= 0:
+			if current != -1 {
+				cmds = append(cmds, []string{"PERSIST", tagKey})
+			}
+		case current == -2 || (current >= 0 && current < ttl.Milliseconds()):
+			// Only ever extend the set's lifetime.
+			cmds = append(cmds, []string{"PEXPIRE", tagKey, strconv.FormatInt(ttl.Milliseconds(), 10)})
+		}
+	}
+
+	if _, err := conn.do(c.opts.Timeout, "MULTI"); err != nil {
+		return false, err
+	}
+	for _, cmd := range cmds {
+		if _, err := conn.do(c.opts.Timeout, cmd...); err != nil {
+			return false, err
+		}
+	}
+	// EXEC replies with a nil array when a watched key changed.
+	reply, err := conn.do(c.opts.Timeout, "EXEC")
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// InvalidateTags removes every entry carrying any of tags, along with the tag
+// sets, and returns how many entries were removed
+func (c *Redis) InvalidateTags(tags ...string) int {
+	removed := 0
+	for _, tag := range tags {
+		// Move the set aside before reading it, so an entry tagged meanwhile
+		// starts a new set rather than being dropped along with this one.
+		retired := c.opts.Prefix + redisRetiredNamespace + tag + ":" + rand.Text()
+		if _, err := c.do("RENAME", c.tagKey(tag), retired); err != nil {
+			var replyErr redisError
+			if !errors.As(err, &replyErr) || !strings.Contains(string(replyErr), "no such key") {
+				c.opts.Logger.Error("Failed to retire cache tag", "tag", tag, "error", err)
+			}
+			continue
+		}
+
+		reply, err := c.do("SMEMBERS", retired)
+		if err != nil {
+			c.opts.Logger.Error("Failed to read cache tag", "tag", tag, "error", err)
+			continue
+		}
+
+		members, _ := reply.([]any)
+		keys := make([]string, 0, len(members)+1)
+		for _, m := range members {
+			if key, ok := m.([]byte); ok {
+				keys = append(keys, string(key))
+			}
+		}
+
+		if len(keys) > 0 {
+			reply, err := c.do(append([]string{"DEL"}, keys...)...)
+			if err != nil {
+				c.opts.Logger.Error("Failed to invalidate cache tag", "tag", tag, "error", err)
+				continue
+			}
+			n, _ := reply.(int64)
+			removed += int(n)
+		}
+		if _, err := c.do("DEL", retired); err != nil {
+			c.opts.Logger.Error("Failed to remove cache tag", "tag", tag, "error", err)
+		}
+	}
+	return removed
+}
+
+func (c *Redis) tagKey(tag string) string {
+	return c.opts.Prefix + redisTagNamespace + tag
+}
+
+// Get retrieves an item from the cache
+func (c *Redis) Get(key string) (any, bool) {
+	reply, err := c.do("GET", c.opts.Prefix+key)
+	if err != nil {
+		c.opts.Logger.Error("Failed to get cache entry", "key", key, "error", err)
+		return nil, false
+	}
+	if reply == nil {
+		return nil, false
+	}
+
+	data, ok := reply.([]byte)
+	if !ok {
+		c.opts.Logger.Error("Unexpected reply type for cache entry", "key", key, "type", fmt.Sprintf("%T", reply))
+		return nil, false
+	}
+
+	value, err := c.opts.Codec.Unmarshal(data)
+	if err != nil {
+		// Usually a type that is no longer registered after a deploy; drop it so
+		// the next read repopulates the entry.
+		c.opts.Logger.Warn("Undecodable cache entry, removing", "key", key, "error", err)
+		c.Delete(key)
+		return nil, false
+	}
+	return value, true
+}
+
+// Delete removes an item from the cache
+func (c *Redis) Delete(key string) {
+	if _, err := c.do("DEL", c.opts.Prefix+key); err != nil {
+		c.opts.Logger.Error("Failed to delete cache entry", "key", key, "error", err)
+	}
+}
+
+// Clear removes all items under the cache's prefix
+func (c *Redis) Clear() {
+	c.InvalidatePattern("*")
+}
+
+// InvalidatePattern removes all cache entries that match a pattern. Patterns
+// use Redis glob syntax, where unlike filepath.Match '*' also matches '/'.
+func (c *Redis) InvalidatePattern(pattern string) {
+	keys, err := c.scan(c.opts.Prefix + pattern)
+	if err != nil {
+		c.opts.Logger.Error("Failed to scan cache entries", "pattern", pattern, "error", err)
+		return
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	if _, err := c.do(append([]string{"DEL"}, keys...)...); err != nil {
+		c.opts.Logger.Error("Failed to invalidate cache entries", "pattern", pattern, "error", err)
+	}
+}
+
+// InvalidatePrefix removes every entry whose key starts with prefix and
+// returns how many were removed
+func (c *Redis) InvalidatePrefix(prefix string) int {
+	keys, err := c.scan(c.opts.Prefix + escapeGlob(prefix) + "*")
+	if err != nil {
+		c.opts.Logger.Error("Failed to scan cache entries", "prefix", prefix, "error", err)
+		return 0
+	}
+	if len(keys) == 0 {
+		return 0
+	}
+
+	reply, err := c.do(append([]string{"DEL"}, keys...)...)
+	if err != nil {
+		c.opts.Logger.Error("Failed to invalidate cache entries", "prefix", prefix, "error", err)
+		return 0
+	}
+	n, _ := reply.(int64)
+	return int(n)
+}
+
+// Keys lists the live entries whose key starts with prefix, sorted by key.
+// Sizes are the stored, encoded sizes.
+func (c *Redis) Keys(prefix string) []KeyInfo {
+	keys, err := c.scan(c.opts.Prefix + escapeGlob(prefix) + "*")
+	if err != nil {
+		c.opts.Logger.Error("Failed to scan cache entries", "prefix", prefix, "error", err)
+		return []KeyInfo{}
+	}
+	sort.Strings(keys)
+
+	infos := make([]KeyInfo, 0, len(keys))
+	now := time.Now()
+	for _, key := range keys {
+		if strings.HasPrefix(key, c.opts.Prefix+redisTagNamespace) ||
+			strings.HasPrefix(key, c.opts.Prefix+redisRetiredNamespace) {
+			continue
+		}
+		info := KeyInfo{Key: strings.TrimPrefix(key, c.opts.Prefix)}
+		if reply, err := c.do("STRLEN", key); err == nil {
+			n, _ := reply.(int64)
+			info.Bytes = n
+		}
+		// PTTL is -2 for a key that has gone since the scan and -1 for one
+		// without an expiry.
+		if reply, err := c.do("PTTL", key); err == nil {
+			ms, _ := reply.(int64)
+			if ms == -2 {
+				continue
+			}
+			if ms >= 0 {
+				info.ExpiresAt = now.Add(time.Duration(ms) * time.Millisecond)
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Close releases the pooled connections
+func (c *Redis) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.pool)
+	for conn := range c.pool {
+		conn.Close()
+	}
+}
+
+// scan collects every key matching match with SCAN, which unlike KEYS does
+// not block the server on large databases.
+func (c *Redis) scan(match string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", match, "COUNT", redisScanCount)
+		if err != nil {
+			return nil, err
+		}
+
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected SCAN reply: %v", reply)
+		}
+		next, ok := parts[0].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected SCAN cursor: %v", parts[0])
+		}
+		batch, _ := parts[1].([]any)
+		for _, k := range batch {
+			if key, ok := k.([]byte); ok {
+				keys = append(keys, string(key))
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" {
+			return keys, nil
+		}
+	}
+}
+
+// do runs one command on a pooled connection. Connections that fail at the
+// network level are discarded rather than returned to the pool.
+func (c *Redis) do(args ...string) (any, error) {
+	conn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(c.opts.Timeout, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.Close()
+		return nil, err
+	}
+
+	c.put(conn)
+	return reply, err
+}
+
+func (c *Redis) get() (*redisConn, error) {
+	select {
+	case conn, ok := <-c.pool:
+		if ok {
+			return conn, nil
+		}
+		return nil, errors.New("redis cache is closed")
+	default:
+	}
+
+	return dialRedis(c.opts)
+}
+
+func (c *Redis) put(conn *redisConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		conn.Close()
+		return
+	}
+	select {
+	case c.pool <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// escapeGlob quotes the characters Redis treats specially in MATCH patterns.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// redisError is an error reply from the server. The connection that received
+// it is still usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func dialRedis(opts RedisOptions) (*redisConn, error) {
+	nc, err := net.DialTimeout("tcp", opts.Addr, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	if opts.Password != "" {
+		if _, err := conn.do(opts.Timeout, "AUTH", opts.Password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if opts.DB != 0 {
+		if _, err := conn.do(opts.Timeout, "SELECT", strconv.Itoa(opts.DB)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to select database %d: %w", opts.DB, err)
+		}
+	}
+	return conn, nil
+}
+
+// do writes a command as a RESP array of bulk strings and reads one reply.
+func (c *redisConn) do(timeout time.Duration, args ...string) (any, error) {
+	if err := c.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.r)
+}
+
+// readReply parses one RESP2 reply. Simple strings and integers come back as
+// string and int64, bulk strings as []byte, arrays as []any, and nil bulk
+// strings or arrays as nil. Error replies are returned as redisError.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			item, err := readReply(r)
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
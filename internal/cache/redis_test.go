@@ -0,0 +1,719 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a minimal in-process RESP server that understands the
+// commands the Redis cache sends.
+type fakeRedis struct {
+	t        *testing.T
+	ln       net.Listener
+	password string
+
+	mu      sync.Mutex
+	data    map[string][]byte
+	sets    map[string]map[string]struct{}
+	expires map[string]time.Time
+	// versions counts writes per key, for WATCH.
+	versions map[string]int
+	// hooks run once, before the next command with their name is executed.
+	hooks map[string]func()
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeRedis{
+		t:        t,
+		ln:       ln,
+		password: password,
+		data:     make(map[string][]byte),
+		sets:     make(map[string]map[string]struct{}),
+		expires:  make(map[string]time.Time),
+		versions: make(map[string]int),
+		hooks:    make(map[string]func()),
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go s.serve()
+	return s
+}
+
+func (s *fakeRedis) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeRedis) set(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+func (s *fakeRedis) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.alive(key)
+}
+
+// before runs fn once, just before the next command named name. It lets a
+// test interleave its own calls with a multi-command operation.
+func (s *fakeRedis) before(name string, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks[name] = fn
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+	var queued [][]string
+	var watched map[string]int
+	for {
+		req, err := readReply(r)
+		if err != nil {
+			if err != io.EOF {
+				s.t.Logf("fake redis: %v", err)
+			}
+			return
+		}
+		parts, _ := req.([]any)
+		args := make([]string, len(parts))
+		for i, p := range parts {
+			args[i] = string(p.([]byte))
+		}
+
+		var reply string
+		switch {
+		case len(args) == 0:
+			reply = "-ERR empty command\r\n"
+		case strings.EqualFold(args[0], "AUTH"):
+			if len(args) == 2 && args[1] == s.password {
+				authed = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case strings.EqualFold(args[0], "WATCH"):
+			watched = s.watch(watched, args[1:])
+			reply = "+OK\r\n"
+		case strings.EqualFold(args[0], "MULTI"):
+			queued = [][]string{}
+			reply = "+OK\r\n"
+		case strings.EqualFold(args[0], "EXEC"):
+			s.hook(args[0])
+			reply = s.execQueued(queued, watched)
+			queued, watched = nil, nil
+		case queued != nil:
+			queued = append(queued, args)
+			reply = "+QUEUED\r\n"
+		default:
+			s.hook(args[0])
+			reply = s.exec(args)
+		}
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) hook(name string) {
+	s.mu.Lock()
+	fn := s.hooks[strings.ToUpper(name)]
+	delete(s.hooks, strings.ToUpper(name))
+	s.mu.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+}
+
+// watch records the current version of keys on top of watched.
+func (s *fakeRedis) watch(watched map[string]int, keys []string) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if watched == nil {
+		watched = make(map[string]int)
+	}
+	for _, key := range keys {
+		watched[key] = s.versions[key]
+	}
+	return watched
+}
+
+// execQueued runs a MULTI block, or replies with a nil array if a watched key
+// was written since WATCH.
+func (s *fakeRedis) execQueued(queued [][]string, watched map[string]int) string {
+	if queued == nil {
+		return "-ERR EXEC without MULTI\r\n"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, version := range watched {
+		if s.versions[key] != version {
+			return "*-1\r\n"
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(queued))
+	for _, args := range queued {
+		b.WriteString(s.run(args))
+	}
+	return b.String()
+}
+
+func (s *fakeRedis) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.run(args)
+}
+
+// run executes one command. Callers must hold s.mu.
+func (s *fakeRedis) run(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "SET", "SADD", "PEXPIRE", "PERSIST":
+		s.versions[args[1]]++
+	case "RENAME", "DEL":
+		for _, key := range args[1:] {
+			s.versions[key]++
+		}
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "SET":
+		delete(s.sets, args[1])
+		s.data[args[1]] = []byte(args[2])
+		delete(s.expires, args[1])
+		if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+			ms, _ := strconv.Atoi(args[4])
+			s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "GET":
+		v, ok := s.lookup(args[1])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(string(v))
+	case "STRLEN":
+		if _, ok := s.sets[args[1]]; ok {
+			return "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+		}
+		v, _ := s.lookup(args[1])
+		return fmt.Sprintf(":%d\r\n", len(v))
+	case "SADD":
+		s.alive(args[1])
+		set, ok := s.sets[args[1]]
+		if !ok {
+			set = make(map[string]struct{})
+			s.sets[args[1]] = set
+		}
+		n := 0
+		for _, member := range args[2:] {
+			if _, ok := set[member]; !ok {
+				set[member] = struct{}{}
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SMEMBERS":
+		s.alive(args[1])
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", len(s.sets[args[1]]))
+		for member := range s.sets[args[1]] {
+			b.WriteString(bulk(member))
+		}
+		return b.String()
+	case "PEXPIRE":
+		if !s.alive(args[1]) {
+			return ":0\r\n"
+		}
+		ms, _ := strconv.Atoi(args[2])
+		s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return ":1\r\n"
+	case "PERSIST":
+		delete(s.expires, args[1])
+		return ":1\r\n"
+	case "PTTL":
+		if !s.alive(args[1]) {
+			return ":-2\r\n"
+		}
+		exp, ok := s.expires[args[1]]
+		if !ok {
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(exp).Milliseconds())
+	case "RENAME":
+		if !s.alive(args[1]) {
+			return "-ERR no such key\r\n"
+		}
+		from, to := args[1], args[2]
+		delete(s.data, to)
+		delete(s.sets, to)
+		delete(s.expires, to)
+		if v, ok := s.data[from]; ok {
+			s.data[to] = v
+		}
+		if set, ok := s.sets[from]; ok {
+			s.sets[to] = set
+		}
+		if exp, ok := s.expires[from]; ok {
+			s.expires[to] = exp
+		}
+		delete(s.data, from)
+		delete(s.sets, from)
+		delete(s.expires, from)
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+		for _, key := range args[1:] {
+			if s.alive(key) {
+				delete(s.data, key)
+				delete(s.sets, key)
+				delete(s.expires, key)
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SCAN":
+		// Return everything in one page; the cursor loop is covered by the
+		// client reading the "0" cursor.
+		match := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.EqualFold(args[i], "MATCH") {
+				match = args[i+1]
+			}
+		}
+		var keys []string
+		for _, all := range []map[string][]byte{s.data, s.setKeys()} {
+			for key := range all {
+				if !s.alive(key) {
+					continue
+				}
+				if ok, _ := path.Match(match, key); ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+		var b strings.Builder
+		b.WriteString("*2\r\n" + bulk("0"))
+		fmt.Fprintf(&b, "*%d\r\n", len(keys))
+		for _, key := range keys {
+			b.WriteString(bulk(key))
+		}
+		return b.String()
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+// alive reports whether key exists, dropping it if it has expired. Callers
+// must hold s.mu.
+func (s *fakeRedis) alive(key string) bool {
+	if exp, ok := s.expires[key]; ok && time.Now().After(exp) {
+		delete(s.data, key)
+		delete(s.sets, key)
+		delete(s.expires, key)
+		return false
+	}
+	_, isString := s.data[key]
+	_, isSet := s.sets[key]
+	return isString || isSet
+}
+
+// lookup returns a live string value. Callers must hold s.mu.
+func (s *fakeRedis) lookup(key string) ([]byte, bool) {
+	if !s.alive(key) {
+		return nil, false
+	}
+	v, ok := s.data[key]
+	return v, ok
+}
+
+// setKeys returns the set keys in the shape of s.data, for SCAN.
+func (s *fakeRedis) setKeys() map[string][]byte {
+	keys := make(map[string][]byte, len(s.sets))
+	for key := range s.sets {
+		keys[key] = nil
+	}
+	return keys
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+type redisTestProgram struct {
+	ID    string
+	Title string
+	Tags  []string
+}
+
+func init() {
+	Register(&redisTestProgram{}, []redisTestProgram{})
+}
+
+func newTestRedis(t *testing.T, server *fakeRedis, ttl time.Duration) *Redis {
+	t.Helper()
+
+	c, err := NewRedis(RedisOptions{
+		Addr:     server.addr(),
+		Password: server.password,
+		TTL:      ttl,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewRedis: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestRedisCache_SetAndGet(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+
+	want := &redisTestProgram{ID: "1", Title: "فنجان", Tags: []string{"culture"}}
+	c.Set("program:1", want)
+
+	value, found := c.Get("program:1")
+	if !found {
+		t.Fatal("Expected to find program:1")
+	}
+	got, ok := value.(*redisTestProgram)
+	if !ok {
+		t.Fatalf("Expected *redisTestProgram, got %T", value)
+	}
+	if got.ID != want.ID || got.Title != want.Title || len(got.Tags) != 1 {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	c.Set("programs:list", []redisTestProgram{*want})
+	value, found = c.Get("programs:list")
+	if _, ok := value.([]redisTestProgram); !found || !ok {
+		t.Errorf("Expected a []redisTestProgram, got %T", value)
+	}
+
+	if _, found := c.Get("program:2"); found {
+		t.Error("Expected program:2 to be missing")
+	}
+}
+
+func TestRedisCache_PrefixesKeys(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c := newTestRedis(t, server, time.Minute)
+
+	c.Set("key1", "value1")
+	if !server.has(DefaultRedisPrefix + "key1") {
+		t.Errorf("Expected key to be stored as %q", DefaultRedisPrefix+"key1")
+	}
+}
+
+func TestRedisCache_Delete(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+
+	c.Set("key1", "value1")
+	c.Delete("key1")
+
+	if _, found := c.Get("key1"); found {
+		t.Error("Expected key1 to be deleted")
+	}
+}
+
+func TestRedisCache_ClearKeepsForeignKeys(t *testing.T) {
+	server := newFakeRedis(t, "")
+	server.set("other-app:key", []byte("keep me"))
+	c := newTestRedis(t, server, time.Minute)
+
+	c.Set("key1", "value1")
+	c.Set("key2", "value2")
+	c.Clear()
+
+	for _, key := range []string{"key1", "key2"} {
+		if _, found := c.Get(key); found {
+			t.Errorf("Expected %s to be cleared", key)
+		}
+	}
+	if !server.has("other-app:key") {
+		t.Error("Clear removed a key outside the cache prefix")
+	}
+}
+
+func TestRedisCache_InvalidatePattern(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+
+	c.Set("programs:list:20:", "page1")
+	c.Set("programs:list:20:abc", "page2")
+	c.Set("programs:search:q:20:", "results")
+
+	c.InvalidatePattern("programs:list:*")
+
+	if _, found := c.Get("programs:list:20:"); found {
+		t.Error("Expected programs:list:20: to be invalidated")
+	}
+	if _, found := c.Get("programs:list:20:abc"); found {
+		t.Error("Expected programs:list:20:abc to be invalidated")
+	}
+	if _, found := c.Get("programs:search:q:20:"); !found {
+		t.Error("Expected programs:search:q:20: to remain")
+	}
+}
+
+func TestRedisCache_Expiration(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), 50*time.Millisecond)
+
+	c.Set("key1", "value1")
+	if _, found := c.Get("key1"); !found {
+		t.Fatal("Expected to find key1 before expiry")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, found := c.Get("key1"); found {
+		t.Error("Expected key1 to expire")
+	}
+}
+
+func TestRedisCache_UndecodableEntryIsRemoved(t *testing.T) {
+	server := newFakeRedis(t, "")
+	server.set(DefaultRedisPrefix+"key1", []byte("not gob"))
+	c := newTestRedis(t, server, time.Minute)
+
+	if _, found := c.Get("key1"); found {
+		t.Error("Expected an undecodable entry to be a miss")
+	}
+	if server.has(DefaultRedisPrefix + "key1") {
+		t.Error("Expected the undecodable entry to be deleted")
+	}
+}
+
+func TestRedisCache_UnregisteredTypeIsNotStored(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+
+	type unregistered struct{ Name string }
+	c.Set("key1", unregistered{Name: "x"})
+
+	if _, found := c.Get("key1"); found {
+		t.Error("Expected a value of an unregistered type not to be cached")
+	}
+}
+
+func TestRedisCache_Auth(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+
+	if _, err := NewRedis(RedisOptions{Addr: server.addr(), Password: "wrong"}); err == nil {
+		t.Error("Expected an error with the wrong password")
+	}
+
+	c := newTestRedis(t, server, time.Minute)
+	c.Set("key1", "value1")
+	if value, found := c.Get("key1"); !found || value != "value1" {
+		t.Errorf("Expected value1, got %v (found %v)", value, found)
+	}
+}
+
+func TestRedisCache_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if _, err := NewRedis(RedisOptions{Addr: addr, Timeout: 100 * time.Millisecond}); err == nil {
+		t.Error("Expected an error for an unreachable server")
+	}
+}
+
+func TestRedisCache_ConcurrentAccess(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key%d", i)
+			c.Set(key, i)
+			if value, found := c.Get(key); !found || value != i {
+				t.Errorf("Expected %d for %s, got %v", i, key, value)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRedisCache_KeysAndInvalidatePrefix(t *testing.T) {
+	server := newFakeRedis(t, "")
+	server.set("other-app:programs:search:x", []byte("keep me"))
+	c := newTestRedis(t, server, time.Minute)
+
+	c.Set("programs:search:b:20:", "results")
+	c.Set("programs:search:a*[:20:", "results")
+	c.Set("programs:list:20:", "page")
+
+	keys := c.Keys("programs:search:")
+	if len(keys) != 2 || keys[0].Key != "programs:search:a*[:20:" || keys[1].Key != "programs:search:b:20:" {
+		t.Fatalf("Expected both search keys in order, got %+v", keys)
+	}
+	if keys[0].Bytes <= 0 || keys[0].ExpiresAt.IsZero() {
+		t.Errorf("Expected size and expiry to be reported, got %+v", keys[0])
+	}
+
+	// Pattern characters in the prefix are literal.
+	if n := c.InvalidatePrefix("programs:search:a*"); n != 1 {
+		t.Errorf("Expected 1 key purged, got %d", n)
+	}
+	if n := c.InvalidatePrefix("programs:search:"); n != 1 {
+		t.Errorf("Expected 1 key purged, got %d", n)
+	}
+	if _, found := c.Get("programs:list:20:"); !found {
+		t.Error("Expected programs:list:20: to remain")
+	}
+	if !server.has("other-app:programs:search:x") {
+		t.Error("InvalidatePrefix removed a key outside the cache prefix")
+	}
+}
+
+func TestRedisCache_InvalidateTags(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c := newTestRedis(t, server, time.Minute)
+
+	c.SetTagged("program:1", "one", 0, "program:1")
+	c.SetTagged("programs:list:20:", "page", 0, "programs:list", "program:1", "program:2")
+	c.SetTagged("programs:category:b:20:", "page", 0, "program:2")
+
+	if !server.has("gomania:~tag:program:1") {
+		t.Fatal("Expected a set for tag program:1")
+	}
+	if keys := c.Keys(""); len(keys) != 3 {
+		t.Errorf("Expected Keys to skip tag sets, got %+v", keys)
+	}
+
+	if n := c.InvalidateTags("program:1"); n != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", n)
+	}
+	if _, found := c.Get("programs:list:20:"); found {
+		t.Error("Expected programs:list:20: to be invalidated")
+	}
+	if _, found := c.Get("programs:category:b:20:"); !found {
+		t.Error("Expected programs:category:b:20: to remain")
+	}
+	if server.has("gomania:~tag:program:1") {
+		t.Error("Expected the tag set to be removed")
+	}
+}
+
+func TestRedisCache_TagOutlivesItsEntries(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c := newTestRedis(t, server, time.Minute)
+
+	c.SetTagged("long", "value", time.Minute, "shared")
+	c.SetTagged("short", "value", 20*time.Millisecond, "shared")
+
+	time.Sleep(40 * time.Millisecond)
+
+	// The short entry must not shorten the set holding the long one.
+	if n := c.InvalidateTags("shared"); n != 1 {
+		t.Errorf("Expected the long entry to be removed, got %d", n)
+	}
+	if _, found := c.Get("long"); found {
+		t.Error("Expected long to be invalidated")
+	}
+}
+
+func TestRedisCache_ClosedIsAMiss(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+
+	c.Set("key1", "value1")
+	c.Close()
+
+	if _, found := c.Get("key1"); found {
+		t.Error("Expected a closed cache to report misses")
+	}
+}
+
+func TestRedisCache_SetDuringInvalidateTags(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c := newTestRedis(t, server, time.Minute)
+
+	c.SetTagged("early", "value", 0, "shared")
+
+	// An entry tagged while the invalidation reads the set must not lose
+	// its tag when the set is removed.
+	server.before("SMEMBERS", func() {
+		c.SetTagged("late", "value", 0, "shared")
+	})
+	if n := c.InvalidateTags("shared"); n != 1 {
+		t.Errorf("Expected the early entry to be removed, got %d", n)
+	}
+
+	if n := c.InvalidateTags("shared"); n != 1 {
+		t.Errorf("Expected the late entry to still be tagged, got %d", n)
+	}
+	if _, found := c.Get("late"); found {
+		t.Error("Expected late to be invalidated")
+	}
+}
+
+func TestRedisCache_InvalidateTagsDuringSet(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c := newTestRedis(t, server, time.Minute)
+
+	c.SetTagged("early", "value", time.Hour, "shared")
+
+	// The tag set's lifetime was read before the invalidation, so the write
+	// has to start over against the new set.
+	server.before("EXEC", func() {
+		c.InvalidateTags("shared")
+	})
+	c.SetTagged("late", "value", 0, "shared")
+
+	if _, found := c.Get("late"); !found {
+		t.Fatal("Expected late to be stored")
+	}
+	server.mu.Lock()
+	_, expires := server.expires["gomania:~tag:shared"]
+	server.mu.Unlock()
+	if !expires {
+		t.Error("Expected the new tag set to expire with its entry")
+	}
+	if n := c.InvalidateTags("shared"); n != 1 {
+		t.Errorf("Expected late to be tagged, got %d", n)
+	}
+}
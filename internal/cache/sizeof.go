@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"reflect"
+)
+
+// entryOverhead approximates the bookkeeping cost of one entry: the map slot,
+// the list element and the item struct.
+const entryOverhead = 128
+
+// maxSizeofDepth bounds how deep Sizeof follows pointers, so cyclic or very
+// deep values cannot make Set expensive.
+const maxSizeofDepth = 8
+
+// Sizeof estimates how many bytes a cached value keeps alive, including the
+// per-entry overhead. It follows pointers, slices, maps and strings, but not
+// shared memory more than once per path, so the result is an approximation.
+func Sizeof(v any) int64 {
+	if v == nil {
+		return entryOverhead
+	}
+	return entryOverhead + sizeofValue(reflect.ValueOf(v), 0)
+}
+
+func sizeofValue(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+
+	size := int64(v.Type().Size())
+	if depth >= maxSizeofDepth {
+		return size
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		size += int64(v.Len())
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			size += sizeofValue(v.Elem(), depth+1)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		elem := v.Type().Elem()
+		if hasIndirection(elem) {
+			for i := 0; i < v.Len(); i++ {
+				size += sizeofValue(v.Index(i), depth+1)
+			}
+		} else {
+			size += int64(v.Cap()) * int64(elem.Size())
+		}
+	case reflect.Array:
+		if hasIndirection(v.Type().Elem()) {
+			size -= int64(v.Type().Size())
+			for i := 0; i < v.Len(); i++ {
+				size += sizeofValue(v.Index(i), depth+1)
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			size += sizeofValue(iter.Key(), depth+1) + sizeofValue(iter.Value(), depth+1)
+		}
+	case reflect.Struct:
+		// The struct's own size is already counted; add what its fields point to.
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if hasIndirection(f.Type()) {
+				size += sizeofValue(f, depth+1) - int64(f.Type().Size())
+			}
+		}
+	}
+
+	return size
+}
+
+// hasIndirection reports whether values of t reference memory outside
+// themselves.
+func hasIndirection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return hasIndirection(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasIndirection(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ValueCodec converts values of one type to bytes and back. Typed uses it to
+// store plain bytes, which any out-of-process backend can hold without the
+// type being registered with Register.
+type ValueCodec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec is a ValueCodec using encoding/json.
+type JSONCodec[T any] struct{}
+
+// Encode marshals value as JSON
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode unmarshals a JSON document into a new T
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// TypedOptions configures a Typed cache.
+type TypedOptions[T any] struct {
+	// TTL overrides the cache's lifetime for every key written through this
+	// Typed. Zero uses the cache's own TTL.
+	TTL time.Duration
+	// Codec, when set, stores values as encoded bytes instead of as T.
+	Codec ValueCodec[T]
+	// Tags, when set, derives tags from each stored value, such as the IDs
+	// of the records a page contains. They are added to the tags passed to
+	// Set or Load.
+	Tags func(value T) []string
+}
+
+// Typed is a view of a Loader's cache holding values of a single type T, so
+// callers get a T back instead of asserting on any. Entries of another type,
+// such as ones written by an older release under the same key, are treated as
+// misses and removed.
+type Typed[T any] struct {
+	loader *Loader
+	opts   TypedOptions[T]
+}
+
+// NewTyped creates a typed view over l
+func NewTyped[T any](l *Loader, opts TypedOptions[T]) *Typed[T] {
+	return &Typed[T]{loader: l, opts: opts}
+}
+
+// Get retrieves an item from the cache
+func (t *Typed[T]) Get(key string) (T, bool) {
+	cached, found := t.loader.cache.Get(key)
+	if !found {
+		var zero T
+		return zero, false
+	}
+
+	var value any = cached
+	if e, ok := cached.(*loaded); ok {
+		value = e.Value
+	}
+	return t.decode(key, value)
+}
+
+// Set stores an item in the cache under tags
+func (t *Typed[T]) Set(key string, value T, tags ...string) {
+	t.SetWithTTL(key, value, t.opts.TTL, tags...)
+}
+
+// SetWithTTL stores an item under tags that expires after ttl, overriding
+// the default for this key only
+func (t *Typed[T]) SetWithTTL(key string, value T, ttl time.Duration, tags ...string) {
+	stored, err := t.encode(value)
+	if err != nil {
+		t.loader.opts.Logger.Error("Failed to encode cache entry", "key", key, "error", err)
+		return
+	}
+	t.loader.store(key, stored, ttl, t.tags(value, tags)...)
+}
+
+// Load returns the cached value for key, calling load on a miss and storing
+// the result under tags. It shares loads and revalidates stale values like
+// (*Loader).Load.
+func (t *Typed[T]) Load(ctx context.Context, key string, load func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	loadAny := func(ctx context.Context) (any, []string, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		stored, err := t.encode(value)
+		return stored, t.tags(value, tags), err
+	}
+
+	value, err := t.loader.load(ctx, key, t.opts.TTL, loadAny)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if v, ok := t.decode(key, value); ok {
+		return v, nil
+	}
+
+	// decode removed the mismatched entry, so this loads afresh.
+	value, err = t.loader.load(ctx, key, t.opts.TTL, loadAny)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, ok := t.decode(key, value)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("cache entry %s does not hold a %T", key, zero)
+	}
+	return v, nil
+}
+
+func (t *Typed[T]) tags(value T, static []string) []string {
+	if t.opts.Tags == nil {
+		return static
+	}
+	return append(append([]string(nil), static...), t.opts.Tags(value)...)
+}
+
+func (t *Typed[T]) encode(value T) (any, error) {
+	if t.opts.Codec == nil {
+		return value, nil
+	}
+	return t.opts.Codec.Encode(value)
+}
+
+// decode turns a stored value back into a T, removing the entry if it cannot.
+func (t *Typed[T]) decode(key string, value any) (T, bool) {
+	var zero T
+
+	if t.opts.Codec != nil {
+		if data, ok := value.([]byte); ok {
+			v, err := t.opts.Codec.Decode(data)
+			if err == nil {
+				return v, true
+			}
+			t.loader.opts.Logger.Warn("Undecodable cache entry, removing", "key", key, "error", err)
+			t.loader.cache.Delete(key)
+			return zero, false
+		}
+	} else if v, ok := value.(T); ok {
+		return v, true
+	}
+
+	t.loader.opts.Logger.Warn("Invalid cache entry type, removing", "key", key, "type", fmt.Sprintf("%T", value))
+	t.loader.cache.Delete(key)
+	return zero, false
+}
+
+// GetAs retrieves an item from c as a T. It reports a miss if the entry is
+// missing or holds another type, and understands entries written by a Loader.
+func GetAs[T any](c Cache, key string) (T, bool) {
+	var zero T
+
+	cached, found := c.Get(key)
+	if !found {
+		return zero, false
+	}
+	if e, ok := cached.(*loaded); ok {
+		cached = e.Value
+	}
+	v, ok := cached.(T)
+	if !ok {
+		return zero, false
+	}
+	return v, true
+}
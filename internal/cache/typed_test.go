@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestTyped_SetAndGet(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+	programs := NewTyped(l, TypedOptions[*redisTestProgram]{})
+
+	programs.Set("program:1", &redisTestProgram{ID: "1", Title: "Fnjan"})
+
+	got, found := programs.Get("program:1")
+	if !found || got.Title != "Fnjan" {
+		t.Errorf("Expected Fnjan, got %+v (found %v)", got, found)
+	}
+	if _, found := programs.Get("program:2"); found {
+		t.Error("Expected program:2 to be missing")
+	}
+}
+
+func TestTyped_SharesEntriesWithLoad(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+	programs := NewTyped(l, TypedOptions[string]{})
+
+	programs.Set("key", "set")
+	got, err := programs.Load(context.Background(), "key", func(ctx context.Context) (string, error) {
+		t.Error("Expected Load to use the value from Set")
+		return "loaded", nil
+	})
+	if err != nil || got != "set" {
+		t.Errorf("Expected set, got %q (%v)", got, err)
+	}
+}
+
+func TestTyped_MismatchedEntryIsRemoved(t *testing.T) {
+	l, c := newTestLoader(t, LoaderOptions{})
+	strings := NewTyped(l, TypedOptions[string]{})
+	ints := NewTyped(l, TypedOptions[int]{})
+
+	ints.Set("key", 42)
+
+	if _, found := strings.Get("key"); found {
+		t.Error("Expected an int entry to be a miss for a string view")
+	}
+	if _, found := c.Get("key"); found {
+		t.Error("Expected the mismatched entry to be removed")
+	}
+
+	got, err := strings.Load(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "reloaded", nil
+	})
+	if err != nil || got != "reloaded" {
+		t.Errorf("Expected reloaded, got %q (%v)", got, err)
+	}
+}
+
+func TestTyped_TTLOverride(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+	short := NewTyped(l, TypedOptions[string]{TTL: 20 * time.Millisecond})
+	long := NewTyped(l, TypedOptions[string]{})
+
+	short.Set("short", "value")
+	long.Set("long", "value")
+	long.SetWithTTL("per-key", "value", 20*time.Millisecond)
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, found := short.Get("short"); found {
+		t.Error("Expected the Typed TTL to expire short")
+	}
+	if _, found := long.Get("per-key"); found {
+		t.Error("Expected the per-key TTL to expire per-key")
+	}
+	if _, found := long.Get("long"); !found {
+		t.Error("Expected long to use the cache's TTL and still be present")
+	}
+}
+
+func TestTyped_LoadUsesTTLOverride(t *testing.T) {
+	l, _ := newTestLoader(t, LoaderOptions{})
+	search := NewTyped(l, TypedOptions[string]{TTL: 20 * time.Millisecond})
+
+	loads := 0
+	load := func(ctx context.Context) (string, error) {
+		loads++
+		return "results", nil
+	}
+
+	if _, err := search.Load(context.Background(), "programs:search:q", load); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := search.Load(context.Background(), "programs:search:q", load); err != nil {
+		t.Fatal(err)
+	}
+
+	if loads != 2 {
+		t.Errorf("Expected the entry to expire after the override and load twice, got %d loads", loads)
+	}
+}
+
+func TestTyped_CodecWithRedis(t *testing.T) {
+	c := newTestRedis(t, newFakeRedis(t, ""), time.Minute)
+	l := NewLoader(c, LoaderOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	// Not registered with Register: the codec turns it into bytes first.
+	type episode struct {
+		Title    string
+		Duration int
+	}
+	episodes := NewTyped(l, TypedOptions[[]episode]{Codec: JSONCodec[[]episode]{}})
+
+	episodes.Set("episodes:program:1", []episode{{Title: "One", Duration: 60}})
+
+	got, found := episodes.Get("episodes:program:1")
+	if !found || len(got) != 1 || got[0].Duration != 60 {
+		t.Errorf("Expected one 60s episode, got %+v (found %v)", got, found)
+	}
+}
+
+func TestTyped_UndecodableEntryIsRemoved(t *testing.T) {
+	l, c := newTestLoader(t, LoaderOptions{})
+	typed := NewTyped(l, TypedOptions[[]int]{Codec: JSONCodec[[]int]{}})
+
+	l.store("key", []byte("not json"), 0)
+
+	if _, found := typed.Get("key"); found {
+		t.Error("Expected an undecodable entry to be a miss")
+	}
+	if _, found := c.Get("key"); found {
+		t.Error("Expected the undecodable entry to be removed")
+	}
+}
+
+func TestTyped_Tags(t *testing.T) {
+	l, c := newTestLoader(t, LoaderOptions{})
+	pages := NewTyped(l, TypedOptions[[]string]{
+		Tags: func(ids []string) []string {
+			tags := make([]string, len(ids))
+			for i, id := range ids {
+				tags[i] = ProgramTag(id)
+			}
+			return tags
+		},
+	})
+
+	_, err := pages.Load(context.Background(), "programs:list:20:", func(ctx context.Context) ([]string, error) {
+		return []string{"1", "2"}, nil
+	}, TagProgramsList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages.Set("programs:category:a:20:", []string{"3"}, CategoryTag("a"))
+
+	if n := c.InvalidateTags(ProgramTag("2")); n != 1 {
+		t.Errorf("Expected the page holding program 2 to be removed, got %d", n)
+	}
+	if n := c.InvalidateTags(TagProgramsList, ProgramTag("3")); n != 1 {
+		t.Errorf("Expected the category page to be removed, got %d", n)
+	}
+}
+
+func TestInvalidateTags_FallsBackToClear(t *testing.T) {
+	c := &untaggedCache{Cache: New(time.Minute)}
+	defer c.Close()
+
+	SetTagged(c, "program:1", "one", 0, "program:1")
+	SetTagged(c, "categories:list", "cats", 0, TagCategories)
+
+	InvalidateTags(c)
+	if _, found := c.Get("program:1"); !found {
+		t.Error("Expected no tags to invalidate nothing")
+	}
+
+	InvalidateTags(c, "program:1")
+	if _, found := c.Get("categories:list"); found {
+		t.Error("Expected a cache without tag support to be cleared")
+	}
+}
+
+// untaggedCache hides the Tagger methods of the cache it wraps.
+type untaggedCache struct {
+	Cache
+}
+
+func TestGetAs(t *testing.T) {
+	l, c := newTestLoader(t, LoaderOptions{})
+
+	c.Set("plain", "value")
+	NewTyped(l, TypedOptions[string]{}).Set("loaded", "value")
+
+	for _, key := range []string{"plain", "loaded"} {
+		if got, found := GetAs[string](c, key); !found || got != "value" {
+			t.Errorf("%s: expected value, got %q (found %v)", key, got, found)
+		}
+	}
+	if _, found := GetAs[int](c, "plain"); found {
+		t.Error("Expected a type mismatch to be a miss")
+	}
+	if _, found := GetAs[string](c, "missing"); found {
+		t.Error("Expected a missing key to be a miss")
+	}
+}
+
+func TestSetWithTTL(t *testing.T) {
+	c := New(time.Minute)
+	defer c.Close()
+
+	SetWithTTL(c, "short", "value", 20*time.Millisecond)
+	SetWithTTL(c, "default", "value", 0)
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, found := c.Get("short"); found {
+		t.Error("Expected short to expire")
+	}
+	if _, found := c.Get("default"); !found {
+		t.Error("Expected default to use the cache's TTL")
+	}
+}
@@ -1,8 +1,19 @@
 package cache
 
-// Common cache key patterns
+import "strconv"
+
+// Tags shared by many entries. Entries are also tagged with the records they
+// were built from, see ProgramTag and CategoryTag.
 const (
-	KeyPatternProgramsSearch = "programs:search:*"
+	// TagProgramsList marks every page of the programs list
+	TagProgramsList = "programs:list"
+	// TagSearch marks every page of search results, and listings filtered or
+	// sorted by fields that change when a program is edited
+	TagSearch = "search"
+	// TagCategories marks the categories list
+	TagCategories = "categories"
+	// TagTags marks entries that count programs per tag
+	TagTags = "tags"
 )
 
 // Helper functions for common cache operations
@@ -25,19 +36,40 @@ func ProgramKey(id string) string {
 	return CacheKey("program", id)
 }
 
-// ProgramsListKey builds a cache key for programs list
-func ProgramsListKey() string {
-	return CacheKey("programs", "list")
+// ProgramsListKey builds a cache key for a page of the programs list.
+// filter is the normalized, query-escaped filter and sort, if any.
+func ProgramsListKey(limit int, cursor string, filter string) string {
+	return withFilter(CacheKey("programs", "list", strconv.Itoa(limit), cursor), filter)
+}
+
+// ProgramsSearchKey builds a cache key for a page of program search results
+func ProgramsSearchKey(query string, limit int, cursor string, filter string) string {
+	return withFilter(CacheKey("programs", "search", query, strconv.Itoa(limit), cursor), filter)
 }
 
-// ProgramsSearchKey builds a cache key for program search
-func ProgramsSearchKey(query string) string {
-	return CacheKey("programs", "search", query)
+// ProgramsFacetsKey builds a cache key for the tag counts of a search
+func ProgramsFacetsKey(query string, filter string) string {
+	return withFilter(CacheKey("programs", "facets", query), filter)
+}
+
+// withFilter appends a filter to key. Callers normalize the filter so equal
+// filters always map to the same key.
+func withFilter(key string, filter string) string {
+	if filter == "" {
+		return key
+	}
+	return CacheKey(key, filter)
 }
 
-// ProgramsCategoryKey builds a cache key for programs by category
-func ProgramsCategoryKey(categoryID string) string {
-	return CacheKey("programs", "category", categoryID)
+// ProgramsCategoryKey builds a cache key for a page of programs by category
+func ProgramsCategoryKey(categoryID string, limit int, cursor string) string {
+	return CacheKey("programs", "category", categoryID, strconv.Itoa(limit), cursor)
+}
+
+// ProgramsCategoryTreeKey builds a cache key for a page of programs in a
+// category and all of its descendants
+func ProgramsCategoryTreeKey(categoryID string, limit int, cursor string) string {
+	return CacheKey("programs", "category", categoryID, "tree", strconv.Itoa(limit), cursor)
 }
 
 // CategoryKey builds a cache key for a category
@@ -49,3 +81,34 @@ func CategoryKey(id string) string {
 func CategoriesListKey() string {
 	return CacheKey("categories", "list")
 }
+
+// TagsListKey builds a cache key for the tags list
+func TagsListKey() string {
+	return CacheKey("tags", "list")
+}
+
+// ProgramTag marks entries that include a program
+func ProgramTag(id string) string {
+	return CacheKey("program", id)
+}
+
+// CategoryTag marks entries that depend on a category or list its programs
+func CategoryTag(id string) string {
+	return CacheKey("category", id)
+}
+
+// TagFilterTag marks entries filtered by a program tag, which change when the
+// tag is attached to or detached from a program
+func TagFilterTag(name string) string {
+	return CacheKey("tag", name)
+}
+
+// EpisodeKey builds a cache key for an episode
+func EpisodeKey(id string) string {
+	return CacheKey("episode", id)
+}
+
+// ProgramEpisodesKey builds a cache key for the episodes of a program
+func ProgramEpisodesKey(programID string) string {
+	return CacheKey("episodes", "program", programID)
+}
@@ -9,20 +9,83 @@ import (
 )
 
 type Category struct {
+	ID         pgtype.UUID        `db:"id"`
+	Name       string             `db:"name"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at"`
+	SearchName pgtype.Text        `db:"search_name"`
+	ParentID   pgtype.UUID        `db:"parent_id"`
+}
+
+type Episode struct {
+	ID            pgtype.UUID        `db:"id"`
+	ProgramID     pgtype.UUID        `db:"program_id"`
+	Title         string             `db:"title"`
+	Description   pgtype.Text        `db:"description"`
+	AudioUrl      string             `db:"audio_url"`
+	Duration      pgtype.Int4        `db:"duration"`
+	SeasonNumber  pgtype.Int4        `db:"season_number"`
+	EpisodeNumber pgtype.Int4        `db:"episode_number"`
+	PublishedAt   pgtype.Timestamptz `db:"published_at"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at"`
+	TranscriptUrl pgtype.Text        `db:"transcript_url"`
+}
+
+type Program struct {
+	ID                pgtype.UUID        `db:"id"`
+	Title             string             `db:"title"`
+	Description       pgtype.Text        `db:"description"`
+	CategoryID        pgtype.UUID        `db:"category_id"`
+	Language          pgtype.Text        `db:"language"`
+	Duration          pgtype.Int4        `db:"duration"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at"`
+	SearchTitle       pgtype.Text        `db:"search_title"`
+	SearchDescription pgtype.Text        `db:"search_description"`
+	Author            pgtype.Text        `db:"author"`
+	OwnerEmail        pgtype.Text        `db:"owner_email"`
+	ImageUrl          pgtype.Text        `db:"image_url"`
+	Locked            bool               `db:"locked"`
+	ExternalUrl       pgtype.Text        `db:"external_url"`
+	Source            pgtype.Text        `db:"source"`
+	ExternalID        pgtype.Text        `db:"external_id"`
+	Status            string             `db:"status"`
+	PublishAt         pgtype.Timestamptz `db:"publish_at"`
+	DeletedAt         pgtype.Timestamptz `db:"deleted_at"`
+	Version           int32              `db:"version"`
+}
+
+type ProgramRevision struct {
+	ID           pgtype.UUID        `db:"id"`
+	ProgramID    pgtype.UUID        `db:"program_id"`
+	Revision     int32              `db:"revision"`
+	UserID       pgtype.UUID        `db:"user_id"`
+	Snapshot     []byte             `db:"snapshot"`
+	Changes      []byte             `db:"changes"`
+	RolledBackTo pgtype.Int4        `db:"rolled_back_to"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at"`
+}
+
+type ProgramTag struct {
+	ProgramID pgtype.UUID `db:"program_id"`
+	TagID     pgtype.UUID `db:"tag_id"`
+}
+
+type Role struct {
+	Name string `db:"name"`
+}
+
+type Tag struct {
 	ID        pgtype.UUID        `db:"id"`
 	Name      string             `db:"name"`
 	CreatedAt pgtype.Timestamptz `db:"created_at"`
 }
 
-type Program struct {
-	ID          pgtype.UUID        `db:"id"`
-	Title       string             `db:"title"`
-	Description pgtype.Text        `db:"description"`
-	CategoryID  pgtype.UUID        `db:"category_id"`
-	Language    pgtype.Text        `db:"language"`
-	Duration    pgtype.Int4        `db:"duration"`
-	CreatedAt   pgtype.Timestamptz `db:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `db:"updated_at"`
+type Token struct {
+	Hash      []byte             `db:"hash"`
+	UserID    pgtype.UUID        `db:"user_id"`
+	Expiry    pgtype.Timestamptz `db:"expiry"`
+	CreatedAt pgtype.Timestamptz `db:"created_at"`
 }
 
 type User struct {
@@ -30,4 +93,5 @@ type User struct {
 	Email        string             `db:"email"`
 	PasswordHash string             `db:"password_hash"`
 	CreatedAt    pgtype.Timestamptz `db:"created_at"`
+	Role         string             `db:"role"`
 }
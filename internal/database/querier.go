@@ -11,15 +11,83 @@ import (
 )
 
 type Querier interface {
-	CreateCategory(ctx context.Context, name string) (CreateCategoryRow, error)
+	AttachProgramTags(ctx context.Context, arg AttachProgramTagsParams) error
+	CountProgramsInCategory(ctx context.Context, categoryID pgtype.UUID) (int64, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (CreateCategoryRow, error)
+	CreateEpisode(ctx context.Context, arg CreateEpisodeParams) (CreateEpisodeRow, error)
 	CreateProgram(ctx context.Context, arg CreateProgramParams) (CreateProgramRow, error)
-	DeleteProgram(ctx context.Context, id pgtype.UUID) error
+	// A NULL created_at means now.
+	CreateProgramRevision(ctx context.Context, arg CreateProgramRevisionParams) (int32, error)
+	CreateTag(ctx context.Context, name string) (CreateTagRow, error)
+	CreateToken(ctx context.Context, arg CreateTokenParams) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	DeleteCategory(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteEpisode(ctx context.Context, arg DeleteEpisodeParams) (int64, error)
+	DeleteExpiredTokens(ctx context.Context) error
+	DeleteTag(ctx context.Context, id pgtype.UUID) (string, error)
+	DeleteToken(ctx context.Context, hash []byte) error
+	DeleteUser(ctx context.Context, id pgtype.UUID) (int64, error)
+	DetachProgramTag(ctx context.Context, arg DetachProgramTagParams) (int64, error)
 	GetCategories(ctx context.Context) ([]GetCategoriesRow, error)
+	GetCategory(ctx context.Context, id pgtype.UUID) (GetCategoryRow, error)
+	// Locks the programs in a category, including those in the trash, before
+	// they are moved out of it.
+	GetCategoryProgramsForUpdate(ctx context.Context, categoryID pgtype.UUID) ([]GetCategoryProgramsForUpdateRow, error)
+	GetEpisode(ctx context.Context, arg GetEpisodeParams) (GetEpisodeRow, error)
+	// Locks the program previously imported from a source, even from the trash.
+	GetImportedProgramForUpdate(ctx context.Context, arg GetImportedProgramForUpdateParams) (GetImportedProgramForUpdateRow, error)
 	GetProgram(ctx context.Context, id pgtype.UUID) (GetProgramRow, error)
-	GetProgramsByCategory(ctx context.Context, id pgtype.UUID) ([]GetProgramsByCategoryRow, error)
-	ListPrograms(ctx context.Context) ([]ListProgramsRow, error)
-	SearchPrograms(ctx context.Context, dollar_1 pgtype.Text) ([]SearchProgramsRow, error)
+	// Locks a program's row until the end of the transaction, so its revisions
+	// are numbered in order.
+	GetProgramForUpdate(ctx context.Context, id pgtype.UUID) (GetProgramForUpdateRow, error)
+	GetProgramRevision(ctx context.Context, arg GetProgramRevisionParams) (GetProgramRevisionRow, error)
+	// With include_descendants, programs in every subcategory are listed too.
+	GetProgramsByCategory(ctx context.Context, arg GetProgramsByCategoryParams) ([]GetProgramsByCategoryRow, error)
+	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
+	GetUserForToken(ctx context.Context, hash []byte) (GetUserForTokenRow, error)
+	HasProgramRevisions(ctx context.Context, programID pgtype.UUID) (bool, error)
+	// Lists the trash, most recently deleted first.
+	ListDeletedPrograms(ctx context.Context, arg ListDeletedProgramsParams) ([]ListDeletedProgramsRow, error)
+	ListEpisodesByProgram(ctx context.Context, programID pgtype.UUID) ([]ListEpisodesByProgramRow, error)
+	ListProgramRevisions(ctx context.Context, programID pgtype.UUID) ([]ListProgramRevisionsRow, error)
+	ListProgramTags(ctx context.Context, programID pgtype.UUID) ([]ListProgramTagsRow, error)
+	ListPublishedEpisodesByProgram(ctx context.Context, programID pgtype.UUID) ([]ListPublishedEpisodesByProgramRow, error)
+	// Programs in the trash are not counted.
+	ListTags(ctx context.Context) ([]ListTagsRow, error)
+	ListUsers(ctx context.Context) ([]ListUsersRow, error)
+	// NULL when no program is scheduled.
+	NextScheduledPublish(ctx context.Context) (pgtype.Timestamptz, error)
+	// Publishes every scheduled program whose publish_at has passed. Programs
+	// in the trash wait until they are restored.
+	PublishDuePrograms(ctx context.Context) ([]PublishDueProgramsRow, error)
+	// Deletes programs trashed before deleted_before for good, along with their
+	// episodes and tags.
+	PurgeDeletedPrograms(ctx context.Context, deletedBefore pgtype.Timestamptz) (int64, error)
+	// A NULL to_category_id leaves the programs without a category.
+	ReassignCategoryPrograms(ctx context.Context, arg ReassignCategoryProgramsParams) ([]pgtype.UUID, error)
+	// Moves the children of a category up to the category's own parent.
+	ReparentChildCategories(ctx context.Context, categoryID pgtype.UUID) error
+	RestoreProgram(ctx context.Context, id pgtype.UUID) (RestoreProgramRow, error)
+	SetProgramStatus(ctx context.Context, arg SetProgramStatusParams) (SetProgramStatusRow, error)
+	// Moves a program to the trash.
+	SoftDeleteProgram(ctx context.Context, id pgtype.UUID) (int64, error)
+	// Trashed programs do not keep a category from being deleted. They lose it
+	// instead.
+	UncategorizeDeletedPrograms(ctx context.Context, categoryID pgtype.UUID) error
+	// The parent only changes when set_parent is true.
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (UpdateCategoryRow, error)
+	UpdateEpisode(ctx context.Context, arg UpdateEpisodeParams) (UpdateEpisodeRow, error)
+	// Unless if_match is NULL, the program is only updated if its version is
+	// one of those listed.
 	UpdateProgram(ctx context.Context, arg UpdateProgramParams) (UpdateProgramRow, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error)
+	// The no-op update makes RETURNING yield the id of an existing category too.
+	UpsertCategoryByName(ctx context.Context, name string) (pgtype.UUID, error)
+	// Imported programs are published straight away. A re-import keeps the
+	// program's status, and takes it out of the trash.
+	UpsertImportedProgram(ctx context.Context, arg UpsertImportedProgramParams) (UpsertImportedProgramRow, error)
+	// The no-op update makes RETURNING yield the ids of existing tags too.
+	UpsertTags(ctx context.Context, names []string) ([]pgtype.UUID, error)
 }
 
 var _ Querier = (*Queries)(nil)
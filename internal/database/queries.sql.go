@@ -11,44 +11,73 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countProgramsInCategory = `-- name: CountProgramsInCategory :one
+SELECT count(*) FROM programs WHERE category_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountProgramsInCategory(ctx context.Context, categoryID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countProgramsInCategory, categoryID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createCategory = `-- name: CreateCategory :one
-INSERT INTO categories (name)
-VALUES ($1)
-RETURNING id, name
+INSERT INTO categories (name, parent_id)
+VALUES ($1, $2)
+RETURNING id, name, parent_id
 `
 
+type CreateCategoryParams struct {
+	Name     string      `db:"name"`
+	ParentID pgtype.UUID `db:"parent_id"`
+}
+
 type CreateCategoryRow struct {
-	ID   pgtype.UUID `db:"id"`
-	Name string      `db:"name"`
+	ID       pgtype.UUID `db:"id"`
+	Name     string      `db:"name"`
+	ParentID pgtype.UUID `db:"parent_id"`
 }
 
-func (q *Queries) CreateCategory(ctx context.Context, name string) (CreateCategoryRow, error) {
-	row := q.db.QueryRow(ctx, createCategory, name)
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (CreateCategoryRow, error) {
+	row := q.db.QueryRow(ctx, createCategory, arg.Name, arg.ParentID)
 	var i CreateCategoryRow
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(&i.ID, &i.Name, &i.ParentID)
 	return i, err
 }
 
 const createProgram = `-- name: CreateProgram :one
-INSERT INTO programs (title, description, category_id, language, duration)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, title, description, language, duration
+INSERT INTO programs (title, description, category_id, language, duration, author, owner_email, image_url, locked, status, publish_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, title, description, language, duration, author, owner_email, image_url, locked, status, publish_at
 `
 
 type CreateProgramParams struct {
-	Title       string      `db:"title"`
-	Description pgtype.Text `db:"description"`
-	CategoryID  pgtype.UUID `db:"category_id"`
-	Language    pgtype.Text `db:"language"`
-	Duration    pgtype.Int4 `db:"duration"`
+	Title       string             `db:"title"`
+	Description pgtype.Text        `db:"description"`
+	CategoryID  pgtype.UUID        `db:"category_id"`
+	Language    pgtype.Text        `db:"language"`
+	Duration    pgtype.Int4        `db:"duration"`
+	Author      pgtype.Text        `db:"author"`
+	OwnerEmail  pgtype.Text        `db:"owner_email"`
+	ImageUrl    pgtype.Text        `db:"image_url"`
+	Locked      bool               `db:"locked"`
+	Status      string             `db:"status"`
+	PublishAt   pgtype.Timestamptz `db:"publish_at"`
 }
 
 type CreateProgramRow struct {
-	ID          pgtype.UUID `db:"id"`
-	Title       string      `db:"title"`
-	Description pgtype.Text `db:"description"`
-	Language    pgtype.Text `db:"language"`
-	Duration    pgtype.Int4 `db:"duration"`
+	ID          pgtype.UUID        `db:"id"`
+	Title       string             `db:"title"`
+	Description pgtype.Text        `db:"description"`
+	Language    pgtype.Text        `db:"language"`
+	Duration    pgtype.Int4        `db:"duration"`
+	Author      pgtype.Text        `db:"author"`
+	OwnerEmail  pgtype.Text        `db:"owner_email"`
+	ImageUrl    pgtype.Text        `db:"image_url"`
+	Locked      bool               `db:"locked"`
+	Status      string             `db:"status"`
+	PublishAt   pgtype.Timestamptz `db:"publish_at"`
 }
 
 func (q *Queries) CreateProgram(ctx context.Context, arg CreateProgramParams) (CreateProgramRow, error) {
@@ -58,6 +87,12 @@ func (q *Queries) CreateProgram(ctx context.Context, arg CreateProgramParams) (C
 		arg.CategoryID,
 		arg.Language,
 		arg.Duration,
+		arg.Author,
+		arg.OwnerEmail,
+		arg.ImageUrl,
+		arg.Locked,
+		arg.Status,
+		arg.PublishAt,
 	)
 	var i CreateProgramRow
 	err := row.Scan(
@@ -66,28 +101,38 @@ func (q *Queries) CreateProgram(ctx context.Context, arg CreateProgramParams) (C
 		&i.Description,
 		&i.Language,
 		&i.Duration,
+		&i.Author,
+		&i.OwnerEmail,
+		&i.ImageUrl,
+		&i.Locked,
+		&i.Status,
+		&i.PublishAt,
 	)
 	return i, err
 }
 
-const deleteProgram = `-- name: DeleteProgram :exec
-DELETE FROM programs WHERE id = $1
+const deleteCategory = `-- name: DeleteCategory :execrows
+DELETE FROM categories WHERE id = $1
 `
 
-func (q *Queries) DeleteProgram(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteProgram, id)
-	return err
+func (q *Queries) DeleteCategory(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCategory, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getCategories = `-- name: GetCategories :many
-SELECT id, name
+SELECT id, name, parent_id
 FROM categories
 ORDER BY name
 `
 
 type GetCategoriesRow struct {
-	ID   pgtype.UUID `db:"id"`
-	Name string      `db:"name"`
+	ID       pgtype.UUID `db:"id"`
+	Name     string      `db:"name"`
+	ParentID pgtype.UUID `db:"parent_id"`
 }
 
 func (q *Queries) GetCategories(ctx context.Context) ([]GetCategoriesRow, error) {
@@ -99,7 +144,7 @@ func (q *Queries) GetCategories(ctx context.Context) ([]GetCategoriesRow, error)
 	var items []GetCategoriesRow
 	for rows.Next() {
 		var i GetCategoriesRow
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		if err := rows.Scan(&i.ID, &i.Name, &i.ParentID); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -110,6 +155,25 @@ func (q *Queries) GetCategories(ctx context.Context) ([]GetCategoriesRow, error)
 	return items, nil
 }
 
+const getCategory = `-- name: GetCategory :one
+SELECT id, name, parent_id
+FROM categories
+WHERE id = $1
+`
+
+type GetCategoryRow struct {
+	ID       pgtype.UUID `db:"id"`
+	Name     string      `db:"name"`
+	ParentID pgtype.UUID `db:"parent_id"`
+}
+
+func (q *Queries) GetCategory(ctx context.Context, id pgtype.UUID) (GetCategoryRow, error) {
+	row := q.db.QueryRow(ctx, getCategory, id)
+	var i GetCategoryRow
+	err := row.Scan(&i.ID, &i.Name, &i.ParentID)
+	return i, err
+}
+
 const getProgram = `-- name: GetProgram :one
 SELECT
     p.id,
@@ -118,20 +182,40 @@ SELECT
     p.language,
     p.duration,
     p.category_id,
-    c.name as category_name
+    c.name as category_name,
+    p.author,
+    p.owner_email,
+    p.image_url,
+    p.locked,
+    p.external_url,
+    p.source,
+    p.external_id,
+    p.status,
+    p.publish_at,
+    p.version
 FROM programs p
 LEFT JOIN categories c ON p.category_id = c.id
-WHERE p.id = $1
+WHERE p.id = $1 AND p.deleted_at IS NULL
 `
 
 type GetProgramRow struct {
-	ID           pgtype.UUID `db:"id"`
-	Title        string      `db:"title"`
-	Description  pgtype.Text `db:"description"`
-	Language     pgtype.Text `db:"language"`
-	Duration     pgtype.Int4 `db:"duration"`
-	CategoryID   pgtype.UUID `db:"category_id"`
-	CategoryName pgtype.Text `db:"category_name"`
+	ID           pgtype.UUID        `db:"id"`
+	Title        string             `db:"title"`
+	Description  pgtype.Text        `db:"description"`
+	Language     pgtype.Text        `db:"language"`
+	Duration     pgtype.Int4        `db:"duration"`
+	CategoryID   pgtype.UUID        `db:"category_id"`
+	CategoryName pgtype.Text        `db:"category_name"`
+	Author       pgtype.Text        `db:"author"`
+	OwnerEmail   pgtype.Text        `db:"owner_email"`
+	ImageUrl     pgtype.Text        `db:"image_url"`
+	Locked       bool               `db:"locked"`
+	ExternalUrl  pgtype.Text        `db:"external_url"`
+	Source       pgtype.Text        `db:"source"`
+	ExternalID   pgtype.Text        `db:"external_id"`
+	Status       string             `db:"status"`
+	PublishAt    pgtype.Timestamptz `db:"publish_at"`
+	Version      int32              `db:"version"`
 }
 
 func (q *Queries) GetProgram(ctx context.Context, id pgtype.UUID) (GetProgramRow, error) {
@@ -145,11 +229,29 @@ func (q *Queries) GetProgram(ctx context.Context, id pgtype.UUID) (GetProgramRow
 		&i.Duration,
 		&i.CategoryID,
 		&i.CategoryName,
+		&i.Author,
+		&i.OwnerEmail,
+		&i.ImageUrl,
+		&i.Locked,
+		&i.ExternalUrl,
+		&i.Source,
+		&i.ExternalID,
+		&i.Status,
+		&i.PublishAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getProgramsByCategory = `-- name: GetProgramsByCategory :many
+WITH RECURSIVE tree AS (
+    SELECT id FROM categories WHERE id = $1
+    UNION
+    SELECT sub.id
+    FROM categories sub
+    JOIN tree t ON sub.parent_id = t.id
+    WHERE $2::boolean
+)
 SELECT
     p.id,
     p.title,
@@ -157,25 +259,46 @@ SELECT
     p.language,
     p.duration,
     p.category_id,
-    c.name as category_name
+    c.name as category_name,
+    p.created_at
 FROM programs p
 JOIN categories c ON p.category_id = c.id
-WHERE c.id = $1
-ORDER BY p.created_at DESC
+WHERE p.category_id IN (SELECT id FROM tree)
+  AND p.deleted_at IS NULL
+  AND ($3::timestamptz IS NULL
+   OR (p.created_at, p.id) < ($3::timestamptz, $4::uuid))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $5
 `
 
+type GetProgramsByCategoryParams struct {
+	CategoryID         pgtype.UUID        `db:"category_id"`
+	IncludeDescendants bool               `db:"include_descendants"`
+	CursorCreatedAt    pgtype.Timestamptz `db:"cursor_created_at"`
+	CursorID           pgtype.UUID        `db:"cursor_id"`
+	PageLimit          int32              `db:"page_limit"`
+}
+
 type GetProgramsByCategoryRow struct {
-	ID           pgtype.UUID `db:"id"`
-	Title        string      `db:"title"`
-	Description  pgtype.Text `db:"description"`
-	Language     pgtype.Text `db:"language"`
-	Duration     pgtype.Int4 `db:"duration"`
-	CategoryID   pgtype.UUID `db:"category_id"`
-	CategoryName string      `db:"category_name"`
+	ID           pgtype.UUID        `db:"id"`
+	Title        string             `db:"title"`
+	Description  pgtype.Text        `db:"description"`
+	Language     pgtype.Text        `db:"language"`
+	Duration     pgtype.Int4        `db:"duration"`
+	CategoryID   pgtype.UUID        `db:"category_id"`
+	CategoryName string             `db:"category_name"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at"`
 }
 
-func (q *Queries) GetProgramsByCategory(ctx context.Context, id pgtype.UUID) ([]GetProgramsByCategoryRow, error) {
-	rows, err := q.db.Query(ctx, getProgramsByCategory, id)
+// With include_descendants, programs in every subcategory are listed too.
+func (q *Queries) GetProgramsByCategory(ctx context.Context, arg GetProgramsByCategoryParams) ([]GetProgramsByCategoryRow, error) {
+	rows, err := q.db.Query(ctx, getProgramsByCategory,
+		arg.CategoryID,
+		arg.IncludeDescendants,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +314,7 @@ func (q *Queries) GetProgramsByCategory(ctx context.Context, id pgtype.UUID) ([]
 			&i.Duration,
 			&i.CategoryID,
 			&i.CategoryName,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -202,47 +326,55 @@ func (q *Queries) GetProgramsByCategory(ctx context.Context, id pgtype.UUID) ([]
 	return items, nil
 }
 
-const listPrograms = `-- name: ListPrograms :many
+const listDeletedPrograms = `-- name: ListDeletedPrograms :many
 SELECT
     p.id,
     p.title,
-    p.description,
-    p.language,
-    p.duration,
     p.category_id,
-    c.name as category_name
+    c.name as category_name,
+    p.status,
+    p.deleted_at
 FROM programs p
 LEFT JOIN categories c ON p.category_id = c.id
-ORDER BY p.created_at DESC
+WHERE p.deleted_at IS NOT NULL
+  AND ($1::timestamptz IS NULL
+   OR (p.deleted_at, p.id) < ($1::timestamptz, $2::uuid))
+ORDER BY p.deleted_at DESC, p.id DESC
+LIMIT $3
 `
 
-type ListProgramsRow struct {
-	ID           pgtype.UUID `db:"id"`
-	Title        string      `db:"title"`
-	Description  pgtype.Text `db:"description"`
-	Language     pgtype.Text `db:"language"`
-	Duration     pgtype.Int4 `db:"duration"`
-	CategoryID   pgtype.UUID `db:"category_id"`
-	CategoryName pgtype.Text `db:"category_name"`
+type ListDeletedProgramsParams struct {
+	CursorDeletedAt pgtype.Timestamptz `db:"cursor_deleted_at"`
+	CursorID        pgtype.UUID        `db:"cursor_id"`
+	PageLimit       int32              `db:"page_limit"`
+}
+
+type ListDeletedProgramsRow struct {
+	ID           pgtype.UUID        `db:"id"`
+	Title        string             `db:"title"`
+	CategoryID   pgtype.UUID        `db:"category_id"`
+	CategoryName pgtype.Text        `db:"category_name"`
+	Status       string             `db:"status"`
+	DeletedAt    pgtype.Timestamptz `db:"deleted_at"`
 }
 
-func (q *Queries) ListPrograms(ctx context.Context) ([]ListProgramsRow, error) {
-	rows, err := q.db.Query(ctx, listPrograms)
+// Lists the trash, most recently deleted first.
+func (q *Queries) ListDeletedPrograms(ctx context.Context, arg ListDeletedProgramsParams) ([]ListDeletedProgramsRow, error) {
+	rows, err := q.db.Query(ctx, listDeletedPrograms, arg.CursorDeletedAt, arg.CursorID, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListProgramsRow
+	var items []ListDeletedProgramsRow
 	for rows.Next() {
-		var i ListProgramsRow
+		var i ListDeletedProgramsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
-			&i.Description,
-			&i.Language,
-			&i.Duration,
 			&i.CategoryID,
 			&i.CategoryName,
+			&i.Status,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -254,51 +386,44 @@ func (q *Queries) ListPrograms(ctx context.Context) ([]ListProgramsRow, error) {
 	return items, nil
 }
 
-const searchPrograms = `-- name: SearchPrograms :many
-SELECT
-    p.id,
-    p.title,
-    p.description,
-    p.language,
-    p.duration,
-    p.category_id,
-    c.name as category_name
-FROM programs p
-LEFT JOIN categories c ON p.category_id = c.id
-WHERE p.title ILIKE '%' || $1 || '%'
-   OR p.description ILIKE '%' || $1 || '%'
-   OR c.name ILIKE '%' || $1 || '%'
-ORDER BY p.created_at DESC
+const nextScheduledPublish = `-- name: NextScheduledPublish :one
+SELECT min(publish_at)::timestamptz AS publish_at
+FROM programs
+WHERE status = 'scheduled' AND deleted_at IS NULL
 `
 
-type SearchProgramsRow struct {
-	ID           pgtype.UUID `db:"id"`
-	Title        string      `db:"title"`
-	Description  pgtype.Text `db:"description"`
-	Language     pgtype.Text `db:"language"`
-	Duration     pgtype.Int4 `db:"duration"`
-	CategoryID   pgtype.UUID `db:"category_id"`
-	CategoryName pgtype.Text `db:"category_name"`
+// NULL when no program is scheduled.
+func (q *Queries) NextScheduledPublish(ctx context.Context) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, nextScheduledPublish)
+	var publish_at pgtype.Timestamptz
+	err := row.Scan(&publish_at)
+	return publish_at, err
 }
 
-func (q *Queries) SearchPrograms(ctx context.Context, dollar_1 pgtype.Text) ([]SearchProgramsRow, error) {
-	rows, err := q.db.Query(ctx, searchPrograms, dollar_1)
+const publishDuePrograms = `-- name: PublishDuePrograms :many
+UPDATE programs
+SET status = 'published', updated_at = CURRENT_TIMESTAMP
+WHERE status = 'scheduled' AND publish_at <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+RETURNING id, category_id
+`
+
+type PublishDueProgramsRow struct {
+	ID         pgtype.UUID `db:"id"`
+	CategoryID pgtype.UUID `db:"category_id"`
+}
+
+// Publishes every scheduled program whose publish_at has passed. Programs
+// in the trash wait until they are restored.
+func (q *Queries) PublishDuePrograms(ctx context.Context) ([]PublishDueProgramsRow, error) {
+	rows, err := q.db.Query(ctx, publishDuePrograms)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SearchProgramsRow
+	var items []PublishDueProgramsRow
 	for rows.Next() {
-		var i SearchProgramsRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Description,
-			&i.Language,
-			&i.Duration,
-			&i.CategoryID,
-			&i.CategoryName,
-		); err != nil {
+		var i PublishDueProgramsRow
+		if err := rows.Scan(&i.ID, &i.CategoryID); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -309,26 +434,213 @@ func (q *Queries) SearchPrograms(ctx context.Context, dollar_1 pgtype.Text) ([]S
 	return items, nil
 }
 
+const purgeDeletedPrograms = `-- name: PurgeDeletedPrograms :execrows
+DELETE FROM programs
+WHERE deleted_at < $1
+`
+
+// Deletes programs trashed before deleted_before for good, along with their
+// episodes and tags.
+func (q *Queries) PurgeDeletedPrograms(ctx context.Context, deletedBefore pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedPrograms, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reassignCategoryPrograms = `-- name: ReassignCategoryPrograms :many
+UPDATE programs
+SET
+    category_id = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE category_id = $2
+RETURNING id
+`
+
+type ReassignCategoryProgramsParams struct {
+	ToCategoryID   pgtype.UUID `db:"to_category_id"`
+	FromCategoryID pgtype.UUID `db:"from_category_id"`
+}
+
+// A NULL to_category_id leaves the programs without a category.
+func (q *Queries) ReassignCategoryPrograms(ctx context.Context, arg ReassignCategoryProgramsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, reassignCategoryPrograms, arg.ToCategoryID, arg.FromCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reparentChildCategories = `-- name: ReparentChildCategories :exec
+UPDATE categories
+SET parent_id = (SELECT parent.parent_id FROM categories parent WHERE parent.id = $1)
+WHERE parent_id = $1
+`
+
+// Moves the children of a category up to the category's own parent.
+func (q *Queries) ReparentChildCategories(ctx context.Context, categoryID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, reparentChildCategories, categoryID)
+	return err
+}
+
+const restoreProgram = `-- name: RestoreProgram :one
+UPDATE programs
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, category_id
+`
+
+type RestoreProgramRow struct {
+	ID         pgtype.UUID `db:"id"`
+	CategoryID pgtype.UUID `db:"category_id"`
+}
+
+func (q *Queries) RestoreProgram(ctx context.Context, id pgtype.UUID) (RestoreProgramRow, error) {
+	row := q.db.QueryRow(ctx, restoreProgram, id)
+	var i RestoreProgramRow
+	err := row.Scan(&i.ID, &i.CategoryID)
+	return i, err
+}
+
+const setProgramStatus = `-- name: SetProgramStatus :one
+UPDATE programs
+SET status = $2, publish_at = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, category_id, status, publish_at
+`
+
+type SetProgramStatusParams struct {
+	ID        pgtype.UUID        `db:"id"`
+	Status    string             `db:"status"`
+	PublishAt pgtype.Timestamptz `db:"publish_at"`
+}
+
+type SetProgramStatusRow struct {
+	ID         pgtype.UUID        `db:"id"`
+	CategoryID pgtype.UUID        `db:"category_id"`
+	Status     string             `db:"status"`
+	PublishAt  pgtype.Timestamptz `db:"publish_at"`
+}
+
+func (q *Queries) SetProgramStatus(ctx context.Context, arg SetProgramStatusParams) (SetProgramStatusRow, error) {
+	row := q.db.QueryRow(ctx, setProgramStatus, arg.ID, arg.Status, arg.PublishAt)
+	var i SetProgramStatusRow
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Status,
+		&i.PublishAt,
+	)
+	return i, err
+}
+
+const softDeleteProgram = `-- name: SoftDeleteProgram :execrows
+UPDATE programs
+SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+// Moves a program to the trash.
+func (q *Queries) SoftDeleteProgram(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteProgram, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const uncategorizeDeletedPrograms = `-- name: UncategorizeDeletedPrograms :exec
+UPDATE programs
+SET category_id = NULL
+WHERE category_id = $1 AND deleted_at IS NOT NULL
+`
+
+// Trashed programs do not keep a category from being deleted. They lose it
+// instead.
+func (q *Queries) UncategorizeDeletedPrograms(ctx context.Context, categoryID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, uncategorizeDeletedPrograms, categoryID)
+	return err
+}
+
+const updateCategory = `-- name: UpdateCategory :one
+UPDATE categories
+SET
+    name = $1,
+    parent_id = CASE WHEN $2::bool THEN $3::uuid ELSE parent_id END
+WHERE id = $4
+RETURNING id, name, parent_id
+`
+
+type UpdateCategoryParams struct {
+	Name      string      `db:"name"`
+	SetParent bool        `db:"set_parent"`
+	ParentID  pgtype.UUID `db:"parent_id"`
+	ID        pgtype.UUID `db:"id"`
+}
+
+type UpdateCategoryRow struct {
+	ID       pgtype.UUID `db:"id"`
+	Name     string      `db:"name"`
+	ParentID pgtype.UUID `db:"parent_id"`
+}
+
+// The parent only changes when set_parent is true.
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (UpdateCategoryRow, error) {
+	row := q.db.QueryRow(ctx, updateCategory,
+		arg.Name,
+		arg.SetParent,
+		arg.ParentID,
+		arg.ID,
+	)
+	var i UpdateCategoryRow
+	err := row.Scan(&i.ID, &i.Name, &i.ParentID)
+	return i, err
+}
+
 const updateProgram = `-- name: UpdateProgram :one
 UPDATE programs
 SET
-    title = $2,
-    description = $3,
-    category_id = $4,
-    language = $5,
-    duration = $6,
+    title = $1,
+    description = $2,
+    category_id = $3,
+    language = $4,
+    duration = $5,
+    author = $6,
+    owner_email = $7,
+    image_url = $8,
+    locked = $9,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, title, description, language, duration
+WHERE id = $10
+  AND deleted_at IS NULL
+  AND ($11::int[] IS NULL OR version = ANY($11::int[]))
+RETURNING id, title, description, language, duration, author, owner_email, image_url, locked, version
 `
 
 type UpdateProgramParams struct {
-	ID          pgtype.UUID `db:"id"`
 	Title       string      `db:"title"`
 	Description pgtype.Text `db:"description"`
 	CategoryID  pgtype.UUID `db:"category_id"`
 	Language    pgtype.Text `db:"language"`
 	Duration    pgtype.Int4 `db:"duration"`
+	Author      pgtype.Text `db:"author"`
+	OwnerEmail  pgtype.Text `db:"owner_email"`
+	ImageUrl    pgtype.Text `db:"image_url"`
+	Locked      bool        `db:"locked"`
+	ID          pgtype.UUID `db:"id"`
+	IfMatch     []int32     `db:"if_match"`
 }
 
 type UpdateProgramRow struct {
@@ -337,16 +649,28 @@ type UpdateProgramRow struct {
 	Description pgtype.Text `db:"description"`
 	Language    pgtype.Text `db:"language"`
 	Duration    pgtype.Int4 `db:"duration"`
+	Author      pgtype.Text `db:"author"`
+	OwnerEmail  pgtype.Text `db:"owner_email"`
+	ImageUrl    pgtype.Text `db:"image_url"`
+	Locked      bool        `db:"locked"`
+	Version     int32       `db:"version"`
 }
 
+// Unless if_match is NULL, the program is only updated if its version is
+// one of those listed.
 func (q *Queries) UpdateProgram(ctx context.Context, arg UpdateProgramParams) (UpdateProgramRow, error) {
 	row := q.db.QueryRow(ctx, updateProgram,
-		arg.ID,
 		arg.Title,
 		arg.Description,
 		arg.CategoryID,
 		arg.Language,
 		arg.Duration,
+		arg.Author,
+		arg.OwnerEmail,
+		arg.ImageUrl,
+		arg.Locked,
+		arg.ID,
+		arg.IfMatch,
 	)
 	var i UpdateProgramRow
 	err := row.Scan(
@@ -355,6 +679,11 @@ func (q *Queries) UpdateProgram(ctx context.Context, arg UpdateProgramParams) (U
 		&i.Description,
 		&i.Language,
 		&i.Duration,
+		&i.Author,
+		&i.OwnerEmail,
+		&i.ImageUrl,
+		&i.Locked,
+		&i.Version,
 	)
 	return i, err
 }
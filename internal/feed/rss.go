@@ -0,0 +1,225 @@
+// Package feed renders programs as RSS 2.0 podcast feeds with the iTunes and
+// Podcasting 2.0 namespaces, so they can be submitted to podcast directories.
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+const (
+	namespaceITunes  = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	namespacePodcast = "https://podcastindex.org/namespace/1.0"
+	namespaceAtom    = "http://www.w3.org/2005/Atom"
+)
+
+// podcastGUIDNamespace is the UUIDv5 namespace defined by the Podcasting 2.0
+// spec for deriving podcast:guid from a feed URL.
+var podcastGUIDNamespace = uuid.MustParse("ead4c236-bf58-58c6-a2c6-a6b28d128cb6")
+
+type RSS struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	ITunesNS  string   `xml:"xmlns:itunes,attr"`
+	PodcastNS string   `xml:"xmlns:podcast,attr"`
+	AtomNS    string   `xml:"xmlns:atom,attr"`
+	Channel   Channel  `xml:"channel"`
+}
+
+type Channel struct {
+	Title          string          `xml:"title"`
+	Link           string          `xml:"link"`
+	AtomLink       AtomLink        `xml:"atom:link"`
+	Description    string          `xml:"description"`
+	Language       string          `xml:"language,omitempty"`
+	Generator      string          `xml:"generator"`
+	ITunesAuthor   string          `xml:"itunes:author,omitempty"`
+	ITunesOwner    *ITunesOwner    `xml:"itunes:owner,omitempty"`
+	ITunesImage    *ITunesImage    `xml:"itunes:image,omitempty"`
+	ITunesCategory *ITunesCategory `xml:"itunes:category,omitempty"`
+	ITunesExplicit string          `xml:"itunes:explicit"`
+	PodcastGUID    string          `xml:"podcast:guid"`
+	PodcastLocked  PodcastLocked   `xml:"podcast:locked"`
+	Items          []Item          `xml:"item"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type ITunesOwner struct {
+	Name  string `xml:"itunes:name,omitempty"`
+	Email string `xml:"itunes:email"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type ITunesCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type PodcastLocked struct {
+	Owner string `xml:"owner,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type Item struct {
+	Title             string             `xml:"title"`
+	Description       string             `xml:"description,omitempty"`
+	GUID              GUID               `xml:"guid"`
+	PubDate           string             `xml:"pubDate"`
+	Enclosure         Enclosure          `xml:"enclosure"`
+	ITunesDuration    string             `xml:"itunes:duration,omitempty"`
+	ITunesSeason      string             `xml:"itunes:season,omitempty"`
+	ITunesEpisode     string             `xml:"itunes:episode,omitempty"`
+	PodcastTranscript *PodcastTranscript `xml:"podcast:transcript,omitempty"`
+}
+
+type GUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// Enclosure points at the episode audio. We do not store file sizes, so the
+// length is 0, which directories accept as "unknown".
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type PodcastTranscript struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// New builds the feed for a program and its published episodes. feedURL is the
+// public URL the feed is served from; it seeds podcast:guid, so it must stay
+// stable for the lifetime of the program.
+func New(program *database.GetProgramRow, episodes []database.ListPublishedEpisodesByProgramRow, feedURL string) *RSS {
+	ch := Channel{
+		Title:          program.Title,
+		Link:           feedURL,
+		AtomLink:       AtomLink{Href: feedURL, Rel: "self", Type: "application/rss+xml"},
+		Description:    program.Title,
+		Language:       program.Language.String,
+		Generator:      "Gomania",
+		ITunesAuthor:   program.Author.String,
+		ITunesExplicit: "false",
+		PodcastGUID:    PodcastGUID(feedURL),
+		PodcastLocked:  PodcastLocked{Owner: program.OwnerEmail.String, Value: "no"},
+		Items:          make([]Item, 0, len(episodes)),
+	}
+
+	if program.Description.Valid && program.Description.String != "" {
+		ch.Description = program.Description.String
+	}
+	if program.OwnerEmail.Valid {
+		ch.ITunesOwner = &ITunesOwner{Name: program.Author.String, Email: program.OwnerEmail.String}
+	}
+	if program.ImageUrl.Valid {
+		ch.ITunesImage = &ITunesImage{Href: program.ImageUrl.String}
+	}
+	if program.CategoryName.Valid {
+		ch.ITunesCategory = &ITunesCategory{Text: program.CategoryName.String}
+	}
+	if program.Locked {
+		ch.PodcastLocked.Value = "yes"
+	}
+
+	for _, e := range episodes {
+		item := Item{
+			Title:       e.Title,
+			Description: e.Description.String,
+			GUID:        GUID{Value: uuid.UUID(e.ID.Bytes).String()},
+			PubDate:     e.PublishedAt.Time.UTC().Format(time.RFC1123Z),
+			Enclosure:   Enclosure{URL: e.AudioUrl, Type: mediaType(e.AudioUrl, "audio/mpeg")},
+		}
+		if e.Duration.Valid {
+			item.ITunesDuration = strconv.Itoa(int(e.Duration.Int32))
+		}
+		if e.SeasonNumber.Valid {
+			item.ITunesSeason = strconv.Itoa(int(e.SeasonNumber.Int32))
+		}
+		if e.EpisodeNumber.Valid {
+			item.ITunesEpisode = strconv.Itoa(int(e.EpisodeNumber.Int32))
+		}
+		if e.TranscriptUrl.Valid {
+			item.PodcastTranscript = &PodcastTranscript{
+				URL:  e.TranscriptUrl.String,
+				Type: mediaType(e.TranscriptUrl.String, "text/plain"),
+			}
+		}
+		ch.Items = append(ch.Items, item)
+	}
+
+	return &RSS{
+		Version:   "2.0",
+		ITunesNS:  namespaceITunes,
+		PodcastNS: namespacePodcast,
+		AtomNS:    namespaceAtom,
+		Channel:   ch,
+	}
+}
+
+// Write encodes the feed as an indented XML document.
+func (f *RSS) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// PodcastGUID derives a podcast:guid from a feed URL as described by the
+// Podcasting 2.0 spec: the scheme and trailing slashes are dropped before
+// hashing.
+func PodcastGUID(feedURL string) string {
+	u := feedURL
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	}
+	u = strings.TrimRight(u, "/")
+	return uuid.NewSHA1(podcastGUIDNamespace, []byte(u)).String()
+}
+
+// mediaType guesses a MIME type from the URL's file extension. The common
+// podcast formats are listed explicitly because the system MIME table may not
+// know them.
+func mediaType(rawURL, fallback string) string {
+	ext := strings.ToLower(path.Ext(strings.SplitN(rawURL, "?", 2)[0]))
+	switch ext {
+	case ".mp3":
+		return "audio/mpeg"
+	case ".m4a":
+		return "audio/x-m4a"
+	case ".ogg", ".oga":
+		return "audio/ogg"
+	case ".opus":
+		return "audio/opus"
+	case ".vtt":
+		return "text/vtt"
+	case ".srt":
+		return "application/x-subrip"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return strings.SplitN(t, ";", 2)[0]
+	}
+	return fallback
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+func TestPodcastGUID(t *testing.T) {
+	// Example from the Podcasting 2.0 namespace documentation.
+	want := "917393e3-1b1e-5cef-ace4-edaa54e1f810"
+	for _, u := range []string{
+		"https://mp3s.nashownotes.com/pc20rss.xml",
+		"http://mp3s.nashownotes.com/pc20rss.xml/",
+		"mp3s.nashownotes.com/pc20rss.xml",
+	} {
+		if got := PodcastGUID(u); got != want {
+			t.Errorf("PodcastGUID(%q) = %s, want %s", u, got, want)
+		}
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	tests := map[string]string{
+		"https://cdn.example.com/ep1.mp3":           "audio/mpeg",
+		"https://cdn.example.com/ep1.M4A?token=abc": "audio/x-m4a",
+		"https://cdn.example.com/ep1.vtt":           "text/vtt",
+		"https://cdn.example.com/stream":            "fallback/type",
+	}
+	for u, want := range tests {
+		if got := mediaType(u, "fallback/type"); got != want {
+			t.Errorf("mediaType(%q) = %s, want %s", u, got, want)
+		}
+	}
+}
+
+func TestNew_Write(t *testing.T) {
+	program := &database.GetProgramRow{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Title:        "تقنية بودكاست",
+		Description:  pgtype.Text{String: "برنامج أسبوعي", Valid: true},
+		Language:     pgtype.Text{String: "ar", Valid: true},
+		CategoryName: pgtype.Text{String: "Technology", Valid: true},
+		Author:       pgtype.Text{String: "Gomania", Valid: true},
+		OwnerEmail:   pgtype.Text{String: "owner@example.com", Valid: true},
+		ImageUrl:     pgtype.Text{String: "https://cdn.example.com/cover.jpg", Valid: true},
+		Locked:       true,
+	}
+	episodeID := uuid.New()
+	episodes := []database.ListPublishedEpisodesByProgramRow{{
+		ID:            pgtype.UUID{Bytes: episodeID, Valid: true},
+		Title:         "الحلقة الأولى",
+		AudioUrl:      "https://cdn.example.com/ep1.mp3",
+		Duration:      pgtype.Int4{Int32: 1800, Valid: true},
+		EpisodeNumber: pgtype.Int4{Int32: 1, Valid: true},
+		PublishedAt:   pgtype.Timestamptz{Time: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), Valid: true},
+		TranscriptUrl: pgtype.Text{String: "https://cdn.example.com/ep1.vtt", Valid: true},
+	}}
+
+	var buf bytes.Buffer
+	if err := New(program, episodes, "https://api.example.com/v1/programs/x/feed.xml").Write(&buf); err != nil {
+		t.Fatalf("Unexpected error writing feed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"`,
+		`xmlns:podcast="https://podcastindex.org/namespace/1.0"`,
+		`<itunes:author>Gomania</itunes:author>`,
+		`<itunes:category text="Technology"></itunes:category>`,
+		`<itunes:image href="https://cdn.example.com/cover.jpg"></itunes:image>`,
+		`<podcast:locked owner="owner@example.com">yes</podcast:locked>`,
+		`<itunes:duration>1800</itunes:duration>`,
+		`<podcast:transcript url="https://cdn.example.com/ep1.vtt" type="text/vtt"></podcast:transcript>`,
+		`<enclosure url="https://cdn.example.com/ep1.mp3" length="0" type="audio/mpeg"></enclosure>`,
+		`<pubDate>Mon, 15 Jan 2024 10:00:00 +0000</pubDate>`,
+		`<guid isPermaLink="false">` + episodeID.String() + `</guid>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected feed to contain %s\n%s", want, out)
+		}
+	}
+
+	// The document must stay well-formed XML.
+	dec := xml.NewDecoder(&buf)
+	for {
+		if _, err := dec.Token(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("Feed is not well-formed: %v", err)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned when an email/password pair does not match a user.
+var ErrInvalidCredentials = errors.New("invalid authentication credentials")
+
+// ErrInvalidToken is returned when a session token is unknown or expired.
+var ErrInvalidToken = errors.New("invalid or expired authentication token")
+
+// dummyPasswordHash is compared against when the email is unknown, so a
+// failed login takes as long whether or not the account exists. Its cost
+// matches HashPassword's.
+const dummyPasswordHash = "$2a$12$ghnDZUFnlQChCZd7kJ4oUu2fDmDbtbTKKBs6TcdgnljrpLAhx52/W"
+
+type AuthService struct {
+	db        DB
+	q         *database.Queries
+	logger    *slog.Logger
+	validator *validator.Validate
+	tokenTTL  time.Duration
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// Token is a session token handed to a client after a successful login.
+// The plaintext is never stored; only its SHA-256 hash is persisted.
+type Token struct {
+	Plaintext string    `json:"token"`
+	Expiry    time.Time `json:"expiry"`
+}
+
+func NewAuthService(db DB, logger *slog.Logger, tokenTTL time.Duration) *AuthService {
+	return &AuthService{
+		db:        db,
+		q:         database.New(db),
+		logger:    logger,
+		validator: validator.New(),
+		tokenTTL:  tokenTTL,
+	}
+}
+
+// Login verifies the user's password and issues a new session token.
+func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*Token, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid login request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	user, err := s.q.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(req.Password))
+			s.logger.Info("Login attempt for unknown email", "email", req.Email)
+			return nil, ErrInvalidCredentials
+		}
+		s.logger.Error("Failed to get user by email", "email", req.Email, "error", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			s.logger.Info("Login attempt with wrong password", "email", req.Email)
+			return nil, ErrInvalidCredentials
+		}
+		s.logger.Error("Failed to compare password hash", "email", req.Email, "error", err)
+		return nil, fmt.Errorf("failed to verify password: %w", err)
+	}
+
+	token, hash, err := newToken(s.tokenTTL)
+	if err != nil {
+		s.logger.Error("Failed to generate token", "error", err)
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	err = s.q.CreateToken(ctx, database.CreateTokenParams{
+		Hash:   hash,
+		UserID: user.ID,
+		Expiry: pgtype.Timestamptz{Time: token.Expiry, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to store token", "email", req.Email, "error", err)
+		return nil, fmt.Errorf("failed to store token: %w", err)
+	}
+
+	// Opportunistically drop expired tokens so the table does not grow forever.
+	if err := s.q.DeleteExpiredTokens(ctx); err != nil {
+		s.logger.Warn("Failed to delete expired tokens", "error", err)
+	}
+
+	s.logger.Info("User logged in", "email", req.Email)
+	return token, nil
+}
+
+// Authenticate returns the user owning a valid, unexpired session token.
+func (s *AuthService) Authenticate(ctx context.Context, plaintext string) (*database.GetUserForTokenRow, error) {
+	user, err := s.q.GetUserForToken(ctx, hashToken(plaintext))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		s.logger.Error("Failed to get user for token", "error", err)
+		return nil, fmt.Errorf("failed to get user for token: %w", err)
+	}
+
+	return &user, nil
+}
+
+// Logout revokes a session token.
+func (s *AuthService) Logout(ctx context.Context, plaintext string) error {
+	if err := s.q.DeleteToken(ctx, hashToken(plaintext)); err != nil {
+		s.logger.Error("Failed to delete token", "error", err)
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
+// HashPassword hashes a plaintext password for storage in users.password_hash.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func newToken(ttl time.Duration) (*Token, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, nil, err
+	}
+
+	token := &Token{
+		Plaintext: base64.RawURLEncoding.EncodeToString(b),
+		Expiry:    time.Now().Add(ttl),
+	}
+	return token, hashToken(token.Plaintext), nil
+}
+
+func hashToken(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
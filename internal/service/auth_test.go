@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestNewToken(t *testing.T) {
+	before := time.Now()
+	token, hash, err := newToken(time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(token.Plaintext) != 43 {
+		t.Errorf("Expected 32 random bytes encoded as 43 characters, got %q", token.Plaintext)
+	}
+	if want := sha256.Sum256([]byte(token.Plaintext)); !bytes.Equal(hash, want[:]) {
+		t.Errorf("Expected the SHA-256 of the plaintext to be stored, got %x", hash)
+	}
+	if !bytes.Equal(hashToken(token.Plaintext), hash) {
+		t.Error("Expected hashToken to match the stored hash")
+	}
+	if token.Expiry.Before(before.Add(time.Hour)) || token.Expiry.After(time.Now().Add(time.Hour)) {
+		t.Errorf("Expected the token to expire in an hour, got %v", token.Expiry)
+	}
+
+	other, _, err := newToken(time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if other.Plaintext == token.Plaintext {
+		t.Error("Expected every token to be different")
+	}
+}
+
+// authDB makes db hold one user and the tokens issued to it, answering
+// GetUserForToken only for tokens that have not expired, as the query does.
+func authDB(t *testing.T, db *fakeDB, email, password string) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	user := database.GetUserByEmailRow{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Email: email, PasswordHash: string(hash)}
+	tokens := map[string]time.Time{}
+
+	db.on("GetUserByEmail", func(args []any) ([]any, error) {
+		if args[0] != email {
+			return nil, nil
+		}
+		return []any{user}, nil
+	})
+	db.on("CreateToken", func(args []any) ([]any, error) {
+		tokens[string(args[0].([]byte))] = args[2].(pgtype.Timestamptz).Time
+		return []any{1}, nil
+	})
+	db.on("DeleteExpiredTokens", func([]any) ([]any, error) { return nil, nil })
+	db.on("GetUserForToken", func(args []any) ([]any, error) {
+		expiry, ok := tokens[string(args[0].([]byte))]
+		if !ok || !expiry.After(time.Now()) {
+			return nil, nil
+		}
+		return []any{database.GetUserForTokenRow{ID: user.ID, Email: email, Role: RoleEditor}}, nil
+	})
+}
+
+func TestDummyPasswordHash(t *testing.T) {
+	// An unknown email has to cost as much as a real account's hash.
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("Expected a valid bcrypt hash, got %v", err)
+	}
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := bcrypt.Cost([]byte(hash)); cost != want {
+		t.Errorf("Expected cost %d, got %d", want, cost)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		ttl      time.Duration
+		err      error
+		authErr  error
+	}{
+		{"valid", "editor@gomania.com", "correct horse", time.Hour, nil, nil},
+		{"wrong password", "editor@gomania.com", "wrong horse", time.Hour, ErrInvalidCredentials, nil},
+		{"unknown email", "nobody@gomania.com", "correct horse", time.Hour, ErrInvalidCredentials, nil},
+		{"expired token", "editor@gomania.com", "correct horse", -time.Minute, nil, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			authDB(t, db, "editor@gomania.com", "correct horse")
+			s := NewAuthService(db, slog.New(slog.NewTextHandler(io.Discard, nil)), tt.ttl)
+
+			token, err := s.Login(context.Background(), LoginRequest{Email: tt.email, Password: tt.password})
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				if db.called("CreateToken") != 0 {
+					t.Error("Expected no token to be issued")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			user, err := s.Authenticate(context.Background(), token.Plaintext)
+			if tt.authErr != nil {
+				if !errors.Is(err, tt.authErr) {
+					t.Fatalf("Expected %v, got %v", tt.authErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if user.Email != tt.email {
+				t.Errorf("Expected the token to belong to %s, got %s", tt.email, user.Email)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_UnknownToken(t *testing.T) {
+	db := newFakeDB(t)
+	authDB(t, db, "editor@gomania.com", "correct horse")
+	s := NewAuthService(db, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour)
+
+	if _, err := s.Authenticate(context.Background(), "not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// ErrCategoryNotEmpty is returned when deleting a category that still has
+// programs under the reject policy.
+var ErrCategoryNotEmpty = errors.New("category still has programs")
+
+// ErrCategoryCycle is returned when a category would become its own
+// ancestor.
+var ErrCategoryCycle = errors.New("category cannot be nested under itself or its descendants")
+
+// ErrSameCategory is returned when a category's programs would be moved into
+// the category itself.
+var ErrSameCategory = errors.New("target category is the category being removed")
+
+// OrphanPolicy decides what happens to the programs of a deleted category.
+type OrphanPolicy string
+
+const (
+	// OrphanReject refuses to delete a category that has programs.
+	OrphanReject OrphanPolicy = "reject"
+	// OrphanReassign moves the programs to a target category.
+	OrphanReassign OrphanPolicy = "reassign"
+	// OrphanNullify leaves the programs without a category.
+	OrphanNullify OrphanPolicy = "nullify"
+)
+
+type UpdateCategoryRequest struct {
+	ID   uuid.UUID `json:"id" validate:"required"`
+	Name string    `json:"name" validate:"required,min=2,max=50"`
+	// ParentID moves the category under another, or makes it top-level when
+	// set to null. Left unset, the category keeps its parent.
+	ParentID OptionalUUID `json:"parent_id"`
+}
+
+// OptionalUUID is a nullable UUID that also records whether it was set, so
+// a JSON null can be told apart from a missing field.
+type OptionalUUID struct {
+	Set   bool
+	Value *uuid.UUID
+}
+
+func (o *OptionalUUID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// CategoryNode is a category with its subcategories, for the category tree.
+type CategoryNode struct {
+	ID       pgtype.UUID     `json:"id"`
+	Name     string          `json:"name"`
+	Children []*CategoryNode `json:"children"`
+}
+
+type DeleteCategoryRequest struct {
+	ID       uuid.UUID    `json:"id" validate:"required"`
+	Policy   OrphanPolicy `json:"policy" validate:"required,oneof=reject reassign nullify"`
+	TargetID uuid.UUID    `json:"target_id" validate:"required_if=Policy reassign"`
+	// UserID is the CMS user making the change, recorded in the revisions
+	// of the programs that lose the category.
+	UserID uuid.UUID `json:"-"`
+}
+
+type MergeCategoriesRequest struct {
+	ID       uuid.UUID `json:"id" validate:"required"`
+	TargetID uuid.UUID `json:"target_id" validate:"required"`
+	// UserID is as in DeleteCategoryRequest.
+	UserID uuid.UUID `json:"-"`
+}
+
+func (s *ProgramService) UpdateCategory(ctx context.Context, req UpdateCategoryRequest) (*database.UpdateCategoryRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid update category request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	s.logger.Info("Updating category", "id", req.ID, "name", req.Name)
+
+	category, err := s.q.UpdateCategory(ctx, database.UpdateCategoryParams{
+		ID:        pgtype.UUID{Bytes: req.ID, Valid: true},
+		Name:      req.Name,
+		SetParent: req.ParentID.Set,
+		ParentID:  nullableUUID(req.ParentID.Value),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // 23505 is unique_violation
+			s.logger.Warn("Attempted to rename a category to an existing name", "id", req.ID, "name", req.Name, "error", err)
+			return nil, &ErrAlreadyExists{Message: fmt.Sprintf("category with name '%s' already exists", req.Name)}
+		}
+		if parentErr := categoryParentError(err, req.ParentID.Value); parentErr != nil {
+			s.logger.Info("Invalid parent for category", "id", req.ID, "error", err)
+			return nil, parentErr
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Category not found for update", "id", req.ID)
+			return nil, fmt.Errorf("%w: category with ID '%s' not found", ErrNotFound, req.ID.String())
+		}
+		s.logger.Error("Failed to update category", "id", req.ID, "error", err)
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	// The name is shown next to every program in the category, in listings
+	// and search results, and is itself searchable. A new parent changes
+	// which subtrees include the category.
+	cache.InvalidateTags(s.cache, cache.CategoryTag(req.ID.String()), cache.TagCategories, cache.TagProgramsList, cache.TagSearch)
+
+	s.logger.Info("Category updated successfully", "id", category.ID, "name", category.Name)
+	return &category, nil
+}
+
+// DeleteCategory removes a category, handling its programs according to
+// req.Policy. It returns how many programs were moved or left uncategorized.
+func (s *ProgramService) DeleteCategory(ctx context.Context, req DeleteCategoryRequest) (int, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid delete category request", "error", err)
+		return 0, fmt.Errorf("validation failed: %w", err)
+	}
+
+	s.logger.Info("Deleting category", "id", req.ID, "policy", req.Policy)
+
+	target := pgtype.UUID{Bytes: req.TargetID, Valid: req.Policy == OrphanReassign}
+	moved, err := s.deleteCategory(ctx, req.ID, req.Policy, target, req.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Category deleted successfully", "id", req.ID, "programs", moved)
+	return moved, nil
+}
+
+// GetCategoryTree returns the top-level categories with their subcategories
+// nested under them, each level sorted by name.
+func (s *ProgramService) GetCategoryTree(ctx context.Context) ([]*CategoryNode, error) {
+	categories, err := s.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// buildCategoryTree nests categories under their parents.
+func buildCategoryTree(categories []database.GetCategoriesRow) []*CategoryNode {
+	nodes := make(map[[16]byte]*CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID.Bytes] = &CategoryNode{ID: c.ID, Name: c.Name, Children: []*CategoryNode{}}
+	}
+
+	// categories is sorted by name, so appending keeps every level sorted.
+	roots := []*CategoryNode{}
+	for _, c := range categories {
+		node := nodes[c.ID.Bytes]
+		if c.ParentID.Valid {
+			if parent, ok := nodes[c.ParentID.Bytes]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// descendantIDs returns the IDs of every category below id.
+func descendantIDs(categories []database.GetCategoriesRow, id uuid.UUID) []uuid.UUID {
+	children := make(map[[16]byte][]uuid.UUID)
+	for _, c := range categories {
+		if c.ParentID.Valid {
+			children[c.ParentID.Bytes] = append(children[c.ParentID.Bytes], c.ID.Bytes)
+		}
+	}
+
+	var ids []uuid.UUID
+	seen := map[uuid.UUID]bool{id: true}
+	queue := []uuid.UUID{id}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, child := range children[next] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return ids
+}
+
+// MergeCategories moves every program of one category into another and
+// deletes the emptied category, in one transaction. It returns the target
+// category and how many programs were moved.
+func (s *ProgramService) MergeCategories(ctx context.Context, req MergeCategoriesRequest) (*database.GetCategoryRow, int, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid merge categories request", "error", err)
+		return nil, 0, fmt.Errorf("validation failed: %w", err)
+	}
+
+	s.logger.Info("Merging categories", "id", req.ID, "target_id", req.TargetID)
+
+	moved, err := s.deleteCategory(ctx, req.ID, OrphanReassign, pgtype.UUID{Bytes: req.TargetID, Valid: true}, req.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	target, err := s.q.GetCategory(ctx, pgtype.UUID{Bytes: req.TargetID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to get merge target category", "id", req.TargetID, "error", err)
+		return nil, 0, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	s.logger.Info("Categories merged successfully", "id", req.ID, "target_id", req.TargetID, "programs", moved)
+	return &target, moved, nil
+}
+
+// deleteCategory deletes category id in a transaction, first moving its
+// programs to target (which may be NULL) unless policy is OrphanReject. Every
+// program that changes category, trashed ones included, gets a revision by
+// userID.
+func (s *ProgramService) deleteCategory(ctx context.Context, id uuid.UUID, policy OrphanPolicy, target pgtype.UUID, userID uuid.UUID) (int, error) {
+	categoryID := pgtype.UUID{Bytes: id, Valid: true}
+	if target.Valid && target.Bytes == id {
+		return 0, ErrSameCategory
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("Failed to begin category deletion transaction", "error", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := qtx.GetCategory(ctx, categoryID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Category not found for deletion", "id", id)
+			return 0, fmt.Errorf("%w: category with ID '%s' not found", ErrNotFound, id.String())
+		}
+		s.logger.Error("Failed to get category", "id", id, "error", err)
+		return 0, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	programs, err := qtx.GetCategoryProgramsForUpdate(ctx, categoryID)
+	if err != nil {
+		s.logger.Error("Failed to lock category programs", "id", id, "error", err)
+		return 0, fmt.Errorf("failed to lock category programs: %w", err)
+	}
+
+	var moved []pgtype.UUID
+	switch policy {
+	case OrphanReject:
+		count, err := qtx.CountProgramsInCategory(ctx, categoryID)
+		if err != nil {
+			s.logger.Error("Failed to count programs in category", "id", id, "error", err)
+			return 0, fmt.Errorf("failed to count programs in category: %w", err)
+		}
+		if count > 0 {
+			s.logger.Info("Refusing to delete category with programs", "id", id, "programs", count)
+			return 0, fmt.Errorf("%w: category with ID '%s' has %d programs", ErrCategoryNotEmpty, id.String(), count)
+		}
+		if err := qtx.UncategorizeDeletedPrograms(ctx, categoryID); err != nil {
+			s.logger.Error("Failed to uncategorize trashed programs", "id", id, "error", err)
+			return 0, fmt.Errorf("failed to uncategorize trashed programs: %w", err)
+		}
+	default:
+		if target.Valid {
+			if _, err := qtx.GetCategory(ctx, target); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					s.logger.Info("Target category not found", "id", uuid.UUID(target.Bytes))
+					return 0, fmt.Errorf("%w: target category with ID '%s' not found", ErrNotFound, uuid.UUID(target.Bytes).String())
+				}
+				s.logger.Error("Failed to get target category", "id", uuid.UUID(target.Bytes), "error", err)
+				return 0, fmt.Errorf("failed to get target category: %w", err)
+			}
+		}
+
+		moved, err = qtx.ReassignCategoryPrograms(ctx, database.ReassignCategoryProgramsParams{
+			ToCategoryID:   target,
+			FromCategoryID: categoryID,
+		})
+		if err != nil {
+			s.logger.Error("Failed to reassign category programs", "id", id, "error", err)
+			return 0, fmt.Errorf("failed to reassign programs: %w", err)
+		}
+	}
+
+	// Under the reject policy, only trashed programs are left to lose the
+	// category.
+	var movedTo *uuid.UUID
+	if target.Valid {
+		movedTo = (*uuid.UUID)(&target.Bytes)
+	}
+	for _, p := range programs {
+		before := database.GetProgramForUpdateRow(p)
+		after := programSnapshot(before)
+		after.CategoryID = movedTo
+		if err := s.recordRevision(ctx, qtx, before, after, userID, pgtype.Int4{}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := qtx.ReparentChildCategories(ctx, categoryID); err != nil {
+		s.logger.Error("Failed to move subcategories up", "id", id, "error", err)
+		return 0, fmt.Errorf("failed to move subcategories: %w", err)
+	}
+
+	if _, err := qtx.DeleteCategory(ctx, categoryID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // 23503 is foreign_key_violation
+			// A program was added to the category since it was checked.
+			s.logger.Warn("Category gained programs during deletion", "id", id, "error", err)
+			return 0, fmt.Errorf("%w: category with ID '%s' has programs", ErrCategoryNotEmpty, id.String())
+		}
+		s.logger.Error("Failed to delete category", "id", id, "error", err)
+		return 0, fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error("Failed to commit category deletion transaction", "error", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	tags := []string{cache.CategoryTag(id.String()), cache.TagCategories}
+	if len(moved) > 0 {
+		// Moved programs show their new category's name everywhere they
+		// appear, and join the target's listing.
+		tags = append(tags, cache.TagSearch)
+		if target.Valid {
+			tags = append(tags, cache.CategoryTag(uuid.UUID(target.Bytes).String()))
+		}
+		for _, programID := range moved {
+			tags = append(tags, cache.ProgramTag(uuid.UUID(programID.Bytes).String()))
+		}
+	}
+	cache.InvalidateTags(s.cache, tags...)
+
+	return len(moved), nil
+}
+
+// categoryParentError maps the errors Postgres raises for an invalid parent
+// to service errors. It returns nil for any other error.
+func categoryParentError(err error, parentID *uuid.UUID) error {
+	var pgErr *pgconn.PgError
+	if parentID == nil || !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch {
+	case pgErr.Code == "23503" && pgErr.ConstraintName == "categories_parent_id_fkey": // 23503 is foreign_key_violation
+		return fmt.Errorf("%w: parent category with ID '%s' not found", ErrNotFound, parentID.String())
+	case pgErr.Code == "23514": // 23514 is check_violation, raised by the self and cycle checks
+		return fmt.Errorf("%w: parent category '%s'", ErrCategoryCycle, parentID.String())
+	}
+	return nil
+}
+
+func nullableUUID(id *uuid.UUID) pgtype.UUID {
+	if id == nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: *id, Valid: true}
+}
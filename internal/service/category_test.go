@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// testCategories returns تعليم > لغات > إنجليزي, تعليم > رياضيات and a
+// separate تقنية, sorted by name as GetCategories returns them.
+func testCategories() (rows []database.GetCategoriesRow, education, languages, english, math, tech uuid.UUID) {
+	education, languages, english, math, tech = uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	row := func(id uuid.UUID, name string, parent *uuid.UUID) database.GetCategoriesRow {
+		return database.GetCategoriesRow{ID: pgtype.UUID{Bytes: id, Valid: true}, Name: name, ParentID: nullableUUID(parent)}
+	}
+	rows = []database.GetCategoriesRow{
+		row(english, "إنجليزي", &languages),
+		row(tech, "تقنية", nil),
+		row(education, "تعليم", nil),
+		row(math, "رياضيات", &education),
+		row(languages, "لغات", &education),
+	}
+	return rows, education, languages, english, math, tech
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	rows, education, languages, english, _, tech := testCategories()
+
+	roots := buildCategoryTree(rows)
+	if len(roots) != 2 || roots[0].ID.Bytes != tech || roots[1].ID.Bytes != education {
+		t.Fatalf("Expected تقنية and تعليم at the top, got %+v", roots)
+	}
+	if len(roots[0].Children) != 0 || roots[0].Children == nil {
+		t.Errorf("Expected a leaf to have an empty children list, got %v", roots[0].Children)
+	}
+
+	children := roots[1].Children
+	if len(children) != 2 || children[0].Name != "رياضيات" || children[1].ID.Bytes != languages {
+		t.Fatalf("Expected رياضيات and لغات under تعليم, got %+v", children)
+	}
+	if len(children[1].Children) != 1 || children[1].Children[0].ID.Bytes != english {
+		t.Errorf("Expected إنجليزي under لغات, got %+v", children[1].Children)
+	}
+}
+
+func TestDescendantIDs(t *testing.T) {
+	rows, education, languages, english, math, tech := testCategories()
+
+	got := map[uuid.UUID]bool{}
+	for _, id := range descendantIDs(rows, education) {
+		got[id] = true
+	}
+	if len(got) != 3 || !got[languages] || !got[english] || !got[math] {
+		t.Errorf("Expected لغات, إنجليزي and رياضيات below تعليم, got %v", got)
+	}
+
+	if ids := descendantIDs(rows, tech); len(ids) != 0 {
+		t.Errorf("Expected no descendants for a leaf, got %v", ids)
+	}
+}
+
+func TestUpdateCategory_Parent(t *testing.T) {
+	parent := uuid.New()
+	tests := []struct {
+		name       string
+		body       string
+		setParent  bool
+		wantParent pgtype.UUID
+	}{
+		{"rename keeps the parent", `{"name":"لغات"}`, false, pgtype.UUID{}},
+		{"null makes it top-level", `{"name":"لغات","parent_id":null}`, true, pgtype.UUID{}},
+		{"new parent", `{"name":"لغات","parent_id":"` + parent.String() + `"}`, true, pgtype.UUID{Bytes: parent, Valid: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req UpdateCategoryRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			req.ID = uuid.New()
+
+			db := newFakeDB(t)
+			var got database.UpdateCategoryParams
+			db.on("UpdateCategory", func(args []any) ([]any, error) {
+				got = database.UpdateCategoryParams{Name: args[0].(string), SetParent: args[1].(bool), ParentID: args[2].(pgtype.UUID), ID: args[3].(pgtype.UUID)}
+				return []any{database.UpdateCategoryRow{ID: got.ID, Name: got.Name}}, nil
+			})
+			s := newTestProgramService(t, db)
+
+			if _, err := s.UpdateCategory(context.Background(), req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.SetParent != tt.setParent || got.ParentID != tt.wantParent {
+				t.Errorf("Expected set_parent %v with parent %v, got %v with %v", tt.setParent, tt.wantParent, got.SetParent, got.ParentID)
+			}
+		})
+	}
+}
+
+// categoryProgram is a program as the category queries of categoryDB see it.
+type categoryProgram struct {
+	id       pgtype.UUID
+	category pgtype.UUID
+	trashed  bool
+}
+
+// categoryDB makes db hold categories and programs in them, and answer the
+// queries that delete a category the way Postgres would.
+func categoryDB(t *testing.T, db *fakeDB, categories map[uuid.UUID]bool, programs []*categoryProgram) {
+	db.on("GetCategory", func(args []any) ([]any, error) {
+		id := args[0].(pgtype.UUID)
+		if !categories[id.Bytes] {
+			return nil, nil
+		}
+		return []any{database.GetCategoryRow{ID: id, Name: "تقنية"}}, nil
+	})
+	db.on("GetCategoryProgramsForUpdate", func(args []any) ([]any, error) {
+		var rows []any
+		for _, p := range programs {
+			if p.category == args[0] {
+				rows = append(rows, database.GetCategoryProgramsForUpdateRow{ID: p.id, Title: "برنامج", CategoryID: p.category})
+			}
+		}
+		return rows, nil
+	})
+	db.on("CountProgramsInCategory", func(args []any) ([]any, error) {
+		var n int64
+		for _, p := range programs {
+			if p.category == args[0] && !p.trashed {
+				n++
+			}
+		}
+		return []any{n}, nil
+	})
+	db.on("UncategorizeDeletedPrograms", func(args []any) ([]any, error) {
+		for _, p := range programs {
+			if p.category == args[0] && p.trashed {
+				p.category = pgtype.UUID{}
+			}
+		}
+		return nil, nil
+	})
+	db.on("ReassignCategoryPrograms", func(args []any) ([]any, error) {
+		var ids []any
+		for _, p := range programs {
+			if p.category == args[1] {
+				p.category = args[0].(pgtype.UUID)
+				ids = append(ids, p.id)
+			}
+		}
+		return ids, nil
+	})
+	db.on("ReparentChildCategories", func([]any) ([]any, error) { return nil, nil })
+	db.on("DeleteCategory", func(args []any) ([]any, error) {
+		delete(categories, args[0].(pgtype.UUID).Bytes)
+		return []any{1}, nil
+	})
+	recordRevisions(t, db)
+}
+
+func TestDeleteCategory_Policies(t *testing.T) {
+	category, target := uuid.New(), uuid.New()
+	tests := []struct {
+		name     string
+		policy   OrphanPolicy
+		targetID uuid.UUID
+		trashed  bool // whether the category's only live program is trashed too
+		err      error
+		moved    int
+		movedTo  pgtype.UUID
+	}{
+		{"reject", OrphanReject, uuid.Nil, false, ErrCategoryNotEmpty, 0, pgtype.UUID{}},
+		{"reject only trashed", OrphanReject, uuid.Nil, true, nil, 0, pgtype.UUID{}},
+		{"nullify", OrphanNullify, uuid.Nil, false, nil, 2, pgtype.UUID{}},
+		{"reassign", OrphanReassign, target, false, nil, 2, pgtype.UUID{Bytes: target, Valid: true}},
+		{"reassign to itself", OrphanReassign, category, false, ErrSameCategory, 0, pgtype.UUID{}},
+		{"reassign to missing", OrphanReassign, uuid.New(), false, ErrNotFound, 0, pgtype.UUID{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			categories := map[uuid.UUID]bool{category: true, target: true}
+			programs := []*categoryProgram{
+				{id: pgtype.UUID{Bytes: uuid.New(), Valid: true}, category: pgtype.UUID{Bytes: category, Valid: true}, trashed: tt.trashed},
+				{id: pgtype.UUID{Bytes: uuid.New(), Valid: true}, category: pgtype.UUID{Bytes: category, Valid: true}, trashed: true},
+			}
+			categoryDB(t, db, categories, programs)
+			s := newTestProgramService(t, db)
+
+			moved, err := s.DeleteCategory(context.Background(), DeleteCategoryRequest{ID: category, Policy: tt.policy, TargetID: tt.targetID})
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				if db.commits != 0 {
+					t.Error("Expected the deletion to be rolled back")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if moved != tt.moved {
+				t.Errorf("Expected %d programs moved, got %d", tt.moved, moved)
+			}
+			if categories[category] {
+				t.Error("Expected the category to be deleted")
+			}
+			for _, p := range programs {
+				if p.category != tt.movedTo {
+					t.Errorf("Expected every program to end up in %v, got %v", tt.movedTo, p.category)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteCategory_NotFound(t *testing.T) {
+	db := newFakeDB(t)
+	categoryDB(t, db, map[uuid.UUID]bool{}, nil)
+	s := newTestProgramService(t, db)
+
+	_, err := s.DeleteCategory(context.Background(), DeleteCategoryRequest{ID: uuid.New(), Policy: OrphanNullify})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMergeCategories(t *testing.T) {
+	category, target := uuid.New(), uuid.New()
+	tests := []struct {
+		name     string
+		targetID uuid.UUID
+		err      error
+	}{
+		{"merge", target, nil},
+		{"into itself", category, ErrSameCategory},
+		{"into a missing category", uuid.New(), ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			categories := map[uuid.UUID]bool{category: true, target: true}
+			programs := []*categoryProgram{
+				{id: pgtype.UUID{Bytes: uuid.New(), Valid: true}, category: pgtype.UUID{Bytes: category, Valid: true}},
+			}
+			categoryDB(t, db, categories, programs)
+			s := newTestProgramService(t, db)
+
+			merged, moved, err := s.MergeCategories(context.Background(), MergeCategoriesRequest{ID: category, TargetID: tt.targetID})
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				if !categories[category] || db.commits != 0 {
+					t.Error("Expected the category to be kept")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if merged.ID.Bytes != target || moved != 1 {
+				t.Errorf("Expected 1 program merged into %s, got %d into %v", target, moved, merged.ID)
+			}
+			if categories[category] || programs[0].category.Bytes != target {
+				t.Error("Expected the program to move and the category to be deleted")
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/khatibomar/gomania/internal/cache"
+)
+
+// fakeQuery answers a query given its arguments. Each returned value is a
+// row: a struct whose fields are scanned in order into the columns, or a
+// single column value. Exec reports one affected row per value.
+type fakeQuery func(args []any) ([]any, error)
+
+// fakeDB stands in for Postgres. It answers the sqlc queries, which it tells
+// apart by the "-- name:" line sqlc starts each of them with, with the
+// fakeQuery registered for that name.
+type fakeDB struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	queries   map[string]fakeQuery
+	calls     []string
+	commits   int
+	rollbacks int
+}
+
+func newFakeDB(t *testing.T) *fakeDB {
+	t.Helper()
+	return &fakeDB{t: t, queries: make(map[string]fakeQuery)}
+}
+
+// on registers how to answer the query called name.
+func (db *fakeDB) on(name string, q fakeQuery) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.queries[name] = q
+}
+
+// called reports how many times the query called name ran.
+func (db *fakeDB) called(name string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	n := 0
+	for _, c := range db.calls {
+		if c == name {
+			n++
+		}
+	}
+	return n
+}
+
+func (db *fakeDB) run(sql string, args []any) ([]any, error) {
+	name, ok := queryName(sql)
+	if !ok {
+		db.t.Errorf("unexpected query without a name:\n%s", sql)
+		return nil, fmt.Errorf("unexpected query")
+	}
+
+	db.mu.Lock()
+	q := db.queries[name]
+	db.calls = append(db.calls, name)
+	db.mu.Unlock()
+
+	if q == nil {
+		db.t.Errorf("unexpected query %s", name)
+		return nil, fmt.Errorf("unexpected query %s", name)
+	}
+	return q(args)
+}
+
+// queryName returns the name sqlc gave a query.
+func queryName(sql string) (string, bool) {
+	rest, ok := strings.CutPrefix(sql, "-- name: ")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name, true
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	rows, err := db.run(sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", len(rows))), nil
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := db.run(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: rows, i: -1}, nil
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	rows, err := db.run(sql, args)
+	return &fakeRow{rows: rows, err: err}
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{db: db}, nil
+}
+
+// fakeTx runs its queries on the fakeDB straight away; only whether it was
+// committed is recorded.
+type fakeTx struct {
+	pgx.Tx
+	db   *fakeDB
+	done bool
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	if tx.done {
+		return pgx.ErrTxClosed
+	}
+	tx.done = true
+	tx.db.mu.Lock()
+	tx.db.commits++
+	tx.db.mu.Unlock()
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	if tx.done {
+		return pgx.ErrTxClosed
+	}
+	tx.done = true
+	tx.db.mu.Lock()
+	tx.db.rollbacks++
+	tx.db.mu.Unlock()
+	return nil
+}
+
+type fakeRow struct {
+	rows []any
+	err  error
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.rows) == 0 {
+		return pgx.ErrNoRows
+	}
+	return scanFake(r.rows[0], dest)
+}
+
+type fakeRows struct {
+	pgx.Rows
+	rows []any
+	i    int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error { return scanFake(r.rows[r.i], dest) }
+func (r *fakeRows) Close()                 {}
+func (r *fakeRows) Err() error             { return nil }
+
+// scanFake copies a row into the scan destinations.
+func scanFake(row any, dest []any) error {
+	values := []reflect.Value{reflect.ValueOf(row)}
+	if len(dest) > 1 {
+		v := reflect.ValueOf(row)
+		if v.Kind() != reflect.Struct || v.NumField() != len(dest) {
+			return fmt.Errorf("cannot scan %T into %d columns", row, len(dest))
+		}
+		values = make([]reflect.Value, len(dest))
+		for i := range values {
+			values[i] = v.Field(i)
+		}
+	}
+
+	for i, d := range dest {
+		dst := reflect.ValueOf(d).Elem()
+		if !values[i].IsValid() {
+			dst.SetZero()
+			continue
+		}
+		if !values[i].Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("cannot scan %s into %s", values[i].Type(), dst.Type())
+		}
+		dst.Set(values[i])
+	}
+	return nil
+}
+
+// newTestProgramService returns a ProgramService on db with a fresh memory
+// cache.
+func newTestProgramService(t *testing.T, db *fakeDB) *ProgramService {
+	t.Helper()
+
+	c := cache.New(time.Minute)
+	t.Cleanup(c.Close)
+	return NewProgramServiceWithCacheBackend(db, slog.New(slog.NewTextHandler(io.Discard, nil)), c)
+}
+
+// newTestEpisodeService returns an EpisodeService on db sharing c with other
+// services, as the API does.
+func newTestEpisodeService(t *testing.T, db *fakeDB, c cache.Cache) *EpisodeService {
+	t.Helper()
+	return NewEpisodeServiceWithCacheBackend(db, slog.New(slog.NewTextHandler(io.Discard, nil)), c)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+type EpisodeService struct {
+	db        DB
+	q         *database.Queries
+	logger    *slog.Logger
+	validator *validator.Validate
+	cache     cache.Cache
+
+	episodes     *cache.Typed[*database.GetEpisodeRow]
+	episodeLists *cache.Typed[[]database.ListEpisodesByProgramRow]
+}
+
+// Cached values must be registered so out-of-process caches can decode them.
+func init() {
+	cache.Register(
+		&database.GetEpisodeRow{},
+		[]database.ListEpisodesByProgramRow{},
+	)
+}
+
+type CreateEpisodeRequest struct {
+	ProgramID     uuid.UUID  `json:"program_id" validate:"required"`
+	Title         string     `json:"title" validate:"required,min=3,max=255"`
+	Description   string     `json:"description" validate:"omitempty,max=5000"`
+	AudioURL      string     `json:"audio_url" validate:"required,url,max=2048"`
+	Duration      int        `json:"duration" validate:"omitempty,gt=0"`
+	SeasonNumber  int        `json:"season_number" validate:"omitempty,gt=0"`
+	EpisodeNumber int        `json:"episode_number" validate:"omitempty,gt=0"`
+	PublishedAt   *time.Time `json:"published_at"`
+	TranscriptURL string     `json:"transcript_url" validate:"omitempty,url,max=2048"`
+}
+
+type UpdateEpisodeRequest struct {
+	ID            uuid.UUID  `json:"id" validate:"required"`
+	ProgramID     uuid.UUID  `json:"program_id" validate:"required"`
+	Title         string     `json:"title" validate:"required,min=3,max=255"`
+	Description   string     `json:"description" validate:"omitempty,max=5000"`
+	AudioURL      string     `json:"audio_url" validate:"required,url,max=2048"`
+	Duration      int        `json:"duration" validate:"omitempty,gt=0"`
+	SeasonNumber  int        `json:"season_number" validate:"omitempty,gt=0"`
+	EpisodeNumber int        `json:"episode_number" validate:"omitempty,gt=0"`
+	PublishedAt   *time.Time `json:"published_at"`
+	TranscriptURL string     `json:"transcript_url" validate:"omitempty,url,max=2048"`
+}
+
+func NewEpisodeService(db DB, logger *slog.Logger) *EpisodeService {
+	return NewEpisodeServiceWithCache(db, logger, 15*time.Minute)
+}
+
+func NewEpisodeServiceWithCache(db DB, logger *slog.Logger, cacheTTL time.Duration) *EpisodeService {
+	return NewEpisodeServiceWithCacheBackend(db, logger, cache.NewMemoryCache(cacheTTL))
+}
+
+// NewEpisodeServiceWithCacheBackend creates the service on top of an existing
+// cache, which may be shared with other services.
+func NewEpisodeServiceWithCacheBackend(db DB, logger *slog.Logger, c cache.Cache) *EpisodeService {
+	return NewEpisodeServiceWithLoader(db, logger, cache.NewLoader(c, cache.LoaderOptions{Logger: logger}))
+}
+
+// NewEpisodeServiceWithLoader creates the service on top of a read-through
+// loader, which may be shared with other services.
+func NewEpisodeServiceWithLoader(db DB, logger *slog.Logger, loader *cache.Loader) *EpisodeService {
+	return &EpisodeService{
+		db:        db,
+		q:         database.New(db),
+		logger:    logger,
+		validator: validator.New(),
+		cache:     loader.Cache(),
+
+		episodes:     cache.NewTyped(loader, cache.TypedOptions[*database.GetEpisodeRow]{}),
+		episodeLists: cache.NewTyped(loader, cache.TypedOptions[[]database.ListEpisodesByProgramRow]{}),
+	}
+}
+
+func (s *EpisodeService) CreateEpisode(ctx context.Context, req CreateEpisodeRequest) (*database.CreateEpisodeRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid create episode request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	s.logger.Info("Creating new episode", "program_id", req.ProgramID, "title", req.Title)
+
+	episode, err := s.q.CreateEpisode(ctx, database.CreateEpisodeParams{
+		ProgramID:     pgtype.UUID{Bytes: req.ProgramID, Valid: true},
+		Title:         req.Title,
+		Description:   pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		AudioUrl:      req.AudioURL,
+		Duration:      pgtype.Int4{Int32: int32(req.Duration), Valid: req.Duration > 0},
+		SeasonNumber:  pgtype.Int4{Int32: int32(req.SeasonNumber), Valid: req.SeasonNumber > 0},
+		EpisodeNumber: pgtype.Int4{Int32: int32(req.EpisodeNumber), Valid: req.EpisodeNumber > 0},
+		PublishedAt:   timestamptz(req.PublishedAt),
+		TranscriptUrl: pgtype.Text{String: req.TranscriptURL, Valid: req.TranscriptURL != ""},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// No episode is created for a program in the trash.
+			s.logger.Info("Program not found for new episode", "program_id", req.ProgramID)
+			return nil, fmt.Errorf("%w: program with ID '%s' not found", ErrNotFound, req.ProgramID.String())
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation
+				s.logger.Warn("Attempted to create a duplicate episode", "program_id", req.ProgramID, "season", req.SeasonNumber, "episode", req.EpisodeNumber)
+				return nil, &ErrAlreadyExists{Message: fmt.Sprintf("episode %d of season %d already exists for this program", req.EpisodeNumber, req.SeasonNumber)}
+			case "23503": // foreign_key_violation
+				s.logger.Info("Program not found for new episode", "program_id", req.ProgramID)
+				return nil, fmt.Errorf("%w: program with ID '%s' not found", ErrNotFound, req.ProgramID.String())
+			}
+		}
+		s.logger.Error("Failed to create episode", "program_id", req.ProgramID, "title", req.Title, "error", err)
+		return nil, fmt.Errorf("failed to create episode: %w", err)
+	}
+
+	s.cache.Delete(cache.ProgramEpisodesKey(req.ProgramID.String()))
+
+	s.logger.Info("Episode created successfully", "program_id", req.ProgramID, "id", episode.ID)
+	return &episode, nil
+}
+
+// GetEpisode returns an episode of a program. Episodes are cached under the
+// program's tag, so moving the program to the trash drops them.
+func (s *EpisodeService) GetEpisode(ctx context.Context, programID, id uuid.UUID) (*database.GetEpisodeRow, error) {
+	episode, err := s.episodes.Load(ctx, cache.EpisodeKey(id.String()), func(ctx context.Context) (*database.GetEpisodeRow, error) {
+		episode, err := s.q.GetEpisode(ctx, database.GetEpisodeParams{
+			ID:        pgtype.UUID{Bytes: id, Valid: true},
+			ProgramID: pgtype.UUID{Bytes: programID, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.logger.Info("Episode not found in DB", "program_id", programID, "id", id)
+				return nil, fmt.Errorf("%w: episode with ID '%s' not found", ErrNotFound, id.String())
+			}
+			s.logger.Error("Failed to get episode from DB", "id", id, "error", err)
+			return nil, fmt.Errorf("failed to get episode: %w", err)
+		}
+		return &episode, nil
+	}, cache.ProgramTag(programID.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	// The entry is keyed by the episode alone, and may have been loaded for
+	// its own program.
+	if episode.ProgramID.Bytes != programID {
+		s.logger.Info("Episode belongs to another program", "program_id", programID, "id", id)
+		return nil, fmt.Errorf("%w: episode with ID '%s' not found", ErrNotFound, id.String())
+	}
+	return episode, nil
+}
+
+func (s *EpisodeService) ListEpisodes(ctx context.Context, programID uuid.UUID) ([]database.ListEpisodesByProgramRow, error) {
+	return s.episodeLists.Load(ctx, cache.ProgramEpisodesKey(programID.String()), func(ctx context.Context) ([]database.ListEpisodesByProgramRow, error) {
+		s.logger.Info("Listing episodes", "program_id", programID)
+		episodes, err := s.q.ListEpisodesByProgram(ctx, pgtype.UUID{Bytes: programID, Valid: true})
+		if err != nil {
+			s.logger.Error("Failed to list episodes", "program_id", programID, "error", err)
+			return nil, fmt.Errorf("failed to list episodes: %w", err)
+		}
+		return episodes, nil
+	}, cache.ProgramTag(programID.String()))
+}
+
+// ListPublishedEpisodes returns the episodes of a program whose publish date
+// has passed, newest first. It is not cached because the result changes as
+// scheduled episodes go live.
+func (s *EpisodeService) ListPublishedEpisodes(ctx context.Context, programID uuid.UUID) ([]database.ListPublishedEpisodesByProgramRow, error) {
+	episodes, err := s.q.ListPublishedEpisodesByProgram(ctx, pgtype.UUID{Bytes: programID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to list published episodes", "program_id", programID, "error", err)
+		return nil, fmt.Errorf("failed to list published episodes: %w", err)
+	}
+
+	return episodes, nil
+}
+
+func (s *EpisodeService) UpdateEpisode(ctx context.Context, req UpdateEpisodeRequest) (*database.UpdateEpisodeRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid update episode request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	s.logger.Info("Updating episode", "program_id", req.ProgramID, "id", req.ID, "title", req.Title)
+
+	episode, err := s.q.UpdateEpisode(ctx, database.UpdateEpisodeParams{
+		ID:            pgtype.UUID{Bytes: req.ID, Valid: true},
+		ProgramID:     pgtype.UUID{Bytes: req.ProgramID, Valid: true},
+		Title:         req.Title,
+		Description:   pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		AudioUrl:      req.AudioURL,
+		Duration:      pgtype.Int4{Int32: int32(req.Duration), Valid: req.Duration > 0},
+		SeasonNumber:  pgtype.Int4{Int32: int32(req.SeasonNumber), Valid: req.SeasonNumber > 0},
+		EpisodeNumber: pgtype.Int4{Int32: int32(req.EpisodeNumber), Valid: req.EpisodeNumber > 0},
+		PublishedAt:   timestamptz(req.PublishedAt),
+		TranscriptUrl: pgtype.Text{String: req.TranscriptURL, Valid: req.TranscriptURL != ""},
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Warn("Attempted to update episode to a conflicting state", "id", req.ID, "error", err)
+			return nil, &ErrAlreadyExists{Message: fmt.Sprintf("episode %d of season %d already exists for this program", req.EpisodeNumber, req.SeasonNumber)}
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Episode not found for update", "program_id", req.ProgramID, "id", req.ID)
+			return nil, fmt.Errorf("%w: episode with ID '%s' not found for update", ErrNotFound, req.ID.String())
+		}
+		s.logger.Error("Failed to update episode in DB", "id", req.ID, "error", err)
+		return nil, fmt.Errorf("failed to update episode: %w", err)
+	}
+
+	s.cache.Delete(cache.EpisodeKey(req.ID.String()))
+	s.cache.Delete(cache.ProgramEpisodesKey(req.ProgramID.String()))
+
+	s.logger.Info("Episode updated successfully", "id", episode.ID, "title", episode.Title)
+	return &episode, nil
+}
+
+func (s *EpisodeService) DeleteEpisode(ctx context.Context, programID, id uuid.UUID) error {
+	s.logger.Info("Deleting episode", "program_id", programID, "id", id)
+
+	rows, err := s.q.DeleteEpisode(ctx, database.DeleteEpisodeParams{
+		ID:        pgtype.UUID{Bytes: id, Valid: true},
+		ProgramID: pgtype.UUID{Bytes: programID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete episode from DB", "id", id, "error", err)
+		return fmt.Errorf("failed to delete episode: %w", err)
+	}
+
+	s.cache.Delete(cache.EpisodeKey(id.String()))
+	s.cache.Delete(cache.ProgramEpisodesKey(programID.String()))
+
+	if rows == 0 {
+		s.logger.Info("Episode not found for deletion", "program_id", programID, "id", id)
+		return fmt.Errorf("%w: episode with ID '%s' not found", ErrNotFound, id.String())
+	}
+
+	s.logger.Info("Episode deleted successfully", "id", id)
+	return nil
+}
+
+// timestamptz converts an optional time into its nullable database representation.
+func timestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+func TestGetEpisode_Cached(t *testing.T) {
+	db := newFakeDB(t)
+	programID, episodeID := uuid.New(), uuid.New()
+	db.on("GetEpisode", func(args []any) ([]any, error) {
+		if args[1] != (pgtype.UUID{Bytes: programID, Valid: true}) {
+			return nil, nil
+		}
+		return []any{database.GetEpisodeRow{ID: args[0].(pgtype.UUID), ProgramID: args[1].(pgtype.UUID), Title: "الحلقة الأولى"}}, nil
+	})
+	c := cache.New(time.Minute)
+	t.Cleanup(c.Close)
+	s := newTestEpisodeService(t, db, c)
+
+	for range 2 {
+		if _, err := s.GetEpisode(context.Background(), programID, episodeID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if n := db.called("GetEpisode"); n != 1 {
+		t.Errorf("Expected the episode to be read once, got %d reads", n)
+	}
+
+	// The cached episode is not served for another program.
+	if _, err := s.GetEpisode(context.Background(), uuid.New(), episodeID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another program, got %v", err)
+	}
+}
+
+func TestCreateEpisode_Validation(t *testing.T) {
+	valid := CreateEpisodeRequest{
+		ProgramID: uuid.New(),
+		Title:     "الحلقة الأولى",
+		AudioURL:  "https://cdn.example.com/1.mp3",
+	}
+	tests := []struct {
+		name string
+		edit func(*CreateEpisodeRequest)
+	}{
+		{"no program", func(r *CreateEpisodeRequest) { r.ProgramID = uuid.Nil }},
+		{"no title", func(r *CreateEpisodeRequest) { r.Title = "" }},
+		{"short title", func(r *CreateEpisodeRequest) { r.Title = "ab" }},
+		{"no audio", func(r *CreateEpisodeRequest) { r.AudioURL = "" }},
+		{"audio not a URL", func(r *CreateEpisodeRequest) { r.AudioURL = "episode.mp3" }},
+		{"negative duration", func(r *CreateEpisodeRequest) { r.Duration = -1 }},
+		{"negative season", func(r *CreateEpisodeRequest) { r.SeasonNumber = -1 }},
+		{"transcript not a URL", func(r *CreateEpisodeRequest) { r.TranscriptURL = "transcript" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			c := cache.New(time.Minute)
+			t.Cleanup(c.Close)
+			s := newTestEpisodeService(t, db, c)
+
+			req := valid
+			tt.edit(&req)
+			if _, err := s.CreateEpisode(context.Background(), req); !IsValidationError(err) {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+		})
+	}
+}
+
+// episodeDB makes db hold episodes and answer the episode queries the way
+// Postgres would, matching episodes on both their ID and their program.
+func episodeDB(db *fakeDB, episodes map[pgtype.UUID]*database.GetEpisodeRow) {
+	find := func(args []any) *database.GetEpisodeRow {
+		e := episodes[args[0].(pgtype.UUID)]
+		if e == nil || e.ProgramID != args[1] {
+			return nil
+		}
+		return e
+	}
+	db.on("GetEpisode", func(args []any) ([]any, error) {
+		if e := find(args); e != nil {
+			return []any{*e}, nil
+		}
+		return nil, nil
+	})
+	db.on("UpdateEpisode", func(args []any) ([]any, error) {
+		e := find(args)
+		if e == nil {
+			return nil, nil
+		}
+		e.Title = args[2].(string)
+		return []any{database.UpdateEpisodeRow{ID: e.ID, ProgramID: e.ProgramID, Title: e.Title}}, nil
+	})
+	db.on("DeleteEpisode", func(args []any) ([]any, error) {
+		e := find(args)
+		if e == nil {
+			return nil, nil
+		}
+		delete(episodes, e.ID)
+		return []any{1}, nil
+	})
+	db.on("ListPublishedEpisodesByProgram", func(args []any) ([]any, error) {
+		var rows []any
+		for _, e := range episodes {
+			if e.ProgramID == args[0] && e.PublishedAt.Valid && !e.PublishedAt.Time.After(time.Now()) {
+				rows = append(rows, database.ListPublishedEpisodesByProgramRow{ID: e.ID, ProgramID: e.ProgramID, Title: e.Title, PublishedAt: e.PublishedAt})
+			}
+		}
+		return rows, nil
+	})
+}
+
+func TestEpisode_OtherProgram(t *testing.T) {
+	programID, otherID := uuid.New(), uuid.New()
+	episode := &database.GetEpisodeRow{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ProgramID: pgtype.UUID{Bytes: programID, Valid: true},
+		Title:     "الحلقة الأولى",
+	}
+	db := newFakeDB(t)
+	episodeDB(db, map[pgtype.UUID]*database.GetEpisodeRow{episode.ID: episode})
+	c := cache.New(time.Minute)
+	t.Cleanup(c.Close)
+	s := newTestEpisodeService(t, db, c)
+	ctx := context.Background()
+	id := uuid.UUID(episode.ID.Bytes)
+
+	if _, err := s.GetEpisode(ctx, otherID, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound getting the episode through another program, got %v", err)
+	}
+
+	_, err := s.UpdateEpisode(ctx, UpdateEpisodeRequest{
+		ID:        id,
+		ProgramID: otherID,
+		Title:     "عنوان آخر",
+		AudioURL:  "https://cdn.example.com/1.mp3",
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating the episode through another program, got %v", err)
+	}
+	if episode.Title != "الحلقة الأولى" {
+		t.Errorf("Expected the episode to stay unchanged, got %q", episode.Title)
+	}
+
+	if err := s.DeleteEpisode(ctx, otherID, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting the episode through another program, got %v", err)
+	}
+	if got, err := s.GetEpisode(ctx, programID, id); err != nil || got.Title != "الحلقة الأولى" {
+		t.Errorf("Expected the episode to be kept, got %v, %v", got, err)
+	}
+}
+
+func TestListPublishedEpisodes(t *testing.T) {
+	programID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	episode := func(title string, publishedAt pgtype.Timestamptz) *database.GetEpisodeRow {
+		return &database.GetEpisodeRow{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, ProgramID: programID, Title: title, PublishedAt: publishedAt}
+	}
+	published := episode("منشورة", pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true})
+	scheduled := episode("مجدولة", pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true})
+	draft := episode("مسودة", pgtype.Timestamptz{})
+	db := newFakeDB(t)
+	episodeDB(db, map[pgtype.UUID]*database.GetEpisodeRow{published.ID: published, scheduled.ID: scheduled, draft.ID: draft})
+	c := cache.New(time.Minute)
+	t.Cleanup(c.Close)
+	s := newTestEpisodeService(t, db, c)
+
+	episodes, err := s.ListPublishedEpisodes(context.Background(), programID.Bytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(episodes) != 1 || episodes[0].ID != published.ID {
+		t.Fatalf("Expected only the published episode, got %+v", episodes)
+	}
+
+	// The list is not cached, so a scheduled episode shows up once it is due.
+	scheduled.PublishedAt.Time = time.Now().Add(-time.Minute)
+	episodes, err = s.ListPublishedEpisodes(context.Background(), programID.Bytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Errorf("Expected the episode that went live to be listed, got %+v", episodes)
+	}
+}
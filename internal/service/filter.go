@@ -0,0 +1,398 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ProgramSort orders a list of programs.
+type ProgramSort string
+
+const (
+	// SortNewest lists the most recently created programs first. It is the
+	// default outside search.
+	SortNewest ProgramSort = "newest"
+	// SortOldest lists the earliest created programs first.
+	SortOldest ProgramSort = "oldest"
+	// SortTitle lists programs alphabetically by title.
+	SortTitle ProgramSort = "title"
+	// SortDuration lists the shortest programs first. Programs without a
+	// duration come before all others.
+	SortDuration ProgramSort = "duration"
+	// SortRelevance lists the best matches for the search query first. It is
+	// the default in search and is only available there.
+	SortRelevance ProgramSort = "relevance"
+)
+
+// ErrInvalidFilter is returned for a program filter or sort that cannot be
+// applied, such as an empty duration range.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// ProgramFilter narrows and orders ListPrograms and SearchPrograms. Zero
+// fields do not filter.
+type ProgramFilter struct {
+	// CategoryID keeps programs in the category or any of its subcategories.
+	CategoryID *uuid.UUID `json:"category_id"`
+	Language   string     `json:"language"`
+	// MinDuration and MaxDuration bound the duration in seconds, inclusive.
+	MinDuration   int       `json:"min_duration"`
+	MaxDuration   int       `json:"max_duration"`
+	CreatedAfter  time.Time `json:"created_after"`
+	CreatedBefore time.Time `json:"created_before"`
+	// Tags keeps programs carrying all of them.
+	Tags []string `json:"tags"`
+	// Status keeps programs in one stage of the publishing lifecycle. The
+	// public API always sets it to StatusPublished.
+	Status ProgramStatus `json:"status"`
+	// Sort defaults to SortRelevance in search and SortNewest elsewhere.
+	Sort ProgramSort `json:"sort"`
+}
+
+// ProgramSummary is a program as listed by ListPrograms and SearchPrograms.
+// Rank is how well it matched the search query, and is zero outside search.
+type ProgramSummary struct {
+	ID           pgtype.UUID        `db:"id"`
+	Title        string             `db:"title"`
+	Description  pgtype.Text        `db:"description"`
+	Language     pgtype.Text        `db:"language"`
+	Duration     pgtype.Int4        `db:"duration"`
+	CategoryID   pgtype.UUID        `db:"category_id"`
+	CategoryName pgtype.Text        `db:"category_name"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at"`
+	Status       string             `db:"status"`
+	PublishAt    pgtype.Timestamptz `db:"publish_at"`
+	Rank         float32            `db:"rank" json:",omitempty"`
+}
+
+func defaultSort(search bool) ProgramSort {
+	if search {
+		return SortRelevance
+	}
+	return SortNewest
+}
+
+// normalize checks f and puts it in canonical form, so equal filters share a
+// cache entry. The default sort is left empty.
+func (f ProgramFilter) normalize(search bool) (ProgramFilter, error) {
+	tags, err := normalizeTags(f.Tags)
+	if err != nil {
+		return ProgramFilter{}, err
+	}
+	f.Tags = tags
+
+	f.Language = strings.ToLower(strings.TrimSpace(f.Language))
+	if utf8.RuneCountInString(f.Language) > 50 {
+		return ProgramFilter{}, fmt.Errorf("%w: language is longer than 50 characters", ErrInvalidFilter)
+	}
+
+	if f.MinDuration < 0 || f.MaxDuration < 0 {
+		return ProgramFilter{}, fmt.Errorf("%w: durations cannot be negative", ErrInvalidFilter)
+	}
+	if f.MaxDuration > 0 && f.MinDuration > f.MaxDuration {
+		return ProgramFilter{}, fmt.Errorf("%w: min_duration is greater than max_duration", ErrInvalidFilter)
+	}
+
+	f.CreatedAfter, f.CreatedBefore = f.CreatedAfter.UTC(), f.CreatedBefore.UTC()
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		return ProgramFilter{}, fmt.Errorf("%w: created_after must be before created_before", ErrInvalidFilter)
+	}
+
+	switch f.Status {
+	case "", StatusDraft, StatusScheduled, StatusPublished, StatusArchived:
+	default:
+		return ProgramFilter{}, fmt.Errorf("%w: unknown status '%s'", ErrInvalidFilter, f.Status)
+	}
+
+	switch f.Sort {
+	case "", SortNewest, SortOldest, SortTitle, SortDuration:
+	case SortRelevance:
+		if !search {
+			return ProgramFilter{}, fmt.Errorf("%w: sorting by relevance needs a search query", ErrInvalidFilter)
+		}
+	default:
+		return ProgramFilter{}, fmt.Errorf("%w: unknown sort '%s'", ErrInvalidFilter, f.Sort)
+	}
+	if f.Sort == defaultSort(search) {
+		f.Sort = ""
+	}
+	return f, nil
+}
+
+// cacheKey encodes a normalized filter for use in cache keys. It is empty for
+// a filter that keeps everything in the default order.
+func (f ProgramFilter) cacheKey() string {
+	v := url.Values{}
+	if f.CategoryID != nil {
+		v.Set("category", f.CategoryID.String())
+	}
+	if f.Language != "" {
+		v.Set("language", f.Language)
+	}
+	if f.MinDuration > 0 {
+		v.Set("min_duration", strconv.Itoa(f.MinDuration))
+	}
+	if f.MaxDuration > 0 {
+		v.Set("max_duration", strconv.Itoa(f.MaxDuration))
+	}
+	if !f.CreatedAfter.IsZero() {
+		v.Set("created_after", f.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if !f.CreatedBefore.IsZero() {
+		v.Set("created_before", f.CreatedBefore.Format(time.RFC3339Nano))
+	}
+	for _, tag := range f.Tags {
+		v.Add("tag", tag)
+	}
+	if f.Status != "" {
+		v.Set("status", string(f.Status))
+	}
+	if f.Sort != "" {
+		v.Set("sort", string(f.Sort))
+	}
+	return v.Encode()
+}
+
+// dependsOnFields reports whether which programs pass f, or their order,
+// can change when a program is edited.
+func (f ProgramFilter) dependsOnFields() bool {
+	return f.CategoryID != nil || f.Language != "" || f.Status != "" || f.MinDuration > 0 || f.MaxDuration > 0 ||
+		!f.CreatedAfter.IsZero() || !f.CreatedBefore.IsZero() || (f.Sort != "" && f.Sort != SortOldest)
+}
+
+// programQuery builds the queries behind ListPrograms, SearchPrograms and
+// SearchTagFacets over programs p and their categories c. Only the fixed
+// fragments below are written into the SQL; every value from the request is
+// bound as an argument.
+type programQuery struct {
+	sort  ProgramSort
+	where []string
+	args  []any
+	// search is the placeholder of the search query, if there is one.
+	search string
+}
+
+// newProgramQuery matches the programs outside the trash that pass f and,
+// unless query is empty, match the normalized search query (see
+// internal/arabic).
+func newProgramQuery(query string, f ProgramFilter) *programQuery {
+	q := &programQuery{sort: f.Sort}
+	if q.sort == "" {
+		q.sort = defaultSort(query != "")
+	}
+	q.and("p.deleted_at IS NULL")
+
+	if query != "" {
+		q.search = q.arg(query) + "::text"
+		q.and(fmt.Sprintf(`(p.search_title LIKE '%%' || %[1]s || '%%'
+       OR p.search_description LIKE '%%' || %[1]s || '%%'
+       OR c.search_name LIKE '%%' || %[1]s || '%%'
+       OR %[1]s <%% p.search_title)`, q.search))
+	}
+	if f.CategoryID != nil {
+		q.and(fmt.Sprintf(`p.category_id IN (
+        WITH RECURSIVE tree AS (
+            SELECT id FROM categories WHERE id = %s::uuid
+            UNION
+            SELECT sub.id FROM categories sub JOIN tree ON sub.parent_id = tree.id
+        )
+        SELECT id FROM tree
+    )`, q.arg(*f.CategoryID)))
+	}
+	if f.Language != "" {
+		q.and(fmt.Sprintf("lower(p.language) = %s::text", q.arg(f.Language)))
+	}
+	if f.MinDuration > 0 {
+		q.and(fmt.Sprintf("p.duration >= %s::int", q.arg(f.MinDuration)))
+	}
+	if f.MaxDuration > 0 {
+		q.and(fmt.Sprintf("p.duration <= %s::int", q.arg(f.MaxDuration)))
+	}
+	if !f.CreatedAfter.IsZero() {
+		q.and(fmt.Sprintf("p.created_at > %s::timestamptz", q.arg(f.CreatedAfter)))
+	}
+	if !f.CreatedBefore.IsZero() {
+		q.and(fmt.Sprintf("p.created_at < %s::timestamptz", q.arg(f.CreatedBefore)))
+	}
+	if f.Status != "" {
+		q.and(fmt.Sprintf("p.status = %s::text", q.arg(string(f.Status))))
+	}
+	if len(f.Tags) > 0 {
+		tags := q.arg(f.Tags)
+		q.and(fmt.Sprintf(`p.id IN (
+        SELECT fpt.program_id
+        FROM program_tags fpt
+        JOIN tags ft ON ft.id = fpt.tag_id
+        WHERE ft.name = ANY(%[1]s::text[])
+        GROUP BY fpt.program_id
+        HAVING count(*) = cardinality(%[1]s::text[])
+    )`, tags))
+	}
+	return q
+}
+
+// arg binds v and returns its placeholder.
+func (q *programQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *programQuery) and(condition string) {
+	q.where = append(q.where, condition)
+}
+
+func (q *programQuery) whereClause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.where, "\n  AND ")
+}
+
+// rank scores search matches title > category > description, with trigram
+// word similarity on top of substring hits so near-misses still score.
+func (q *programQuery) rank() string {
+	if q.search == "" {
+		return "0"
+	}
+	return fmt.Sprintf(`CASE WHEN strpos(p.search_title, %[1]s) > 0 THEN 3 ELSE 0 END
+            + CASE WHEN strpos(c.search_name, %[1]s) > 0 THEN 2 ELSE 0 END
+            + CASE WHEN strpos(p.search_description, %[1]s) > 0 THEN 1 ELSE 0 END
+            + 3 * COALESCE(word_similarity(%[1]s, p.search_title), 0)
+            + 2 * COALESCE(word_similarity(%[1]s, c.search_name), 0)
+            + COALESCE(word_similarity(%[1]s, p.search_description), 0)`, q.search)
+}
+
+// programSort describes how to page through programs in one order.
+type programSort struct {
+	// order is the ORDER BY clause over the matched rows.
+	order string
+	// after keeps the rows that come after the cursor in this order.
+	after func(q *programQuery, ks keyset) (string, error)
+	// cursor points at a row.
+	cursor func(p ProgramSummary) cursor
+}
+
+var programSorts = map[ProgramSort]programSort{
+	SortNewest: {
+		order: "created_at DESC, id DESC",
+		after: func(q *programQuery, ks keyset) (string, error) {
+			return fmt.Sprintf("(created_at, id) < (%s::timestamptz, %s::uuid)", q.arg(ks.CreatedAt), q.arg(ks.ID)), nil
+		},
+		cursor: func(p ProgramSummary) cursor { return rowCursor(p.CreatedAt, p.ID) },
+	},
+	SortOldest: {
+		order: "created_at, id",
+		after: func(q *programQuery, ks keyset) (string, error) {
+			return fmt.Sprintf("(created_at, id) > (%s::timestamptz, %s::uuid)", q.arg(ks.CreatedAt), q.arg(ks.ID)), nil
+		},
+		cursor: func(p ProgramSummary) cursor { return rowCursor(p.CreatedAt, p.ID) },
+	},
+	SortTitle: {
+		order: "title, id",
+		after: func(q *programQuery, ks keyset) (string, error) {
+			return fmt.Sprintf("(title, id) > (%s::text, %s::uuid)", q.arg(ks.Key), q.arg(ks.ID)), nil
+		},
+		cursor: func(p ProgramSummary) cursor {
+			c := rowCursor(p.CreatedAt, p.ID)
+			c.Sort, c.Key = string(SortTitle), p.Title
+			return c
+		},
+	},
+	SortDuration: {
+		order: "COALESCE(duration, 0), id",
+		after: func(q *programQuery, ks keyset) (string, error) {
+			duration, err := strconv.Atoi(ks.Key)
+			if err != nil {
+				return "", fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+			}
+			return fmt.Sprintf("(COALESCE(duration, 0), id) > (%s::int, %s::uuid)", q.arg(duration), q.arg(ks.ID)), nil
+		},
+		cursor: func(p ProgramSummary) cursor {
+			c := rowCursor(p.CreatedAt, p.ID)
+			c.Sort, c.Key = string(SortDuration), strconv.Itoa(int(p.Duration.Int32))
+			return c
+		},
+	},
+	SortRelevance: {
+		order: "rank DESC, created_at DESC, id DESC",
+		after: func(q *programQuery, ks keyset) (string, error) {
+			if !ks.Rank.Valid {
+				return "", fmt.Errorf("%w: search cursor has no rank", ErrInvalidCursor)
+			}
+			return fmt.Sprintf("(rank, created_at, id) < (%s::real, %s::timestamptz, %s::uuid)",
+				q.arg(ks.Rank), q.arg(ks.CreatedAt), q.arg(ks.ID)), nil
+		},
+		cursor: func(p ProgramSummary) cursor {
+			c := rowCursor(p.CreatedAt, p.ID)
+			c.Rank = &p.Rank
+			return c
+		},
+	},
+}
+
+// listSQL selects the page of matching programs that ks points at.
+func (q *programQuery) listSQL(ks keyset) (string, error) {
+	sort := programSorts[q.sort]
+
+	after := ""
+	if ks.ID.Valid {
+		// Title and duration cursors carry their sort; the others carry none.
+		want := ""
+		if q.sort == SortTitle || q.sort == SortDuration {
+			want = string(q.sort)
+		}
+		if ks.Sort != want {
+			return "", fmt.Errorf("%w: cursor is for another sort", ErrInvalidCursor)
+		}
+		bound, err := sort.after(q, ks)
+		if err != nil {
+			return "", err
+		}
+		after = "WHERE " + bound
+	}
+
+	return fmt.Sprintf(`WITH matched AS (
+    SELECT
+        p.id,
+        p.title,
+        p.description,
+        p.language,
+        p.duration,
+        p.category_id,
+        c.name AS category_name,
+        p.created_at,
+        p.status,
+        p.publish_at,
+        (
+            %s
+        )::real AS rank
+    FROM programs p
+    LEFT JOIN categories c ON p.category_id = c.id
+    %s
+)
+SELECT id, title, description, language, duration, category_id, category_name, created_at, status, publish_at, rank
+FROM matched
+%s
+ORDER BY %s
+LIMIT %s`, q.rank(), q.whereClause(), after, sort.order, q.arg(ks.Limit)), nil
+}
+
+// facetsSQL counts the tags of every matching program, most common first.
+func (q *programQuery) facetsSQL(limit int) string {
+	return fmt.Sprintf(`SELECT t.name, count(*) AS programs
+FROM programs p
+LEFT JOIN categories c ON p.category_id = c.id
+JOIN program_tags pt ON pt.program_id = p.id
+JOIN tags t ON t.id = pt.tag_id
+%s
+GROUP BY t.name
+ORDER BY programs DESC, t.name
+LIMIT %s`, q.whereClause(), q.arg(limit))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProgramFilter_Normalize(t *testing.T) {
+	f, err := ProgramFilter{Language: " AR ", Tags: []string{"AI", "ai"}, Sort: SortNewest}.normalize(false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.Language != "ar" || len(f.Tags) != 1 || f.Sort != "" {
+		t.Errorf("Expected a lowercased language, deduped tags and the default sort cleared, got %+v", f)
+	}
+
+	if f, err := (ProgramFilter{Sort: SortRelevance}).normalize(true); err != nil || f.Sort != "" {
+		t.Errorf("Expected relevance to be the search default, got %q, %v", f.Sort, err)
+	}
+}
+
+func TestProgramFilter_NormalizeInvalid(t *testing.T) {
+	now := time.Now()
+	for name, f := range map[string]ProgramFilter{
+		"relevance outside search": {Sort: SortRelevance},
+		"unknown sort":             {Sort: "popularity"},
+		"negative duration":        {MinDuration: -1},
+		"empty duration range":     {MinDuration: 600, MaxDuration: 300},
+		"empty date range":         {CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)},
+		"unknown status":           {Status: "deleted"},
+	} {
+		if _, err := f.normalize(false); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", name, err)
+		}
+	}
+}
+
+func TestProgramFilter_CacheKey(t *testing.T) {
+	if key := (ProgramFilter{}).cacheKey(); key != "" {
+		t.Errorf("Expected no key for an empty filter, got %q", key)
+	}
+
+	category := uuid.New()
+	a, _ := ProgramFilter{CategoryID: &category, Tags: []string{"startups", "AI"}, Sort: SortTitle}.normalize(false)
+	b, _ := ProgramFilter{CategoryID: &category, Tags: []string{"ai", "Startups"}, Sort: SortTitle}.normalize(false)
+	if a.cacheKey() != b.cacheKey() {
+		t.Errorf("Expected equal filters to share a key, got %q and %q", a.cacheKey(), b.cacheKey())
+	}
+	if strings.Contains(a.cacheKey(), ":") {
+		t.Errorf("Expected the key to be free of cache key separators, got %q", a.cacheKey())
+	}
+}
+
+func TestProgramQuery_BindsValues(t *testing.T) {
+	language := "ar'; DROP TABLE programs; --"
+	f, err := ProgramFilter{Language: language, MinDuration: 60, Tags: []string{"ai"}}.normalize(false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ks, _ := PageRequest{Limit: 10}.keyset()
+	q := newProgramQuery("", f)
+	sql, err := q.listSQL(ks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Contains(sql, "DROP") {
+		t.Errorf("Expected values to be bound, not written into the SQL:\n%s", sql)
+	}
+	for _, want := range []string{"p.deleted_at IS NULL", "lower(p.language) = $1::text", "p.duration >= $2::int", "ANY($3::text[])", "ORDER BY created_at DESC, id DESC", "LIMIT $4"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("Expected SQL to contain %q:\n%s", want, sql)
+		}
+	}
+	if len(q.args) != 4 || q.args[0] != strings.ToLower(language) || q.args[3] != int32(11) {
+		t.Errorf("Unexpected arguments %v", q.args)
+	}
+}
+
+func TestProgramQuery_SortCursor(t *testing.T) {
+	row := ProgramSummary{Title: "أخبار"}
+	row.ID.Bytes, row.ID.Valid = uuid.New(), true
+	row.CreatedAt.Time, row.CreatedAt.Valid = time.Now().UTC(), true
+
+	next := programSorts[SortTitle].cursor(row).encode()
+	ks, err := PageRequest{Cursor: next}.keyset()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	q := newProgramQuery("", ProgramFilter{Sort: SortTitle})
+	sql, err := q.listSQL(ks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, "(title, id) > ($1::text, $2::uuid)") || q.args[0] != "أخبار" {
+		t.Errorf("Expected the page to start after the cursor's title, got %v:\n%s", q.args, sql)
+	}
+
+	if _, err := newProgramQuery("", ProgramFilter{}).listSQL(ks); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected a title cursor to be rejected when sorting by date, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+// ErrInvalidImport is returned when an external podcast lacks the data needed
+// to become a program.
+var ErrInvalidImport = errors.New("podcast cannot be imported")
+
+// Column limits of the programs and categories tables.
+const (
+	maxImportedTitleLength      = 255
+	maxImportedCategoryLength   = 100
+	maxImportedExternalIDLength = 255
+)
+
+// ImportProgram creates a program from an external podcast, or refreshes the
+// program previously imported from the same source and external ID. The
+// podcast's genre becomes the program's category, created if missing. A
+// refresh that changes the program is recorded as a revision by userID.
+func (s *ProgramService) ImportProgram(ctx context.Context, source string, podcast sources.Podcast, userID uuid.UUID) (*database.UpsertImportedProgramRow, error) {
+	if source == "" || podcast.ExternalID == "" {
+		return nil, fmt.Errorf("%w: missing source or external ID", ErrInvalidImport)
+	}
+	if utf8.RuneCountInString(podcast.ExternalID) > maxImportedExternalIDLength {
+		// Unlike the title, the external ID cannot be truncated: it is what
+		// finds the program again on the next import.
+		return nil, fmt.Errorf("%w: external ID is longer than %d characters", ErrInvalidImport, maxImportedExternalIDLength)
+	}
+	if podcast.Title == "" {
+		return nil, fmt.Errorf("%w: podcast has no title", ErrInvalidImport)
+	}
+
+	s.logger.Info("Importing program", "source", source, "external_id", podcast.ExternalID, "title", podcast.Title)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("Failed to begin import transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	previous, err := qtx.GetImportedProgramForUpdate(ctx, database.GetImportedProgramForUpdateParams{
+		Source:     pgtype.Text{String: source, Valid: true},
+		ExternalID: pgtype.Text{String: podcast.ExternalID, Valid: true},
+	})
+	reimport := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("Failed to get previously imported program", "source", source, "external_id", podcast.ExternalID, "error", err)
+		return nil, fmt.Errorf("failed to get previously imported program: %w", err)
+	}
+
+	var categoryID pgtype.UUID
+	if genre := truncate(podcast.Genre, maxImportedCategoryLength); genre != "" {
+		categoryID, err = qtx.UpsertCategoryByName(ctx, genre)
+		if err != nil {
+			s.logger.Error("Failed to get or create category for import", "genre", genre, "error", err)
+			return nil, fmt.Errorf("failed to get or create category: %w", err)
+		}
+	}
+
+	program, err := qtx.UpsertImportedProgram(ctx, database.UpsertImportedProgramParams{
+		Title:       truncate(podcast.Title, maxImportedTitleLength),
+		Description: pgtype.Text{String: podcast.Description, Valid: podcast.Description != ""},
+		CategoryID:  categoryID,
+		Duration:    pgtype.Int4{Int32: int32(podcast.Duration), Valid: podcast.Duration > 0},
+		Author:      pgtype.Text{String: podcast.Host, Valid: podcast.Host != ""},
+		ImageUrl:    pgtype.Text{String: podcast.ArtworkURL, Valid: podcast.ArtworkURL != ""},
+		ExternalUrl: pgtype.Text{String: podcast.ExternalURL, Valid: podcast.ExternalURL != ""},
+		Source:      pgtype.Text{String: source, Valid: true},
+		ExternalID:  pgtype.Text{String: podcast.ExternalID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to upsert imported program", "source", source, "external_id", podcast.ExternalID, "error", err)
+		return nil, fmt.Errorf("failed to import program: %w", err)
+	}
+
+	if reimport {
+		before := database.GetProgramForUpdateRow(previous)
+		after := programSnapshot(before)
+		after.Title = program.Title
+		after.Description = program.Description.String
+		after.CategoryID = nil
+		if program.CategoryID.Valid {
+			after.CategoryID = (*uuid.UUID)(&program.CategoryID.Bytes)
+		}
+		after.Duration = int(program.Duration.Int32)
+		after.Author = program.Author.String
+		after.ImageURL = program.ImageUrl.String
+		if err := s.recordRevision(ctx, qtx, before, after, userID, pgtype.Int4{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error("Failed to commit import transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// A re-import may have moved the program between categories or out of
+	// the trash, and the category itself may be new, so drop every affected
+	// listing.
+	cache.InvalidateTags(s.cache,
+		cache.ProgramTag(uuid.UUID(program.ID.Bytes).String()),
+		cache.CategoryTag(uuid.UUID(categoryID.Bytes).String()),
+		cache.TagProgramsList,
+		cache.TagSearch,
+		cache.TagCategories,
+		cache.TagTags,
+	)
+
+	s.logger.Info("Program imported successfully", "source", source, "external_id", podcast.ExternalID, "id", program.ID, "created", program.Inserted)
+	return &program, nil
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
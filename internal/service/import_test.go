@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+func TestImportProgram_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		podcast sources.Podcast
+	}{
+		{"no source", "", sources.Podcast{ExternalID: "1", Title: "Fnjan"}},
+		{"no external ID", "itunes", sources.Podcast{Title: "Fnjan"}},
+		{"no title", "itunes", sources.Podcast{ExternalID: "1"}},
+		{"external ID too long", "rss", sources.Podcast{ExternalID: "https://example.com/" + strings.Repeat("a", 240), Title: "Fnjan"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No query is registered, so reaching the database fails the test.
+			s := newTestProgramService(t, newFakeDB(t))
+
+			_, err := s.ImportProgram(context.Background(), tt.source, tt.podcast, uuid.New())
+			if !errors.Is(err, ErrInvalidImport) {
+				t.Errorf("Expected ErrInvalidImport, got %v", err)
+			}
+		})
+	}
+}
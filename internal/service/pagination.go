@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultPageLimit is used when a request does not specify a limit.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the largest page a client may request.
+	MaxPageLimit = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageRequest describes which page of a keyset-paginated list to return.
+type PageRequest struct {
+	Limit int `json:"limit" validate:"omitempty,min=1,max=100"`
+	// Cursor fits the longest sort key, a title of 255 characters of up to
+	// four bytes each, base64-encoded with the row's created_at and id.
+	Cursor string `json:"cursor" validate:"omitempty,max=1500"`
+}
+
+// Page holds one page of results and the cursor for the next one.
+// NextCursor is empty when there are no more results.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// cursor is the decoded form of a pagination cursor. Results are ordered by
+// (created_at, id) descending, so a cursor points at the last row returned.
+// Relevance-ordered lists (search) prefix the key with the row's rank. Lists
+// ordered by another column record the order in Sort and the row's value of
+// that column in Key. Key comes last, so it may contain the separator.
+type cursor struct {
+	Rank      *float32
+	Sort      string
+	Key       string
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c cursor) encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	if c.Rank != nil {
+		raw = strconv.FormatFloat(float64(*c.Rank), 'g', -1, 32) + "|" + raw
+	}
+	if c.Sort != "" {
+		raw += "|" + c.Sort + "|" + c.Key
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var c cursor
+	parts := strings.SplitN(string(raw), "|", 4)
+	switch len(parts) {
+	case 2:
+	case 4:
+		c.Sort, c.Key = parts[2], parts[3]
+		parts = parts[:2]
+	case 3:
+		r, err := strconv.ParseFloat(parts[0], 32)
+		if err != nil {
+			return cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+		rank := float32(r)
+		c.Rank = &rank
+		parts = parts[1:]
+	default:
+		return cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidCursor)
+	}
+	createdAt, id := parts[0], parts[1]
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	c.CreatedAt = t
+	c.ID = u
+	return c, nil
+}
+
+// keyset holds the query arguments derived from a PageRequest.
+type keyset struct {
+	Rank      pgtype.Float4
+	Sort      string
+	Key       string
+	CreatedAt pgtype.Timestamptz
+	ID        pgtype.UUID
+	// Limit is one more than the page size so we can tell whether a next page exists.
+	Limit int32
+	size  int
+}
+
+func (p PageRequest) keyset() (keyset, error) {
+	size := p.Limit
+	if size == 0 {
+		size = DefaultPageLimit
+	}
+
+	ks := keyset{Limit: int32(size + 1), size: size}
+	if p.Cursor == "" {
+		return ks, nil
+	}
+
+	c, err := decodeCursor(p.Cursor)
+	if err != nil {
+		return keyset{}, err
+	}
+	if c.Rank != nil {
+		ks.Rank = pgtype.Float4{Float32: *c.Rank, Valid: true}
+	}
+	ks.Sort, ks.Key = c.Sort, c.Key
+	ks.CreatedAt = pgtype.Timestamptz{Time: c.CreatedAt, Valid: true}
+	ks.ID = pgtype.UUID{Bytes: c.ID, Valid: true}
+	return ks, nil
+}
+
+// rowCursor builds a cursor from a row's (created_at, id) sort key.
+func rowCursor(createdAt pgtype.Timestamptz, id pgtype.UUID) cursor {
+	return cursor{CreatedAt: createdAt.Time, ID: id.Bytes}
+}
+
+// newPage trims the extra look-ahead row and builds the next cursor from the
+// last row of the page.
+func newPage[T any](rows []T, ks keyset, key func(T) cursor) *Page[T] {
+	page := &Page[T]{Items: rows}
+	if len(rows) <= ks.size {
+		return page
+	}
+
+	page.Items = rows[:ks.size]
+	page.NextCursor = key(page.Items[len(page.Items)-1]).encode()
+	return page
+}
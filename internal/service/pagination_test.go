@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	want := cursor{
+		CreatedAt: time.Date(2024, 1, 15, 10, 0, 0, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := decodeCursor(want.encode())
+	if err != nil {
+		t.Fatalf("Unexpected error decoding cursor: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestCursor_RankRoundTrip(t *testing.T) {
+	rank := float32(4.75)
+	want := cursor{Rank: &rank, CreatedAt: time.Now().UTC(), ID: uuid.New()}
+
+	got, err := decodeCursor(want.encode())
+	if err != nil {
+		t.Fatalf("Unexpected error decoding cursor: %v", err)
+	}
+	if got.Rank == nil || *got.Rank != rank {
+		t.Errorf("Expected rank %v, got %v", rank, got.Rank)
+	}
+
+	ks, err := PageRequest{Cursor: want.encode()}.keyset()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ks.Rank.Valid || ks.Rank.Float32 != rank {
+		t.Errorf("Expected keyset rank %v, got %+v", rank, ks.Rank)
+	}
+}
+
+func TestCursor_SortKeyRoundTrip(t *testing.T) {
+	want := cursor{Sort: "title", Key: "أخبار | تقنية", CreatedAt: time.Now().UTC(), ID: uuid.New()}
+
+	ks, err := PageRequest{Cursor: want.encode()}.keyset()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ks.Sort != want.Sort || ks.Key != want.Key || ks.ID.Bytes != want.ID {
+		t.Errorf("Expected sort %q and key %q, got %+v", want.Sort, want.Key, ks)
+	}
+}
+
+func TestCursor_LongestTitlePassesValidation(t *testing.T) {
+	// The longest title the schema allows, in a script of two-byte runes,
+	// ending in the cursor's separator.
+	title := strings.Repeat("برنامج ", 36) + "|أخ"
+	if n := utf8.RuneCountInString(title); n != 255 {
+		t.Fatalf("Expected a 255 character title, got %d", n)
+	}
+
+	req := PageRequest{Cursor: cursor{Sort: "title", Key: title, CreatedAt: time.Now().UTC(), ID: uuid.New()}.encode()}
+	if err := validator.New().Struct(req); err != nil {
+		t.Fatalf("Expected a title cursor of %d characters to be valid, got %v", len(req.Cursor), err)
+	}
+
+	ks, err := req.keyset()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ks.Sort != "title" || ks.Key != title {
+		t.Errorf("Expected the title to round-trip, got sort %q and key %q", ks.Sort, ks.Key)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, c := range []string{"not base64!", "Zm9v", cursor{}.encode()[:10]} {
+		if _, err := decodeCursor(c); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", c, err)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	type row struct {
+		createdAt time.Time
+		id        uuid.UUID
+	}
+	key := func(r row) cursor {
+		return rowCursor(pgtype.Timestamptz{Time: r.createdAt, Valid: true}, pgtype.UUID{Bytes: r.id, Valid: true})
+	}
+
+	ks, err := PageRequest{Limit: 2}.keyset()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ks.Limit != 3 {
+		t.Errorf("Expected query limit 3, got %d", ks.Limit)
+	}
+
+	now := time.Now().UTC()
+	rows := []row{
+		{now, uuid.New()},
+		{now.Add(-time.Minute), uuid.New()},
+		{now.Add(-2 * time.Minute), uuid.New()},
+	}
+
+	page := newPage(rows, ks, key)
+	if len(page.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(page.Items))
+	}
+
+	next, err := decodeCursor(page.NextCursor)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding next cursor: %v", err)
+	}
+	if next.ID != rows[1].id {
+		t.Errorf("Expected next cursor to point at the last returned row")
+	}
+
+	last := newPage(rows[:2], ks, key)
+	if last.NextCursor != "" {
+		t.Errorf("Expected no next cursor on the last page, got %q", last.NextCursor)
+	}
+}
+
+func TestPageRequest_DefaultLimit(t *testing.T) {
+	ks, err := PageRequest{}.keyset()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ks.size != DefaultPageLimit {
+		t.Errorf("Expected default page size %d, got %d", DefaultPageLimit, ks.size)
+	}
+	if ks.CreatedAt.Valid || ks.ID.Valid {
+		t.Error("Expected first page to have no keyset bounds")
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// ErrInvalidPatch is returned for a merge patch that is not a JSON object, or
+// that sets an unknown field or a field to a value of the wrong type.
+var ErrInvalidPatch = errors.New("invalid patch")
+
+type PatchProgramRequest struct {
+	ID uuid.UUID `validate:"required"`
+	// Patch is a JSON Merge Patch (RFC 7396) of the fields of
+	// UpdateProgramRequest. Fields it leaves out keep their value and fields
+	// it sets to null are cleared.
+	Patch json.RawMessage
+	// UserID and IfMatch are as in UpdateProgramRequest.
+	UserID  uuid.UUID
+	IfMatch []int32
+}
+
+// PatchProgram changes some of a program's fields. The patch is applied to
+// the program's current fields and the result is saved like an
+// UpdateProgramRequest. Only the fields the patch sets or clears are held
+// to UpdateProgramRequest's rules, so clearing a required field fails but a
+// stored value that predates the rules is kept as long as it is left out.
+func (s *ProgramService) PatchProgram(ctx context.Context, req PatchProgramRequest) (*database.UpdateProgramRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid patch program request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	var patch any
+	if err := json.Unmarshal(req.Patch, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: the patch must be a JSON object", ErrInvalidPatch)
+	}
+	fields := patchedFields(members)
+
+	s.logger.Info("Patching program", "id", req.ID)
+
+	return s.updateProgram(ctx, req.ID, pgtype.Int4{}, func(current ProgramSnapshot) (UpdateProgramRequest, error) {
+		patched, err := patchSnapshot(current, patch)
+		if err != nil {
+			return UpdateProgramRequest{}, err
+		}
+		update := patched.updateRequest(req.ID)
+		update.UserID = req.UserID
+		update.IfMatch = req.IfMatch
+		if len(fields) == 0 {
+			return update, nil
+		}
+		if err := s.validator.StructPartial(update, fields...); err != nil {
+			s.logger.Error("Invalid patched program", "error", err)
+			return UpdateProgramRequest{}, fmt.Errorf("validation failed: %w", err)
+		}
+		return update, nil
+	})
+}
+
+// patchedFields returns the names of the UpdateProgramRequest fields that
+// the members of a patch set or clear.
+func patchedFields(members map[string]any) []string {
+	var fields []string
+	t := reflect.TypeFor[UpdateProgramRequest]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if _, ok := members[name]; ok {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields
+}
+
+// patchSnapshot applies a merge patch to a program's fields. Cleared fields
+// take their zero value, which for strings stands for NULL.
+func patchSnapshot(current ProgramSnapshot, patch any) (ProgramSnapshot, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return ProgramSnapshot{}, fmt.Errorf("failed to encode program: %w", err)
+	}
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return ProgramSnapshot{}, fmt.Errorf("failed to decode program: %w", err)
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return ProgramSnapshot{}, fmt.Errorf("failed to encode patched program: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	var patched ProgramSnapshot
+	if err := dec.Decode(&patched); err != nil {
+		return ProgramSnapshot{}, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+	return patched, nil
+}
+
+// mergePatch applies patch to target as RFC 7396 describes: an object patch
+// merges into target member by member, removing those set to null, and any
+// other patch replaces target.
+func mergePatch(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	merged, ok := target.(map[string]any)
+	if !ok {
+		merged = map[string]any{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+		merged[name] = mergePatch(merged[name], value)
+	}
+	return merged
+}
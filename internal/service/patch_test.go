@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7396, appendix A.
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		var target, patch, want any
+		for _, v := range []struct {
+			doc string
+			dst *any
+		}{{tt.target, &target}, {tt.patch, &patch}, {tt.want, &want}} {
+			if err := json.Unmarshal([]byte(v.doc), v.dst); err != nil {
+				t.Fatalf("Invalid test document %s: %v", v.doc, err)
+			}
+		}
+
+		if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %s patched with %s to be %s, got %v", tt.target, tt.patch, tt.want, got)
+		}
+	}
+}
+
+func TestPatchSnapshot(t *testing.T) {
+	categoryID := uuid.New()
+	current := ProgramSnapshot{
+		Title:       "Title",
+		Description: "A description with a typo",
+		CategoryID:  &categoryID,
+		Language:    "ar",
+		Duration:    1800,
+		Author:      "Author",
+	}
+
+	patch := map[string]any{"description": nil, "duration": float64(2400)}
+	got, err := patchSnapshot(current, patch)
+	if err != nil {
+		t.Fatalf("Expected the patch to apply, got %v", err)
+	}
+
+	want := current
+	want.Description = ""
+	want.Duration = 2400
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	if got, err := patchSnapshot(current, map[string]any{"category_id": nil}); err != nil || got.CategoryID != nil {
+		t.Errorf("Expected a null category_id to clear the category, got %v, %v", got.CategoryID, err)
+	}
+}
+
+func TestPatchSnapshot_Invalid(t *testing.T) {
+	for _, patch := range []map[string]any{
+		{"titel": "Title"},
+		{"duration": "long"},
+		{"duration": 1.5},
+		{"locked": "yes"},
+	} {
+		if _, err := patchSnapshot(ProgramSnapshot{Title: "Title"}, patch); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("Expected ErrInvalidPatch for %v, got %v", patch, err)
+		}
+	}
+}
+
+func TestPatchProgram_LegacyFields(t *testing.T) {
+	tests := []struct {
+		patch string
+		valid bool
+	}{
+		{`{"description":"وصف جديد"}`, true},
+		{`{"title":"Tech Talk Weekly","locked":true}`, true},
+		{`{}`, true},
+		{`{"title":"x"}`, false},
+		{`{"category_id":null}`, false},
+		{`{"duration":0}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			db := newFakeDB(t)
+			// Imported before titles were limited to 100 characters, and
+			// without a category or a duration.
+			p := newStoredProgram()
+			p.Title = strings.Repeat("ب", 150)
+			p.CategoryID = pgtype.UUID{}
+			p.Duration = pgtype.Int4{}
+			storedProgram(db, p)
+			s := newTestProgramService(t, db)
+
+			_, err := s.PatchProgram(context.Background(), PatchProgramRequest{ID: p.ID.Bytes, Patch: json.RawMessage(tt.patch)})
+			if tt.valid && err != nil {
+				t.Fatalf("Expected fields the patch leaves out not to be validated, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("Expected the patched field to be validated")
+			}
+		})
+	}
+}
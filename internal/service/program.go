@@ -12,7 +12,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/khatibomar/gomania/internal/arabic"
 	"github.com/khatibomar/gomania/internal/cache"
 	"github.com/khatibomar/gomania/internal/database"
 )
@@ -35,12 +35,56 @@ func IsErrAlreadyExists(err error) bool {
 // ErrNotFound is returned when a resource is not found.
 var ErrNotFound = errors.New("resource not found")
 
+// ErrEditConflict is returned when a resource changed since the version an
+// update was based on.
+var ErrEditConflict = errors.New("edit conflict")
+
+// IsValidationError checks if an error was caused by an invalid request.
+func IsValidationError(err error) bool {
+	var target validator.ValidationErrors
+	return errors.As(err, &target)
+}
+
+// DB runs the services' queries and transactions. *pgxpool.Pool implements
+// it.
+type DB interface {
+	database.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type ProgramService struct {
-	db        *pgxpool.Pool
+	db        DB
 	q         *database.Queries
 	logger    *slog.Logger
 	validator *validator.Validate
 	cache     cache.Cache
+
+	programs      *cache.Typed[*database.GetProgramRow]
+	programPages  *cache.Typed[*Page[ProgramSummary]]
+	searchPages   *cache.Typed[*Page[ProgramSummary]]
+	categoryPages *cache.Typed[*Page[database.GetProgramsByCategoryRow]]
+	categories    *cache.Typed[[]database.GetCategoriesRow]
+	tags          *cache.Typed[[]database.ListTagsRow]
+	facets        *cache.Typed[[]TagFacet]
+
+	// publishWake nudges RunPublishScheduler when a program is scheduled.
+	publishWake chan struct{}
+}
+
+// searchCacheTTL is shorter than the default because search keys are free
+// text: there are many of them and each is rarely reused.
+const searchCacheTTL = 5 * time.Minute
+
+// Cached values must be registered so out-of-process caches can decode them.
+func init() {
+	cache.Register(
+		&database.GetProgramRow{},
+		&Page[ProgramSummary]{},
+		&Page[database.GetProgramsByCategoryRow]{},
+		[]database.GetCategoriesRow{},
+		[]database.ListTagsRow{},
+		[]TagFacet{},
+	)
 }
 
 type CreateProgramRequest struct {
@@ -49,6 +93,13 @@ type CreateProgramRequest struct {
 	CategoryID  uuid.UUID `json:"category_id" validate:"required"`
 	Language    string    `json:"language" validate:"required,min=2,max=50"`
 	Duration    int       `json:"duration" validate:"required,gt=0"`
+	Author      string    `json:"author" validate:"omitempty,max=255"`
+	OwnerEmail  string    `json:"owner_email" validate:"omitempty,email,max=255"`
+	ImageURL    string    `json:"image_url" validate:"omitempty,url,max=2048"`
+	Locked      bool      `json:"locked"`
+	// Status defaults to draft. Scheduling needs a PublishAt in the future.
+	Status    ProgramStatus `json:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+	PublishAt *time.Time    `json:"publish_at"`
 }
 
 type UpdateProgramRequest struct {
@@ -58,27 +109,100 @@ type UpdateProgramRequest struct {
 	CategoryID  uuid.UUID `json:"category_id" validate:"required"`
 	Language    string    `json:"language" validate:"required,min=2,max=50"`
 	Duration    int       `json:"duration" validate:"required,gt=0"`
+	Author      string    `json:"author" validate:"omitempty,max=255"`
+	OwnerEmail  string    `json:"owner_email" validate:"omitempty,email,max=255"`
+	ImageURL    string    `json:"image_url" validate:"omitempty,url,max=2048"`
+	Locked      bool      `json:"locked"`
+	// UserID is the CMS user making the change, recorded in its revision.
+	UserID uuid.UUID `json:"-"`
+	// IfMatch, unless nil, lists the versions of the program the update may
+	// apply to. An empty list matches no version.
+	IfMatch []int32 `json:"-"`
+}
+
+// ListProgramsRequest selects a page of the programs that pass a filter.
+type ListProgramsRequest struct {
+	ProgramFilter
+	PageRequest
 }
 
 type SearchRequest struct {
 	Query string `json:"query" validate:"required,min=1,max=100"`
+	ProgramFilter
+	PageRequest
+}
+
+// CategoryProgramsRequest selects a page of the programs in a category.
+type CategoryProgramsRequest struct {
+	CategoryID uuid.UUID `json:"category_id" validate:"required"`
+	// IncludeDescendants also lists the programs of every subcategory.
+	IncludeDescendants bool `json:"include_descendants"`
+	PageRequest
 }
 
 type CategoryRequest struct {
 	Name string `json:"name" validate:"required,min=2,max=50"`
+	// ParentID nests the category under another; nil makes it top-level.
+	ParentID *uuid.UUID `json:"parent_id"`
 }
 
-func NewProgramService(db *pgxpool.Pool, logger *slog.Logger) *ProgramService {
+func NewProgramService(db DB, logger *slog.Logger) *ProgramService {
 	return NewProgramServiceWithCache(db, logger, 15*time.Minute)
 }
 
-func NewProgramServiceWithCache(db *pgxpool.Pool, logger *slog.Logger, cacheTTL time.Duration) *ProgramService {
+func NewProgramServiceWithCache(db DB, logger *slog.Logger, cacheTTL time.Duration) *ProgramService {
+	return NewProgramServiceWithCacheBackend(db, logger, cache.NewMemoryCache(cacheTTL))
+}
+
+// NewProgramServiceWithCacheBackend creates the service on top of an existing
+// cache, which may be shared with other services.
+func NewProgramServiceWithCacheBackend(db DB, logger *slog.Logger, c cache.Cache) *ProgramService {
+	return NewProgramServiceWithLoader(db, logger, cache.NewLoader(c, cache.LoaderOptions{Logger: logger}))
+}
+
+// NewProgramServiceWithLoader creates the service on top of a read-through
+// loader, which may be shared with other services.
+func NewProgramServiceWithLoader(db DB, logger *slog.Logger, loader *cache.Loader) *ProgramService {
 	return &ProgramService{
 		db:        db,
 		q:         database.New(db),
 		logger:    logger,
 		validator: validator.New(),
-		cache:     cache.NewMemoryCache(cacheTTL),
+		cache:     loader.Cache(),
+
+		programs: cache.NewTyped(loader, cache.TypedOptions[*database.GetProgramRow]{
+			Tags: func(p *database.GetProgramRow) []string {
+				return []string{cache.CategoryTag(uuid.UUID(p.CategoryID.Bytes).String())}
+			},
+		}),
+		programPages: cache.NewTyped(loader, cache.TypedOptions[*Page[ProgramSummary]]{
+			Tags: pageTags(func(p ProgramSummary) pgtype.UUID { return p.ID }),
+		}),
+		searchPages: cache.NewTyped(loader, cache.TypedOptions[*Page[ProgramSummary]]{
+			TTL:  searchCacheTTL,
+			Tags: pageTags(func(p ProgramSummary) pgtype.UUID { return p.ID }),
+		}),
+		categoryPages: cache.NewTyped(loader, cache.TypedOptions[*Page[database.GetProgramsByCategoryRow]]{
+			Tags: pageTags(func(p database.GetProgramsByCategoryRow) pgtype.UUID { return p.ID }),
+		}),
+		categories: cache.NewTyped(loader, cache.TypedOptions[[]database.GetCategoriesRow]{}),
+		tags:       cache.NewTyped(loader, cache.TypedOptions[[]database.ListTagsRow]{}),
+		facets: cache.NewTyped(loader, cache.TypedOptions[[]TagFacet]{
+			TTL: searchCacheTTL,
+		}),
+		publishWake: make(chan struct{}, 1),
+	}
+}
+
+// pageTags tags a cached page with every program on it, so changing or
+// deleting any of them drops the page.
+func pageTags[T any](id func(T) pgtype.UUID) func(*Page[T]) []string {
+	return func(p *Page[T]) []string {
+		tags := make([]string, len(p.Items))
+		for i, item := range p.Items {
+			tags[i] = cache.ProgramTag(uuid.UUID(id(item).Bytes).String())
+		}
+		return tags
 	}
 }
 
@@ -88,7 +212,16 @@ func (s *ProgramService) CreateProgram(ctx context.Context, req CreateProgramReq
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	s.logger.Info("Creating new program", "title", req.Title)
+	status := req.Status
+	if status == "" {
+		status = StatusDraft
+	}
+	at, err := publishAt(status, req.PublishAt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Creating new program", "title", req.Title, "status", status)
 
 	program, err := s.q.CreateProgram(ctx, database.CreateProgramParams{
 		Title:       req.Title,
@@ -96,6 +229,12 @@ func (s *ProgramService) CreateProgram(ctx context.Context, req CreateProgramReq
 		CategoryID:  pgtype.UUID{Bytes: req.CategoryID, Valid: true},
 		Language:    pgtype.Text{String: req.Language, Valid: req.Language != ""},
 		Duration:    pgtype.Int4{Int32: int32(req.Duration), Valid: req.Duration > 0},
+		Author:      pgtype.Text{String: req.Author, Valid: req.Author != ""},
+		OwnerEmail:  pgtype.Text{String: req.OwnerEmail, Valid: req.OwnerEmail != ""},
+		ImageUrl:    pgtype.Text{String: req.ImageURL, Valid: req.ImageURL != ""},
+		Locked:      req.Locked,
+		Status:      string(status),
+		PublishAt:   at,
 	})
 
 	if err != nil {
@@ -108,30 +247,33 @@ func (s *ProgramService) CreateProgram(ctx context.Context, req CreateProgramReq
 		return nil, fmt.Errorf("failed to create program: %w", err)
 	}
 
-	// Invalidate relevant cache entries after successful creation
-	s.cache.Delete(cache.ProgramsListKey())
-	s.cache.InvalidatePattern(cache.KeyPatternProgramsSearch)          // Clears all search results
-	s.cache.Delete(cache.ProgramsCategoryKey(req.CategoryID.String())) // Clears specific category list
+	// A new program can appear on any listing that includes its category, and
+	// in any search
+	cache.InvalidateTags(s.cache, cache.TagProgramsList, cache.TagSearch, cache.CategoryTag(req.CategoryID.String()))
+	if status == StatusScheduled {
+		s.wakePublishScheduler()
+	}
 
 	s.logger.Info("Program created successfully", "title", req.Title, "id", program.ID)
 	return &program, nil
 }
 
 func (s *ProgramService) GetProgram(ctx context.Context, id uuid.UUID) (*database.GetProgramRow, error) {
-	cacheKey := cache.ProgramKey(id.String())
+	return s.programs.Load(ctx, cache.ProgramKey(id.String()), func(ctx context.Context) (*database.GetProgramRow, error) {
+		return s.getProgram(ctx, id)
+	}, cache.ProgramTag(id.String()))
+}
 
-	if cached, found := s.cache.Get(cacheKey); found {
-		s.logger.Debug("Program found in cache", "id", id)
-		if program, ok := cached.(*database.GetProgramRow); ok {
-			return program, nil
-		}
-		// Invalid type in cache, remove it
-		s.logger.Warn("Invalid cache entry type for program, removing", "id", id)
-		s.cache.Delete(cacheKey)
-	}
+// GetProgramForEdit reads a program past the cache, for editors to base an
+// update on. A cached copy may have been read just before the last update
+// and cached just after it, so its Version would fail every If-Match check
+// until it expired.
+func (s *ProgramService) GetProgramForEdit(ctx context.Context, id uuid.UUID) (*database.GetProgramRow, error) {
+	return s.getProgram(ctx, id)
+}
 
-	pgUUID := pgtype.UUID{Bytes: id, Valid: true}
-	program, err := s.q.GetProgram(ctx, pgUUID)
+func (s *ProgramService) getProgram(ctx context.Context, id uuid.UUID) (*database.GetProgramRow, error) {
+	program, err := s.q.GetProgram(ctx, pgtype.UUID{Bytes: id, Valid: true})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			s.logger.Info("Program not found in DB", "id", id)
@@ -140,42 +282,73 @@ func (s *ProgramService) GetProgram(ctx context.Context, id uuid.UUID) (*databas
 		s.logger.Error("Failed to get program from DB", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get program: %w", err)
 	}
-
-	s.cache.Set(cacheKey, &program)
-	s.logger.Debug("Program cached", "id", id)
-
 	return &program, nil
 }
 
+// UpdateProgram replaces a program's fields and records the change as a new
+// revision.
 func (s *ProgramService) UpdateProgram(ctx context.Context, req UpdateProgramRequest) (*database.UpdateProgramRow, error) {
 	if err := s.validator.Struct(req); err != nil {
 		s.logger.Error("Invalid update program request", "error", err)
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Get the program's current state, especially its CategoryID, before updating.
-	// This uses the cached GetProgram method.
-	existingProgram, err := s.GetProgram(ctx, req.ID)
+	return s.updateProgram(ctx, req.ID, pgtype.Int4{}, func(ProgramSnapshot) (UpdateProgramRequest, error) {
+		return req, nil
+	})
+}
+
+// updateProgram sets the fields of program id to those edit returns given
+// the current ones. The program stays locked in between, so an edit based on
+// its current fields cannot undo a concurrent update. rolledBackTo is the
+// revision a rollback restores, or NULL for other updates.
+//
+// The new fields only have to fit the programs table; edit applies any
+// stricter rules to the values it sets.
+func (s *ProgramService) updateProgram(ctx context.Context, id uuid.UUID, rolledBackTo pgtype.Int4, edit func(current ProgramSnapshot) (UpdateProgramRequest, error)) (*database.UpdateProgramRow, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("Failed to begin update program transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	before, err := qtx.GetProgramForUpdate(ctx, pgtype.UUID{Bytes: id, Valid: true})
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			s.logger.Info("Program not found for update", "id", req.ID)
-			// Return a specific error indicating the resource to update was not found
-			return nil, fmt.Errorf("%w: program with ID '%s' not found for update", ErrNotFound, req.ID.String())
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Program not found for update", "id", id)
+			return nil, fmt.Errorf("%w: program with ID '%s' not found for update", ErrNotFound, id.String())
 		}
-		s.logger.Error("Failed to get program details before update", "id", req.ID, "error", err)
-		return nil, fmt.Errorf("failed to get program details before update: %w", err)
+		s.logger.Error("Failed to get program for update", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to get program: %w", err)
+	}
+
+	req, err := edit(programSnapshot(before))
+	if err != nil {
+		return nil, err
+	}
+	req.ID = id
+	if err := s.validator.Struct(req.columns()); err != nil {
+		s.logger.Error("Program fields do not fit the programs table", "id", id, "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
-	oldProgramCategoryID := existingProgram.CategoryID // This is pgtype.UUID
 
 	s.logger.Info("Updating program", "id", req.ID, "title", req.Title)
 
-	updatedProgramData, err := s.q.UpdateProgram(ctx, database.UpdateProgramParams{
+	updatedProgramData, err := qtx.UpdateProgram(ctx, database.UpdateProgramParams{
 		ID:          pgtype.UUID{Bytes: req.ID, Valid: true},
 		Title:       req.Title,
 		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
-		CategoryID:  pgtype.UUID{Bytes: req.CategoryID, Valid: true},
+		CategoryID:  pgtype.UUID{Bytes: req.CategoryID, Valid: req.CategoryID != uuid.Nil},
 		Language:    pgtype.Text{String: req.Language, Valid: req.Language != ""},
 		Duration:    pgtype.Int4{Int32: int32(req.Duration), Valid: req.Duration > 0},
+		Author:      pgtype.Text{String: req.Author, Valid: req.Author != ""},
+		OwnerEmail:  pgtype.Text{String: req.OwnerEmail, Valid: req.OwnerEmail != ""},
+		ImageUrl:    pgtype.Text{String: req.ImageURL, Valid: req.ImageURL != ""},
+		Locked:      req.Locked,
+		IfMatch:     req.IfMatch,
 	})
 
 	if err != nil {
@@ -184,176 +357,218 @@ func (s *ProgramService) UpdateProgram(ctx context.Context, req UpdateProgramReq
 			s.logger.Warn("Attempted to update program to a conflicting state", "id", req.ID, "title", req.Title, "error", err)
 			return nil, &ErrAlreadyExists{Message: fmt.Sprintf("cannot update program, title '%s' may already exist or conflict", req.Title)}
 		}
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // 23503 is foreign_key_violation
+			s.logger.Info("Category not found for program update", "id", req.ID, "category_id", req.CategoryID)
+			return nil, fmt.Errorf("%w: category with ID '%s' not found", ErrNotFound, req.CategoryID.String())
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
-			s.logger.Info("Program not found during DB update operation (race condition or already deleted)", "id", req.ID)
-			return nil, fmt.Errorf("%w: program with ID '%s' not found for update", ErrNotFound, req.ID.String())
+			// The program is locked, so it is still there: only the version
+			// check can have failed.
+			s.logger.Info("Program changed since the version the update is based on", "id", req.ID, "version", before.Version, "if_match", req.IfMatch)
+			return nil, fmt.Errorf("%w: program with ID '%s' is at version %d", ErrEditConflict, req.ID.String(), before.Version)
 		}
 		s.logger.Error("Failed to update program in DB", "id", req.ID, "error", err)
 		return nil, fmt.Errorf("failed to update program: %w", err)
 	}
 
-	// Invalidate relevant cache entries after successful update
-	s.cache.Delete(cache.ProgramKey(req.ID.String()))         // Specific program cache
-	s.cache.Delete(cache.ProgramsListKey())                   // General list of programs
-	s.cache.InvalidatePattern(cache.KeyPatternProgramsSearch) // All search results
-
-	// Invalidate cache for the new/current category
-	s.cache.Delete(cache.ProgramsCategoryKey(req.CategoryID.String()))
-
-	// If the category ID changed, invalidate the cache for the old category as well
-	if oldProgramCategoryID.Valid {
-		oldCatUUID, convErr := uuid.FromBytes(oldProgramCategoryID.Bytes[:])
-		if convErr == nil {
-			if oldCatUUID != req.CategoryID {
-				s.logger.Debug("Program category changed, invalidating old category cache", "old_category_id", oldCatUUID.String())
-				s.cache.Delete(cache.ProgramsCategoryKey(oldCatUUID.String()))
-			}
-		} else {
-			s.logger.Error("Failed to convert old category pgtype.UUID to uuid.UUID for comparison", "error", convErr)
-		}
+	if err := s.recordRevision(ctx, qtx, before, req.snapshot(), req.UserID, rolledBackTo); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error("Failed to commit update program transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// Entries showing the program, including its old category's pages, carry
+	// its tag. The program may also have joined a new category or started
+	// matching other searches.
+	cache.InvalidateTags(s.cache, cache.ProgramTag(req.ID.String()), cache.CategoryTag(req.CategoryID.String()), cache.TagSearch)
+
 	s.logger.Info("Program updated successfully", "id", updatedProgramData.ID, "title", updatedProgramData.Title)
 	return &updatedProgramData, nil
 }
 
+// DeleteProgram moves a program to the trash, from which RestoreProgram can
+// bring it back until PurgeTrash deletes it for good.
 func (s *ProgramService) DeleteProgram(ctx context.Context, id uuid.UUID) error {
 	s.logger.Info("Deleting program", "id", id)
 
-	var categoryIDToDeleteFromCache pgtype.UUID
-	// Fetch program details to get CategoryID for targeted cache invalidation.
-	programToDelete, err := s.GetProgram(ctx, id)
+	rows, err := s.q.SoftDeleteProgram(ctx, pgtype.UUID{Bytes: id, Valid: true})
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			s.logger.Info("Program not found before deletion attempt (already deleted or never existed)", "id", id)
-			// Program is already gone, or never existed. DB delete will confirm.
-			// categoryIDToDeleteFromCache will remain invalid/zero.
-		} else {
-			// For other errors fetching program details, return the error.
-			s.logger.Error("Failed to get program details before deletion", "id", id, "error", err)
-			return fmt.Errorf("failed to get program details before deletion: %w", err)
-		}
-	} else if programToDelete != nil {
-		categoryIDToDeleteFromCache = programToDelete.CategoryID
-	}
-
-	pgUUID := pgtype.UUID{Bytes: id, Valid: true}
-	dbErr := s.q.DeleteProgram(ctx, pgUUID)
-	if dbErr != nil {
-		if errors.Is(dbErr, pgx.ErrNoRows) {
-			s.logger.Info("Program not found in DB during deletion (confirmed already deleted)", "id", id)
-			// Fall through to cache invalidation, as the state is "item does not exist".
-		} else {
-			s.logger.Error("Failed to delete program from DB", "id", id, "error", dbErr)
-			return fmt.Errorf("failed to delete program: %w", dbErr)
-		}
+		s.logger.Error("Failed to delete program from DB", "id", id, "error", err)
+		return fmt.Errorf("failed to delete program: %w", err)
 	}
-
-	// Invalidate relevant cache entries
-	s.cache.Delete(cache.ProgramKey(id.String()))
-	s.cache.Delete(cache.ProgramsListKey())
-	s.cache.InvalidatePattern(cache.KeyPatternProgramsSearch)
-
-	if categoryIDToDeleteFromCache.Valid {
-		categoryUUID, convErr := uuid.FromBytes(categoryIDToDeleteFromCache.Bytes[:])
-		if convErr == nil {
-			s.logger.Debug("Invalidating category cache for deleted program", "category_id", categoryUUID.String())
-			s.cache.Delete(cache.ProgramsCategoryKey(categoryUUID.String()))
-		} else {
-			s.logger.Error("Failed to convert category pgtype.UUID to uuid.UUID for cache key on delete", "error", convErr)
-		}
+	if rows == 0 {
+		s.logger.Info("Program not found for deletion", "id", id)
+		return fmt.Errorf("%w: program with ID '%s' not found", ErrNotFound, id.String())
 	}
 
+	// Every entry that showed the program carries its tag. Pages after it are
+	// keyed by cursor, so they stay valid. Tag counts leave out the trash.
+	cache.InvalidateTags(s.cache, cache.ProgramTag(id.String()), cache.TagTags)
+
 	s.logger.Info("Program deleted successfully", "id", id)
 	return nil
 }
 
-func (s *ProgramService) ListPrograms(ctx context.Context) ([]database.ListProgramsRow, error) {
-	cacheKey := cache.ProgramsListKey()
+func (s *ProgramService) ListPrograms(ctx context.Context, req ListProgramsRequest) (*Page[ProgramSummary], error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid list programs request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
 
-	if cached, found := s.cache.Get(cacheKey); found {
-		s.logger.Debug("Programs list found in cache")
-		if programs, ok := cached.([]database.ListProgramsRow); ok {
-			return programs, nil
-		}
-		// Invalid type in cache, remove it
-		s.logger.Warn("Invalid cache entry type for programs list, removing")
-		s.cache.Delete(cacheKey)
+	filter, err := req.ProgramFilter.normalize(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ks, err := req.keyset()
+	if err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("Listing all programs")
-	programs, err := s.q.ListPrograms(ctx)
+	q := newProgramQuery("", filter)
+	sql, err := q.listSQL(ks)
 	if err != nil {
-		s.logger.Error("Failed to list programs", "error", err)
-		return nil, fmt.Errorf("failed to list programs: %w", err)
+		return nil, err
 	}
 
-	s.cache.Set(cacheKey, programs)
-	s.logger.Debug("Programs list cached")
+	// New programs can join any listing. Filtered listings also change when a
+	// program is edited, which invalidates TagSearch.
+	tags := append(tagFilterTags(filter.Tags), cache.TagProgramsList)
+	if filter.dependsOnFields() {
+		tags = append(tags, cache.TagSearch)
+	}
 
-	s.logger.Info("Successfully listed programs", "count", len(programs))
-	return programs, nil
+	return s.programPages.Load(ctx, cache.ProgramsListKey(ks.size, req.Cursor, filter.cacheKey()), func(ctx context.Context) (*Page[ProgramSummary], error) {
+		s.logger.Info("Listing programs", "limit", ks.size, "cursor", req.Cursor, "filter", filter.cacheKey())
+		rows, err := s.queryPrograms(ctx, sql, q.args)
+		if err != nil {
+			s.logger.Error("Failed to list programs", "error", err)
+			return nil, fmt.Errorf("failed to list programs: %w", err)
+		}
+
+		programs := newPage(rows, ks, programSorts[q.sort].cursor)
+
+		s.logger.Info("Successfully listed programs", "count", len(programs.Items))
+		return programs, nil
+	}, tags...)
 }
 
-func (s *ProgramService) SearchPrograms(ctx context.Context, req SearchRequest) ([]database.SearchProgramsRow, error) {
+// searchFilter validates a search and returns its normalized query and
+// filter. An empty query means nothing can match.
+func (s *ProgramService) searchFilter(req SearchRequest) (string, ProgramFilter, error) {
 	if err := s.validator.Struct(req); err != nil {
 		s.logger.Error("Invalid search request", "error", err)
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return "", ProgramFilter{}, fmt.Errorf("validation failed: %w", err)
 	}
 
-	cacheKey := cache.ProgramsSearchKey(req.Query)
+	filter, err := req.ProgramFilter.normalize(true)
+	if err != nil {
+		return "", ProgramFilter{}, err
+	}
 
-	if cached, found := s.cache.Get(cacheKey); found {
-		s.logger.Debug("Search results found in cache", "query", req.Query)
-		if programs, ok := cached.([]database.SearchProgramsRow); ok {
-			return programs, nil
-		}
-		// Invalid type in cache, remove it
-		s.logger.Warn("Invalid cache entry type for search results, removing", "query", req.Query)
-		s.cache.Delete(cacheKey)
+	// Search runs against the normalized columns, so fold the query the same
+	// way; this also lets spelling variants share a cache entry.
+	return arabic.Normalize(req.Query), filter, nil
+}
+
+func (s *ProgramService) SearchPrograms(ctx context.Context, req SearchRequest) (*Page[ProgramSummary], error) {
+	query, filter, err := s.searchFilter(req)
+	if err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("Searching programs", "query", req.Query)
+	ks, err := req.keyset()
+	if err != nil {
+		return nil, err
+	}
 
-	programs, err := s.q.SearchPrograms(ctx, pgtype.Text{String: req.Query, Valid: true})
+	q := newProgramQuery(query, filter)
+	sql, err := q.listSQL(ks)
 	if err != nil {
-		s.logger.Error("Failed to search programs", "query", req.Query, "error", err)
-		return nil, fmt.Errorf("failed to search programs: %w", err)
+		return nil, err
 	}
 
-	s.cache.Set(cacheKey, programs)
-	s.logger.Debug("Search results cached", "query", req.Query)
+	if query == "" {
+		return &Page[ProgramSummary]{Items: []ProgramSummary{}}, nil
+	}
 
-	s.logger.Info("Search completed", "query", req.Query, "found", len(programs))
-	return programs, nil
+	return s.searchPages.Load(ctx, cache.ProgramsSearchKey(query, ks.size, req.Cursor, filter.cacheKey()), func(ctx context.Context) (*Page[ProgramSummary], error) {
+		s.logger.Info("Searching programs", "query", req.Query, "filter", filter.cacheKey(), "limit", ks.size, "cursor", req.Cursor)
+		rows, err := s.queryPrograms(ctx, sql, q.args)
+		if err != nil {
+			s.logger.Error("Failed to search programs", "query", req.Query, "error", err)
+			return nil, fmt.Errorf("failed to search programs: %w", err)
+		}
+
+		programs := newPage(rows, ks, programSorts[q.sort].cursor)
+
+		s.logger.Info("Search completed", "query", req.Query, "found", len(programs.Items))
+		return programs, nil
+	}, append(tagFilterTags(filter.Tags), cache.TagSearch)...)
 }
 
-func (s *ProgramService) GetProgramsByCategory(ctx context.Context, categoryID uuid.UUID) ([]database.GetProgramsByCategoryRow, error) {
-	cacheKey := cache.ProgramsCategoryKey(categoryID.String())
+// queryPrograms runs a query built by programQuery.listSQL.
+func (s *ProgramService) queryPrograms(ctx context.Context, sql string, args []any) ([]ProgramSummary, error) {
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[ProgramSummary])
+}
 
-	if cached, found := s.cache.Get(cacheKey); found {
-		s.logger.Debug("Programs by category found in cache", "category_id", categoryID)
-		if programs, ok := cached.([]database.GetProgramsByCategoryRow); ok {
-			return programs, nil
-		}
-		// Invalid type in cache, remove it
-		s.logger.Warn("Invalid cache entry type for programs by category, removing", "category_id", categoryID)
-		s.cache.Delete(cacheKey)
+func (s *ProgramService) GetProgramsByCategory(ctx context.Context, req CategoryProgramsRequest) (*Page[database.GetProgramsByCategoryRow], error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid programs by category request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	pgUUID := pgtype.UUID{Bytes: categoryID, Valid: true}
-	s.logger.Info("Getting programs by category", "category_id", categoryID)
-	programs, err := s.q.GetProgramsByCategory(ctx, pgUUID)
+	ks, err := req.keyset()
 	if err != nil {
-		s.logger.Error("Failed to get programs by category", "category_id", categoryID, "error", err)
-		return nil, fmt.Errorf("failed to get programs by category: %w", err)
+		return nil, err
+	}
+
+	categoryID := req.CategoryID
+	cacheKey := cache.ProgramsCategoryKey(categoryID.String(), ks.size, req.Cursor)
+	tags := []string{cache.CategoryTag(categoryID.String())}
+	if req.IncludeDescendants {
+		cacheKey = cache.ProgramsCategoryTreeKey(categoryID.String(), ks.size, req.Cursor)
+
+		// The page changes with the programs of every subcategory, and with
+		// the shape of the tree itself.
+		categories, err := s.GetCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, cache.TagCategories)
+		for _, id := range descendantIDs(categories, categoryID) {
+			tags = append(tags, cache.CategoryTag(id.String()))
+		}
 	}
 
-	s.cache.Set(cacheKey, programs)
-	s.logger.Debug("Programs by category cached", "category_id", categoryID)
+	return s.categoryPages.Load(ctx, cacheKey, func(ctx context.Context) (*Page[database.GetProgramsByCategoryRow], error) {
+		s.logger.Info("Getting programs by category", "category_id", categoryID, "include_descendants", req.IncludeDescendants, "limit", ks.size, "cursor", req.Cursor)
+		rows, err := s.q.GetProgramsByCategory(ctx, database.GetProgramsByCategoryParams{
+			CategoryID:         pgtype.UUID{Bytes: categoryID, Valid: true},
+			IncludeDescendants: req.IncludeDescendants,
+			CursorCreatedAt:    ks.CreatedAt,
+			CursorID:           ks.ID,
+			PageLimit:          ks.Limit,
+		})
+		if err != nil {
+			s.logger.Error("Failed to get programs by category", "category_id", categoryID, "error", err)
+			return nil, fmt.Errorf("failed to get programs by category: %w", err)
+		}
 
-	s.logger.Info("Successfully fetched programs by category", "category_id", categoryID, "count", len(programs))
-	return programs, nil
+		programs := newPage(rows, ks, func(p database.GetProgramsByCategoryRow) cursor {
+			return rowCursor(p.CreatedAt, p.ID)
+		})
+
+		s.logger.Info("Successfully fetched programs by category", "category_id", categoryID, "count", len(programs.Items))
+		return programs, nil
+	}, tags...)
 }
 
 // Category management
@@ -365,46 +580,40 @@ func (s *ProgramService) CreateCategory(ctx context.Context, req CategoryRequest
 
 	s.logger.Info("Creating new category", "name", req.Name)
 
-	category, err := s.q.CreateCategory(ctx, req.Name)
+	category, err := s.q.CreateCategory(ctx, database.CreateCategoryParams{
+		Name:     req.Name,
+		ParentID: nullableUUID(req.ParentID),
+	})
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // 23505 is unique_violation
 			s.logger.Warn("Attempted to create a category that already exists", "name", req.Name, "error", err)
 			return nil, &ErrAlreadyExists{Message: fmt.Sprintf("category with name '%s' already exists", req.Name)}
 		}
+		if parentErr := categoryParentError(err, req.ParentID); parentErr != nil {
+			s.logger.Info("Invalid parent for new category", "name", req.Name, "error", err)
+			return nil, parentErr
+		}
 		s.logger.Error("Failed to create category", "name", req.Name, "error", err)
 		return nil, fmt.Errorf("failed to create category: %w", err)
 	}
 
-	s.cache.Delete(cache.CategoriesListKey())
+	cache.InvalidateTags(s.cache, cache.TagCategories)
 
 	s.logger.Info("Category created successfully", "name", req.Name, "id", category.ID)
 	return &category, nil
 }
 
 func (s *ProgramService) GetCategories(ctx context.Context) ([]database.GetCategoriesRow, error) {
-	cacheKey := cache.CategoriesListKey()
-
-	if cached, found := s.cache.Get(cacheKey); found {
-		s.logger.Debug("Categories found in cache")
-		if categories, ok := cached.([]database.GetCategoriesRow); ok {
-			return categories, nil
+	return s.categories.Load(ctx, cache.CategoriesListKey(), func(ctx context.Context) ([]database.GetCategoriesRow, error) {
+		s.logger.Info("Getting all categories")
+		categories, err := s.q.GetCategories(ctx)
+		if err != nil {
+			s.logger.Error("Failed to get categories", "error", err)
+			return nil, fmt.Errorf("failed to get categories: %w", err)
 		}
-		// Invalid type in cache, remove it
-		s.logger.Warn("Invalid cache entry type for categories list, removing")
-		s.cache.Delete(cacheKey)
-	}
-
-	s.logger.Info("Getting all categories")
-	categories, err := s.q.GetCategories(ctx)
-	if err != nil {
-		s.logger.Error("Failed to get categories", "error", err)
-		return nil, fmt.Errorf("failed to get categories: %w", err)
-	}
-
-	s.cache.Set(cacheKey, categories)
-	s.logger.Debug("Categories cached")
 
-	s.logger.Info("Successfully fetched categories", "count", len(categories))
-	return categories, nil
+		s.logger.Info("Successfully fetched categories", "count", len(categories))
+		return categories, nil
+	}, cache.TagCategories)
 }
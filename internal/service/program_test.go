@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// storedProgram makes db hold a single program and answer the queries that
+// update it the way Postgres would, bumping its version on every update.
+func storedProgram(db *fakeDB, p *database.GetProgramForUpdateRow) {
+	var revisions int32
+	db.on("GetProgramForUpdate", func(args []any) ([]any, error) {
+		if args[0] != p.ID {
+			return nil, nil
+		}
+		return []any{*p}, nil
+	})
+	db.on("UpdateProgram", func(args []any) ([]any, error) {
+		arg := database.UpdateProgramParams{
+			Title: args[0].(string), Description: args[1].(pgtype.Text), CategoryID: args[2].(pgtype.UUID),
+			Language: args[3].(pgtype.Text), Duration: args[4].(pgtype.Int4), Author: args[5].(pgtype.Text),
+			OwnerEmail: args[6].(pgtype.Text), ImageUrl: args[7].(pgtype.Text), Locked: args[8].(bool),
+			ID: args[9].(pgtype.UUID), IfMatch: args[10].([]int32),
+		}
+		if arg.ID != p.ID || (arg.IfMatch != nil && !slices.Contains(arg.IfMatch, p.Version)) {
+			return nil, nil
+		}
+		p.Title, p.Description, p.CategoryID, p.Language, p.Duration = arg.Title, arg.Description, arg.CategoryID, arg.Language, arg.Duration
+		p.Author, p.OwnerEmail, p.ImageUrl, p.Locked = arg.Author, arg.OwnerEmail, arg.ImageUrl, arg.Locked
+		p.Version++
+		return []any{database.UpdateProgramRow{
+			ID: p.ID, Title: p.Title, Description: p.Description, Language: p.Language, Duration: p.Duration,
+			Author: p.Author, OwnerEmail: p.OwnerEmail, ImageUrl: p.ImageUrl, Locked: p.Locked, Version: p.Version,
+		}}, nil
+	})
+	db.on("HasProgramRevisions", func([]any) ([]any, error) { return []any{revisions > 0}, nil })
+	db.on("CreateProgramRevision", func([]any) ([]any, error) {
+		revisions++
+		return []any{revisions}, nil
+	})
+}
+
+func newStoredProgram() *database.GetProgramForUpdateRow {
+	return &database.GetProgramForUpdateRow{
+		ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Title:      "Tech Talk",
+		CategoryID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Language:   pgtype.Text{String: "ar", Valid: true},
+		Duration:   pgtype.Int4{Int32: 1800, Valid: true},
+		Version:    3,
+	}
+}
+
+func TestUpdateProgram_IfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  []int32
+		conflict bool
+	}{
+		{"no If-Match", nil, false},
+		{"current version", []int32{3}, false},
+		{"one of several", []int32{1, 3}, false},
+		{"old version", []int32{2}, true},
+		{"no usable tag", []int32{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			p := newStoredProgram()
+			storedProgram(db, p)
+			s := newTestProgramService(t, db)
+
+			req := programSnapshot(*p).updateRequest(p.ID.Bytes)
+			req.Title = "Tech Talk Weekly"
+			req.IfMatch = tt.ifMatch
+
+			updated, err := s.UpdateProgram(context.Background(), req)
+			if tt.conflict {
+				if !errors.Is(err, ErrEditConflict) {
+					t.Fatalf("Expected ErrEditConflict, got %v", err)
+				}
+				if p.Title != "Tech Talk" || db.commits != 0 {
+					t.Errorf("Expected the program to stay unchanged, got %q after %d commits", p.Title, db.commits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if updated.Version != 4 || updated.Title != "Tech Talk Weekly" {
+				t.Errorf("Expected version 4 titled %q, got version %d titled %q", req.Title, updated.Version, updated.Title)
+			}
+		})
+	}
+}
+
+func TestGetProgramForEdit_SkipsCache(t *testing.T) {
+	db := newFakeDB(t)
+	program := database.GetProgramRow{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Title: "Tech Talk", Version: 1}
+	db.on("GetProgram", func([]any) ([]any, error) { return []any{program}, nil })
+	s := newTestProgramService(t, db)
+	id := uuid.UUID(program.ID.Bytes)
+
+	if _, err := s.GetProgram(context.Background(), id); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// An update the cache missed, as when a fill races the update's
+	// invalidation.
+	program.Version = 2
+
+	cached, err := s.GetProgram(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cached.Version != 1 {
+		t.Fatalf("Expected the cached program at version 1, got %d", cached.Version)
+	}
+
+	fresh, err := s.GetProgramForEdit(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fresh.Version != 2 {
+		t.Errorf("Expected the program for editing at version 2, got %d", fresh.Version)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// ProgramStatus is where a program is in its publishing lifecycle. Only
+// published programs are shown on the public API.
+type ProgramStatus string
+
+const (
+	// StatusDraft is a program still being prepared. New programs start here.
+	StatusDraft ProgramStatus = "draft"
+	// StatusScheduled is a program the scheduler publishes at its publish_at.
+	StatusScheduled ProgramStatus = "scheduled"
+	// StatusPublished is a public program.
+	StatusPublished ProgramStatus = "published"
+	// StatusArchived is a program taken off the public API.
+	StatusArchived ProgramStatus = "archived"
+)
+
+// ErrInvalidPublishAt is returned when publish_at does not suit the status,
+// such as scheduling a program in the past.
+var ErrInvalidPublishAt = errors.New("invalid publish_at")
+
+type SetProgramStatusRequest struct {
+	ID     uuid.UUID     `json:"id" validate:"required"`
+	Status ProgramStatus `json:"status" validate:"required,oneof=draft scheduled published archived"`
+	// PublishAt is required to schedule a program. When publishing, it
+	// defaults to now and may be backdated.
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// publishAt returns the publish_at to store for a program moving to status.
+// Only scheduled and published programs have one.
+func publishAt(status ProgramStatus, at *time.Time, now time.Time) (pgtype.Timestamptz, error) {
+	switch status {
+	case StatusScheduled:
+		if at == nil || !at.After(now) {
+			return pgtype.Timestamptz{}, fmt.Errorf("%w: scheduling a program needs a publish_at in the future", ErrInvalidPublishAt)
+		}
+		return pgtype.Timestamptz{Time: *at, Valid: true}, nil
+	case StatusPublished:
+		if at == nil {
+			return pgtype.Timestamptz{Time: now, Valid: true}, nil
+		}
+		if at.After(now) {
+			return pgtype.Timestamptz{}, fmt.Errorf("%w: schedule the program to publish it in the future", ErrInvalidPublishAt)
+		}
+		return pgtype.Timestamptz{Time: *at, Valid: true}, nil
+	default:
+		return pgtype.Timestamptz{}, nil
+	}
+}
+
+// SetProgramStatus moves a program through the publishing lifecycle.
+func (s *ProgramService) SetProgramStatus(ctx context.Context, req SetProgramStatusRequest) (*database.SetProgramStatusRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid program status request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	at, err := publishAt(req.Status, req.PublishAt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Setting program status", "id", req.ID, "status", req.Status, "publish_at", at.Time)
+
+	program, err := s.q.SetProgramStatus(ctx, database.SetProgramStatusParams{
+		ID:        pgtype.UUID{Bytes: req.ID, Valid: true},
+		Status:    string(req.Status),
+		PublishAt: at,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Program not found for status change", "id", req.ID)
+			return nil, fmt.Errorf("%w: program with ID '%s' not found", ErrNotFound, req.ID.String())
+		}
+		s.logger.Error("Failed to set program status", "id", req.ID, "error", err)
+		return nil, fmt.Errorf("failed to set program status: %w", err)
+	}
+
+	// Public listings filter on status, so they carry TagSearch.
+	cache.InvalidateTags(s.cache, cache.ProgramTag(req.ID.String()), cache.TagSearch)
+	if req.Status == StatusScheduled {
+		s.wakePublishScheduler()
+	}
+
+	s.logger.Info("Program status set", "id", req.ID, "status", program.Status)
+	return &program, nil
+}
+
+// PublishDuePrograms publishes every scheduled program whose publish_at has
+// passed and returns how many there were.
+func (s *ProgramService) PublishDuePrograms(ctx context.Context) (int, error) {
+	programs, err := s.q.PublishDuePrograms(ctx)
+	if err != nil {
+		s.logger.Error("Failed to publish due programs", "error", err)
+		return 0, fmt.Errorf("failed to publish due programs: %w", err)
+	}
+	if len(programs) == 0 {
+		return 0, nil
+	}
+
+	tags := []string{cache.TagSearch}
+	for _, p := range programs {
+		tags = append(tags, cache.ProgramTag(uuid.UUID(p.ID.Bytes).String()))
+	}
+	cache.InvalidateTags(s.cache, tags...)
+
+	s.logger.Info("Published scheduled programs", "count", len(programs))
+	return len(programs), nil
+}
+
+// RunPublishScheduler publishes scheduled programs as they come due, until
+// ctx is done. It wakes when the next program is due, or after interval at
+// the latest to pick up programs scheduled through other replicas. Running
+// it on every replica is safe: each program is published once.
+func (s *ProgramService) RunPublishScheduler(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting publish scheduler", "interval", interval)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping publish scheduler")
+			return
+		case <-s.publishWake:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		wait := interval
+		if _, err := s.PublishDuePrograms(ctx); err == nil {
+			wait = s.nextPublishWait(ctx, interval)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// nextPublishWait returns how long to sleep until the next scheduled program
+// is due, capped at interval. The floor of a second keeps a clock that runs
+// ahead of the database's from spinning the scheduler.
+func (s *ProgramService) nextPublishWait(ctx context.Context, interval time.Duration) time.Duration {
+	next, err := s.q.NextScheduledPublish(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get next scheduled publish", "error", err)
+		return interval
+	}
+	if !next.Valid {
+		return interval
+	}
+	return min(max(time.Until(next.Time), time.Second), interval)
+}
+
+// wakePublishScheduler makes a running scheduler recompute its wait, so a
+// program scheduled sooner than its next check is still published on time.
+func (s *ProgramService) wakePublishScheduler() {
+	select {
+	case s.publishWake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPublishAt(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	if at, err := publishAt(StatusScheduled, &later, now); err != nil || !at.Valid || !at.Time.Equal(later) {
+		t.Errorf("Expected a scheduled program to keep its publish_at, got %v, %v", at, err)
+	}
+	if at, err := publishAt(StatusPublished, nil, now); err != nil || !at.Time.Equal(now) {
+		t.Errorf("Expected publishing to default to now, got %v, %v", at, err)
+	}
+	if at, err := publishAt(StatusPublished, &earlier, now); err != nil || !at.Time.Equal(earlier) {
+		t.Errorf("Expected publishing to allow a past publish_at, got %v, %v", at, err)
+	}
+	for _, status := range []ProgramStatus{StatusDraft, StatusArchived} {
+		if at, err := publishAt(status, &later, now); err != nil || at.Valid {
+			t.Errorf("Expected no publish_at for a %s program, got %v, %v", status, at, err)
+		}
+	}
+}
+
+func TestPublishAt_Invalid(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	for name, tc := range map[string]struct {
+		status ProgramStatus
+		at     *time.Time
+	}{
+		"scheduled without publish_at": {StatusScheduled, nil},
+		"scheduled in the past":        {StatusScheduled, &earlier},
+		"published in the future":      {StatusPublished, &later},
+	} {
+		if _, err := publishAt(tc.status, tc.at, now); !errors.Is(err, ErrInvalidPublishAt) {
+			t.Errorf("%s: expected ErrInvalidPublishAt, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// ProgramSnapshot holds the fields of a program that UpdateProgram sets, as
+// kept in each revision. Empty strings stand for NULL, as in
+// UpdateProgramRequest.
+type ProgramSnapshot struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	CategoryID  *uuid.UUID `json:"category_id"`
+	Language    string     `json:"language"`
+	Duration    int        `json:"duration"`
+	Author      string     `json:"author"`
+	OwnerEmail  string     `json:"owner_email"`
+	ImageURL    string     `json:"image_url"`
+	Locked      bool       `json:"locked"`
+}
+
+// FieldChange is a field's value before and after a change.
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// ProgramChanges maps the JSON names of the changed fields of a program to
+// their change.
+type ProgramChanges map[string]FieldChange
+
+// ProgramRevision is a recorded change to a program. Snapshot is only set
+// when a single revision is requested.
+type ProgramRevision struct {
+	Revision  int32              `json:"revision"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	UserEmail pgtype.Text        `json:"user_email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// RolledBackTo is the revision this one restored, if it was a rollback.
+	RolledBackTo pgtype.Int4      `json:"rolled_back_to"`
+	Changes      ProgramChanges   `json:"changes"`
+	Snapshot     *ProgramSnapshot `json:"snapshot,omitempty"`
+}
+
+// RevisionDiff lists the fields that differ between two revisions.
+type RevisionDiff struct {
+	From    int32          `json:"from"`
+	To      int32          `json:"to"`
+	Changes ProgramChanges `json:"changes"`
+}
+
+type RollbackProgramRequest struct {
+	ID       uuid.UUID `json:"id" validate:"required"`
+	Revision int32     `json:"revision" validate:"required,gt=0"`
+	// UserID is the CMS user making the change, recorded in its revision.
+	UserID uuid.UUID `json:"-"`
+}
+
+// programColumns holds the fields of a program that the programs table
+// limits. Values stored before, such as those of an earlier revision, only
+// have to fit the table again: the stricter rules of UpdateProgramRequest
+// may be newer than they are, and a category may have been removed since.
+type programColumns struct {
+	Title      string `validate:"max=255"`
+	Language   string `validate:"max=10"`
+	Author     string `validate:"max=255"`
+	OwnerEmail string `validate:"max=255"`
+}
+
+func (r UpdateProgramRequest) columns() programColumns {
+	return programColumns{Title: r.Title, Language: r.Language, Author: r.Author, OwnerEmail: r.OwnerEmail}
+}
+
+func (r UpdateProgramRequest) snapshot() ProgramSnapshot {
+	var categoryID *uuid.UUID
+	if r.CategoryID != uuid.Nil {
+		id := r.CategoryID
+		categoryID = &id
+	}
+	return ProgramSnapshot{
+		Title:       r.Title,
+		Description: r.Description,
+		CategoryID:  categoryID,
+		Language:    r.Language,
+		Duration:    r.Duration,
+		Author:      r.Author,
+		OwnerEmail:  r.OwnerEmail,
+		ImageURL:    r.ImageURL,
+		Locked:      r.Locked,
+	}
+}
+
+func programSnapshot(p database.GetProgramForUpdateRow) ProgramSnapshot {
+	var categoryID *uuid.UUID
+	if p.CategoryID.Valid {
+		id := uuid.UUID(p.CategoryID.Bytes)
+		categoryID = &id
+	}
+	return ProgramSnapshot{
+		Title:       p.Title,
+		Description: p.Description.String,
+		CategoryID:  categoryID,
+		Language:    p.Language.String,
+		Duration:    int(p.Duration.Int32),
+		Author:      p.Author.String,
+		OwnerEmail:  p.OwnerEmail.String,
+		ImageURL:    p.ImageUrl.String,
+		Locked:      p.Locked,
+	}
+}
+
+// updateRequest is the update that restores the snapshot.
+func (p ProgramSnapshot) updateRequest(id uuid.UUID) UpdateProgramRequest {
+	req := UpdateProgramRequest{
+		ID:          id,
+		Title:       p.Title,
+		Description: p.Description,
+		Language:    p.Language,
+		Duration:    p.Duration,
+		Author:      p.Author,
+		OwnerEmail:  p.OwnerEmail,
+		ImageURL:    p.ImageURL,
+		Locked:      p.Locked,
+	}
+	if p.CategoryID != nil {
+		req.CategoryID = *p.CategoryID
+	}
+	return req
+}
+
+// fields maps the JSON name of every field to a comparable value.
+func (p ProgramSnapshot) fields() map[string]any {
+	var categoryID any
+	if p.CategoryID != nil {
+		categoryID = p.CategoryID.String()
+	}
+	return map[string]any{
+		"title":       p.Title,
+		"description": p.Description,
+		"category_id": categoryID,
+		"language":    p.Language,
+		"duration":    p.Duration,
+		"author":      p.Author,
+		"owner_email": p.OwnerEmail,
+		"image_url":   p.ImageURL,
+		"locked":      p.Locked,
+	}
+}
+
+// diffSnapshots returns the fields that differ between from and to. It is
+// empty, not nil, when they are equal.
+func diffSnapshots(from, to ProgramSnapshot) ProgramChanges {
+	changes := ProgramChanges{}
+	toFields := to.fields()
+	for name, value := range from.fields() {
+		if value != toFields[name] {
+			changes[name] = FieldChange{From: value, To: toFields[name]}
+		}
+	}
+	return changes
+}
+
+// recordRevision records a program changing from before to after, unless
+// nothing changed. The first recorded change also records the program as it
+// was before, so that it can be rolled back to.
+func (s *ProgramService) recordRevision(ctx context.Context, qtx *database.Queries, before database.GetProgramForUpdateRow, after ProgramSnapshot, userID uuid.UUID, rolledBackTo pgtype.Int4) error {
+	previous := programSnapshot(before)
+	changes := diffSnapshots(previous, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	hasRevisions, err := qtx.HasProgramRevisions(ctx, before.ID)
+	if err != nil {
+		s.logger.Error("Failed to check program revisions", "id", before.ID, "error", err)
+		return fmt.Errorf("failed to check program revisions: %w", err)
+	}
+	if !hasRevisions {
+		if err := s.createRevision(ctx, qtx, database.CreateProgramRevisionParams{
+			ProgramID: before.ID,
+			CreatedAt: before.UpdatedAt,
+		}, previous, ProgramChanges{}); err != nil {
+			return err
+		}
+	}
+
+	return s.createRevision(ctx, qtx, database.CreateProgramRevisionParams{
+		ProgramID:    before.ID,
+		UserID:       pgtype.UUID{Bytes: userID, Valid: userID != uuid.Nil},
+		RolledBackTo: rolledBackTo,
+	}, after, changes)
+}
+
+func (s *ProgramService) createRevision(ctx context.Context, qtx *database.Queries, arg database.CreateProgramRevisionParams, snapshot ProgramSnapshot, changes ProgramChanges) error {
+	var err error
+	if arg.Snapshot, err = json.Marshal(snapshot); err != nil {
+		return fmt.Errorf("failed to encode program snapshot: %w", err)
+	}
+	if arg.Changes, err = json.Marshal(changes); err != nil {
+		return fmt.Errorf("failed to encode program changes: %w", err)
+	}
+
+	revision, err := qtx.CreateProgramRevision(ctx, arg)
+	if err != nil {
+		s.logger.Error("Failed to create program revision", "id", arg.ProgramID, "error", err)
+		return fmt.Errorf("failed to create program revision: %w", err)
+	}
+
+	s.logger.Info("Program revision recorded", "id", arg.ProgramID, "revision", revision, "fields", len(changes))
+	return nil
+}
+
+// ListProgramRevisions returns a program's revisions, newest first, without
+// their snapshots.
+func (s *ProgramService) ListProgramRevisions(ctx context.Context, programID uuid.UUID) ([]ProgramRevision, error) {
+	if _, err := s.GetProgram(ctx, programID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.ListProgramRevisions(ctx, pgtype.UUID{Bytes: programID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to list program revisions", "program_id", programID, "error", err)
+		return nil, fmt.Errorf("failed to list program revisions: %w", err)
+	}
+
+	revisions := make([]ProgramRevision, len(rows))
+	for i, row := range rows {
+		revisions[i] = ProgramRevision{
+			Revision:     row.Revision,
+			UserID:       row.UserID,
+			UserEmail:    row.UserEmail,
+			CreatedAt:    row.CreatedAt,
+			RolledBackTo: row.RolledBackTo,
+		}
+		if err := json.Unmarshal(row.Changes, &revisions[i].Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode changes of revision %d: %w", row.Revision, err)
+		}
+	}
+	return revisions, nil
+}
+
+// GetProgramRevision returns one of a program's revisions with its snapshot.
+func (s *ProgramService) GetProgramRevision(ctx context.Context, programID uuid.UUID, revision int32) (*ProgramRevision, error) {
+	if _, err := s.GetProgram(ctx, programID); err != nil {
+		return nil, err
+	}
+
+	row, err := s.q.GetProgramRevision(ctx, database.GetProgramRevisionParams{
+		ProgramID: pgtype.UUID{Bytes: programID, Valid: true},
+		Revision:  revision,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: program with ID '%s' has no revision %d", ErrNotFound, programID.String(), revision)
+		}
+		s.logger.Error("Failed to get program revision", "program_id", programID, "revision", revision, "error", err)
+		return nil, fmt.Errorf("failed to get program revision: %w", err)
+	}
+
+	r := &ProgramRevision{
+		Revision:     row.Revision,
+		UserID:       row.UserID,
+		UserEmail:    row.UserEmail,
+		CreatedAt:    row.CreatedAt,
+		RolledBackTo: row.RolledBackTo,
+		Snapshot:     &ProgramSnapshot{},
+	}
+	if err := json.Unmarshal(row.Changes, &r.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode changes of revision %d: %w", revision, err)
+	}
+	if err := json.Unmarshal(row.Snapshot, r.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of revision %d: %w", revision, err)
+	}
+	return r, nil
+}
+
+// DiffProgramRevisions compares the program's fields at two revisions.
+func (s *ProgramService) DiffProgramRevisions(ctx context.Context, programID uuid.UUID, from, to int32) (*RevisionDiff, error) {
+	a, err := s.GetProgramRevision(ctx, programID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.GetProgramRevision(ctx, programID, to)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{From: from, To: to, Changes: diffSnapshots(*a.Snapshot, *b.Snapshot)}, nil
+}
+
+// RollbackProgram sets a program's fields back to how they were at an
+// earlier revision. The rollback is an update like any other, recorded as a
+// new revision, except that the restored fields only have to fit the
+// programs table: a revision without a category or a duration, or with a
+// title longer than editors may now set, can still be restored.
+func (s *ProgramService) RollbackProgram(ctx context.Context, req RollbackProgramRequest) (*database.UpdateProgramRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid rollback program request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	revision, err := s.GetProgramRevision(ctx, req.ID, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rolling back program", "id", req.ID, "revision", req.Revision)
+
+	return s.updateProgram(ctx, req.ID, pgtype.Int4{Int32: req.Revision, Valid: true}, func(ProgramSnapshot) (UpdateProgramRequest, error) {
+		update := revision.Snapshot.updateRequest(req.ID)
+		update.UserID = req.UserID
+		return update, nil
+	})
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	category := uuid.New()
+	before := ProgramSnapshot{Title: "تقنية بودكاست", Description: "قديم", CategoryID: &category, Language: "ar", Duration: 1800}
+	after := before
+	after.Description = ""
+	after.CategoryID = nil
+
+	changes := diffSnapshots(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("Expected description and category to change, got %v", changes)
+	}
+	if c := changes["description"]; c.From != "قديم" || c.To != "" {
+		t.Errorf("Unexpected description change %+v", c)
+	}
+	if c := changes["category_id"]; c.From != category.String() || c.To != nil {
+		t.Errorf("Unexpected category change %+v", c)
+	}
+
+	if changes := diffSnapshots(before, before); changes == nil || len(changes) != 0 {
+		t.Errorf("Expected no changes between equal snapshots, got %v", changes)
+	}
+}
+
+func TestProgramSnapshot_RoundTrip(t *testing.T) {
+	req := UpdateProgramRequest{ID: uuid.New(), Title: "برنامج", CategoryID: uuid.New(), Language: "ar", Duration: 600, Locked: true}
+
+	raw, err := json.Marshal(req.snapshot())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var stored ProgramSnapshot
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if changes := diffSnapshots(req.snapshot(), stored); len(changes) != 0 {
+		t.Errorf("Expected a stored snapshot to equal the original, got changes %v", changes)
+	}
+	if got := stored.updateRequest(req.ID); !reflect.DeepEqual(got, req) {
+		t.Errorf("Expected rolling back to rebuild the update, got %+v", got)
+	}
+}
+
+// recordRevisions makes db keep the snapshot of every revision created, for
+// programs that already have revisions.
+func recordRevisions(t *testing.T, db *fakeDB) *[]ProgramSnapshot {
+	var snapshots []ProgramSnapshot
+	db.on("HasProgramRevisions", func([]any) ([]any, error) { return []any{true}, nil })
+	db.on("CreateProgramRevision", func(args []any) ([]any, error) {
+		var snapshot ProgramSnapshot
+		if err := json.Unmarshal(args[2].([]byte), &snapshot); err != nil {
+			t.Errorf("Invalid revision snapshot: %v", err)
+		}
+		snapshots = append(snapshots, snapshot)
+		return []any{int32(len(snapshots) + 1)}, nil
+	})
+	return &snapshots
+}
+
+func TestRollbackProgram_LegacyRevision(t *testing.T) {
+	db := newFakeDB(t)
+	p := newStoredProgram()
+	storedProgram(db, p)
+	db.on("GetProgram", func([]any) ([]any, error) {
+		return []any{database.GetProgramRow{ID: p.ID, Title: p.Title, Version: p.Version}}, nil
+	})
+	// Imported before titles were limited to 100 characters, without a
+	// duration, and since left without a category.
+	legacy := ProgramSnapshot{Title: strings.Repeat("ب", 150), Language: "ar"}
+	db.on("GetProgramRevision", func([]any) ([]any, error) {
+		snapshot, _ := json.Marshal(legacy)
+		return []any{database.GetProgramRevisionRow{Revision: 1, Snapshot: snapshot, Changes: []byte("{}")}}, nil
+	})
+	s := newTestProgramService(t, db)
+
+	_, err := s.RollbackProgram(context.Background(), RollbackProgramRequest{ID: p.ID.Bytes, Revision: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.Title != legacy.Title || p.CategoryID.Valid || p.Duration.Valid {
+		t.Errorf("Expected the legacy fields to be restored, got title %q, category %v, duration %v", p.Title, p.CategoryID, p.Duration)
+	}
+
+	legacy.Title = strings.Repeat("ب", 256)
+	if _, err := s.RollbackProgram(context.Background(), RollbackProgramRequest{ID: p.ID.Bytes, Revision: 1}); err == nil {
+		t.Error("Expected a title longer than the column to be rejected")
+	}
+}
+
+func TestDeleteCategory_RecordsRevisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OrphanPolicy
+		target bool
+	}{
+		{"reassign", OrphanReassign, true},
+		{"nullify", OrphanNullify, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			category, target := uuid.New(), uuid.New()
+			program := database.GetCategoryProgramsForUpdateRow{
+				ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				Title:      "Tech Talk",
+				CategoryID: pgtype.UUID{Bytes: category, Valid: true},
+			}
+			db.on("GetCategory", func(args []any) ([]any, error) {
+				return []any{database.GetCategoryRow{ID: args[0].(pgtype.UUID)}}, nil
+			})
+			db.on("GetCategoryProgramsForUpdate", func([]any) ([]any, error) { return []any{program}, nil })
+			db.on("ReassignCategoryPrograms", func([]any) ([]any, error) { return []any{program.ID}, nil })
+			db.on("ReparentChildCategories", func([]any) ([]any, error) { return nil, nil })
+			db.on("DeleteCategory", func([]any) ([]any, error) { return []any{1}, nil })
+			snapshots := recordRevisions(t, db)
+			s := newTestProgramService(t, db)
+
+			req := DeleteCategoryRequest{ID: category, Policy: tt.policy}
+			if tt.target {
+				req.TargetID = target
+			}
+			if _, err := s.DeleteCategory(context.Background(), req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(*snapshots) != 1 {
+				t.Fatalf("Expected one revision for the moved program, got %d", len(*snapshots))
+			}
+			got := (*snapshots)[0].CategoryID
+			if tt.target && (got == nil || *got != target) {
+				t.Errorf("Expected the revision to move the program to %s, got %v", target, got)
+			}
+			if !tt.target && got != nil {
+				t.Errorf("Expected the revision to leave the program without a category, got %v", got)
+			}
+		})
+	}
+}
+
+func TestImportProgram_RecordsRevisionOnReimport(t *testing.T) {
+	db := newFakeDB(t)
+	previous := database.GetImportedProgramForUpdateRow{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Title:    "بودكاست قديم",
+		Duration: pgtype.Int4{Int32: 1200, Valid: true},
+	}
+	db.on("GetImportedProgramForUpdate", func([]any) ([]any, error) { return []any{previous}, nil })
+	db.on("UpsertImportedProgram", func(args []any) ([]any, error) {
+		return []any{database.UpsertImportedProgramRow{ID: previous.ID, Title: args[0].(string), Duration: args[3].(pgtype.Int4)}}, nil
+	})
+	snapshots := recordRevisions(t, db)
+	s := newTestProgramService(t, db)
+
+	podcast := sources.Podcast{ExternalID: "42", Title: "بودكاست جديد", Duration: 1200}
+	if _, err := s.ImportProgram(context.Background(), "itunes", podcast, uuid.Nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*snapshots) != 1 || (*snapshots)[0].Title != podcast.Title || (*snapshots)[0].Duration != 1200 {
+		t.Errorf("Expected one revision with the new title, got %+v", *snapshots)
+	}
+
+	// Importing the same podcast again changes nothing.
+	previous.Title = podcast.Title
+	if _, err := s.ImportProgram(context.Background(), "itunes", podcast, uuid.Nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*snapshots) != 1 {
+		t.Errorf("Expected no revision for an unchanged program, got %d", len(*snapshots))
+	}
+}
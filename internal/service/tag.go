@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+const (
+	// MaxTagFilters is the most tags a list or search may be filtered by.
+	MaxTagFilters = 10
+	// MaxTagLength is the longest tag name, in characters.
+	MaxTagLength = 50
+	// tagFacetLimit caps the tag counts returned with search results.
+	tagFacetLimit = 20
+)
+
+// ErrInvalidTag is returned for an empty or overlong tag name, or for too
+// many tags in one filter.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// TagFacet counts the programs matching a search that carry a tag.
+type TagFacet struct {
+	Name     string `db:"name" json:"name"`
+	Programs int64  `db:"programs" json:"programs"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type AttachProgramTagsRequest struct {
+	ProgramID uuid.UUID `json:"program_id" validate:"required"`
+	Tags      []string  `json:"tags" validate:"required,min=1"`
+}
+
+// normalizeTags lowercases, trims and dedupes tag names and sorts them, so
+// equal filters share a cache entry.
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := strings.ToLower(strings.TrimSpace(name))
+		if tag == "" {
+			return nil, fmt.Errorf("%w: tag names cannot be empty", ErrInvalidTag)
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: tag '%s' is longer than %d characters", ErrInvalidTag, tag, MaxTagLength)
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) > MaxTagFilters {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidTag, MaxTagFilters)
+	}
+	return tags, nil
+}
+
+// tagFilterTags returns the cache tags of entries filtered by names.
+func tagFilterTags(names []string) []string {
+	tags := make([]string, len(names))
+	for i, name := range names {
+		tags[i] = cache.TagFilterTag(name)
+	}
+	return tags
+}
+
+func (s *ProgramService) ListTags(ctx context.Context) ([]database.ListTagsRow, error) {
+	return s.tags.Load(ctx, cache.TagsListKey(), func(ctx context.Context) ([]database.ListTagsRow, error) {
+		s.logger.Info("Getting all tags")
+		tags, err := s.q.ListTags(ctx)
+		if err != nil {
+			s.logger.Error("Failed to get tags", "error", err)
+			return nil, fmt.Errorf("failed to get tags: %w", err)
+		}
+		if tags == nil {
+			tags = []database.ListTagsRow{}
+		}
+
+		s.logger.Info("Successfully fetched tags", "count", len(tags))
+		return tags, nil
+	}, cache.TagTags)
+}
+
+func (s *ProgramService) CreateTag(ctx context.Context, req CreateTagRequest) (*database.CreateTagRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid create tag request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	names, err := normalizeTags([]string{req.Name})
+	if err != nil {
+		return nil, err
+	}
+	name := names[0]
+
+	s.logger.Info("Creating new tag", "name", name)
+
+	tag, err := s.q.CreateTag(ctx, name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // 23505 is unique_violation
+			s.logger.Warn("Attempted to create a tag that already exists", "name", name, "error", err)
+			return nil, &ErrAlreadyExists{Message: fmt.Sprintf("tag '%s' already exists", name)}
+		}
+		s.logger.Error("Failed to create tag", "name", name, "error", err)
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	cache.InvalidateTags(s.cache, cache.TagTags)
+
+	s.logger.Info("Tag created successfully", "name", name, "id", tag.ID)
+	return &tag, nil
+}
+
+// DeleteTag removes a tag and detaches it from every program.
+func (s *ProgramService) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info("Deleting tag", "id", id)
+
+	name, err := s.q.DeleteTag(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Tag not found for deletion", "id", id)
+			return fmt.Errorf("%w: tag with ID '%s' not found", ErrNotFound, id.String())
+		}
+		s.logger.Error("Failed to delete tag", "id", id, "error", err)
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	cache.InvalidateTags(s.cache, cache.TagFilterTag(name), cache.TagTags)
+
+	s.logger.Info("Tag deleted successfully", "id", id, "name", name)
+	return nil
+}
+
+func (s *ProgramService) GetProgramTags(ctx context.Context, programID uuid.UUID) ([]database.ListProgramTagsRow, error) {
+	if _, err := s.GetProgram(ctx, programID); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.q.ListProgramTags(ctx, pgtype.UUID{Bytes: programID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to get program tags", "program_id", programID, "error", err)
+		return nil, fmt.Errorf("failed to get program tags: %w", err)
+	}
+	if tags == nil {
+		tags = []database.ListProgramTagsRow{}
+	}
+	return tags, nil
+}
+
+// AttachProgramTags tags a program, creating tags that do not exist yet.
+// Tags the program already has are left alone. It returns all of the
+// program's tags. Programs in the trash cannot be tagged.
+func (s *ProgramService) AttachProgramTags(ctx context.Context, req AttachProgramTagsRequest) ([]database.ListProgramTagsRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid attach tags request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	names, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	programID := pgtype.UUID{Bytes: req.ProgramID, Valid: true}
+	s.logger.Info("Attaching tags to program", "program_id", req.ProgramID, "tags", names)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("Failed to begin attach tags transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// Locking the program also keeps it from being moved to the trash
+	// until the tags are attached.
+	if _, err := qtx.GetProgramForUpdate(ctx, programID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Program not found for tagging", "program_id", req.ProgramID)
+			return nil, fmt.Errorf("%w: program with ID '%s' not found", ErrNotFound, req.ProgramID.String())
+		}
+		s.logger.Error("Failed to get program for tagging", "program_id", req.ProgramID, "error", err)
+		return nil, fmt.Errorf("failed to get program: %w", err)
+	}
+
+	ids, err := qtx.UpsertTags(ctx, names)
+	if err != nil {
+		s.logger.Error("Failed to upsert tags", "tags", names, "error", err)
+		return nil, fmt.Errorf("failed to upsert tags: %w", err)
+	}
+
+	err = qtx.AttachProgramTags(ctx, database.AttachProgramTagsParams{
+		ProgramID: programID,
+		TagIds:    ids,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // 23503 is foreign_key_violation
+			s.logger.Info("Program not found for tagging", "program_id", req.ProgramID)
+			return nil, fmt.Errorf("%w: program with ID '%s' not found", ErrNotFound, req.ProgramID.String())
+		}
+		s.logger.Error("Failed to attach tags", "program_id", req.ProgramID, "error", err)
+		return nil, fmt.Errorf("failed to attach tags: %w", err)
+	}
+
+	tags, err := qtx.ListProgramTags(ctx, programID)
+	if err != nil {
+		s.logger.Error("Failed to get program tags", "program_id", req.ProgramID, "error", err)
+		return nil, fmt.Errorf("failed to get program tags: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error("Failed to commit attach tags transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	cache.InvalidateTags(s.cache, append(tagFilterTags(names), cache.TagTags)...)
+
+	s.logger.Info("Tags attached successfully", "program_id", req.ProgramID, "tags", names)
+	return tags, nil
+}
+
+func (s *ProgramService) DetachProgramTag(ctx context.Context, programID uuid.UUID, name string) error {
+	names, err := normalizeTags([]string{name})
+	if err != nil {
+		return err
+	}
+	name = names[0]
+
+	if _, err := s.GetProgram(ctx, programID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Detaching tag from program", "program_id", programID, "tag", name)
+
+	rows, err := s.q.DetachProgramTag(ctx, database.DetachProgramTagParams{
+		ProgramID: pgtype.UUID{Bytes: programID, Valid: true},
+		Name:      name,
+	})
+	if err != nil {
+		s.logger.Error("Failed to detach tag", "program_id", programID, "tag", name, "error", err)
+		return fmt.Errorf("failed to detach tag: %w", err)
+	}
+	if rows == 0 {
+		s.logger.Info("Program does not have tag", "program_id", programID, "tag", name)
+		return fmt.Errorf("%w: program with ID '%s' has no tag '%s'", ErrNotFound, programID.String(), name)
+	}
+
+	cache.InvalidateTags(s.cache, cache.TagFilterTag(name), cache.TagTags)
+
+	s.logger.Info("Tag detached successfully", "program_id", programID, "tag", name)
+	return nil
+}
+
+// SearchTagFacets counts the tags of every program matching a search, so
+// clients can offer them as further filters. The sort, cursor and limit of
+// req are ignored.
+func (s *ProgramService) SearchTagFacets(ctx context.Context, req SearchRequest) ([]TagFacet, error) {
+	query, filter, err := s.searchFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return []TagFacet{}, nil
+	}
+	filter.Sort = ""
+
+	// Counts change with the programs the query matches and with tagging,
+	// hence TagSearch and TagTags.
+	return s.facets.Load(ctx, cache.ProgramsFacetsKey(query, filter.cacheKey()), func(ctx context.Context) ([]TagFacet, error) {
+		q := newProgramQuery(query, filter)
+		rows, err := s.db.Query(ctx, q.facetsSQL(tagFacetLimit), q.args...)
+		if err != nil {
+			s.logger.Error("Failed to count search tag facets", "query", req.Query, "error", err)
+			return nil, fmt.Errorf("failed to count tag facets: %w", err)
+		}
+
+		facets, err := pgx.CollectRows(rows, pgx.RowToStructByName[TagFacet])
+		if err != nil {
+			s.logger.Error("Failed to count search tag facets", "query", req.Query, "error", err)
+			return nil, fmt.Errorf("failed to count tag facets: %w", err)
+		}
+		return facets, nil
+	}, cache.TagSearch, cache.TagTags)
+}
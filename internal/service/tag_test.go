@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := normalizeTags([]string{" Startups", "ai", "AI ", "ذكاء"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"ai", "startups", "ذكاء"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	got, err = normalizeTags(nil)
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty non-nil filter, got %#v, %v", got, err)
+	}
+}
+
+func TestNormalizeTags_Invalid(t *testing.T) {
+	tooMany := make([]string, MaxTagFilters+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("t", i+1)
+	}
+
+	for name, names := range map[string][]string{
+		"empty":    {"ai", "  "},
+		"too long": {strings.Repeat("ت", MaxTagLength+1)},
+		"too many": tooMany,
+	} {
+		if _, err := normalizeTags(names); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("%s: expected ErrInvalidTag, got %v", name, err)
+		}
+	}
+
+	// Duplicates count once towards the limit.
+	dupes := slices.Repeat([]string{"ai"}, MaxTagFilters+1)
+	if _, err := normalizeTags(dupes); err != nil {
+		t.Errorf("Expected duplicates to collapse, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/cache"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// trashPurgeInterval is how often RunTrashPurge looks for expired programs.
+const trashPurgeInterval = time.Hour
+
+// ListTrash returns a page of the programs in the trash, most recently
+// deleted first. The trash is not cached, as only editors read it and every
+// deletion changes it.
+func (s *ProgramService) ListTrash(ctx context.Context, req PageRequest) (*Page[database.ListDeletedProgramsRow], error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid list trash request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// The cursor's timestamp is the deletion time of the last program.
+	ks, err := req.keyset()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.ListDeletedPrograms(ctx, database.ListDeletedProgramsParams{
+		CursorDeletedAt: ks.CreatedAt,
+		CursorID:        ks.ID,
+		PageLimit:       ks.Limit,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list trash", "error", err)
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	if rows == nil {
+		rows = []database.ListDeletedProgramsRow{}
+	}
+
+	return newPage(rows, ks, func(p database.ListDeletedProgramsRow) cursor {
+		return rowCursor(p.DeletedAt, p.ID)
+	}), nil
+}
+
+// RestoreProgram takes a program out of the trash, as it was when deleted.
+func (s *ProgramService) RestoreProgram(ctx context.Context, id uuid.UUID) (*database.GetProgramRow, error) {
+	s.logger.Info("Restoring program", "id", id)
+
+	program, err := s.q.RestoreProgram(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("Program not found in trash", "id", id)
+			return nil, fmt.Errorf("%w: program with ID '%s' is not in the trash", ErrNotFound, id.String())
+		}
+		s.logger.Error("Failed to restore program", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to restore program: %w", err)
+	}
+
+	// The program reappears wherever it belongs, like a new one, and counts
+	// towards its tags again.
+	tags := []string{cache.ProgramTag(id.String()), cache.TagProgramsList, cache.TagSearch, cache.TagTags}
+	if program.CategoryID.Valid {
+		tags = append(tags, cache.CategoryTag(uuid.UUID(program.CategoryID.Bytes).String()))
+	}
+	cache.InvalidateTags(s.cache, tags...)
+
+	s.logger.Info("Program restored successfully", "id", id)
+	return s.GetProgram(ctx, id)
+}
+
+// PurgeTrash deletes the programs that have been in the trash since before
+// deletedBefore for good, and returns how many there were. They are no longer
+// shown anywhere, so no cache entry changes.
+func (s *ProgramService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	purged, err := s.q.PurgeDeletedPrograms(ctx, pgtype.Timestamptz{Time: deletedBefore, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to purge trash", "error", err)
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	if purged > 0 {
+		s.logger.Info("Purged programs from trash", "count", purged, "deleted_before", deletedBefore)
+	}
+	return purged, nil
+}
+
+// RunTrashPurge purges programs that have been in the trash for longer than
+// retention, until ctx is done. Running it on every replica is safe.
+func (s *ProgramService) RunTrashPurge(ctx context.Context, retention time.Duration) {
+	s.logger.Info("Starting trash purge", "retention", retention)
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.PurgeTrash(ctx, time.Now().Add(-retention))
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping trash purge")
+			return
+		case <-ticker.C:
+		}
+	}
+}
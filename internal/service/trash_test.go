@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+func TestListTrash_Pages(t *testing.T) {
+	db := newFakeDB(t)
+	deletedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	trash := []any{
+		database.ListDeletedProgramsRow{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Title: "الأحدث", DeletedAt: pgtype.Timestamptz{Time: deletedAt, Valid: true}},
+		database.ListDeletedProgramsRow{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Title: "الأقدم", DeletedAt: pgtype.Timestamptz{Time: deletedAt.Add(-time.Hour), Valid: true}},
+	}
+	var calls []database.ListDeletedProgramsParams
+	db.on("ListDeletedPrograms", func(args []any) ([]any, error) {
+		arg := database.ListDeletedProgramsParams{CursorDeletedAt: args[0].(pgtype.Timestamptz), CursorID: args[1].(pgtype.UUID), PageLimit: args[2].(int32)}
+		calls = append(calls, arg)
+		if arg.CursorID.Valid {
+			return trash[1:], nil
+		}
+		return trash, nil
+	})
+	s := newTestProgramService(t, db)
+
+	first, err := s.ListTrash(context.Background(), PageRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(first.Items) != 1 || first.Items[0].Title != "الأحدث" || first.NextCursor == "" {
+		t.Fatalf("Expected the most recently deleted program and a cursor, got %+v", first)
+	}
+	if calls[0].PageLimit != 2 || calls[0].CursorID.Valid {
+		t.Errorf("Expected the first page to look one row ahead without a cursor, got %+v", calls[0])
+	}
+
+	second, err := s.ListTrash(context.Background(), PageRequest{Limit: 1, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(second.Items) != 1 || second.Items[0].Title != "الأقدم" || second.NextCursor != "" {
+		t.Fatalf("Expected the last program without a cursor, got %+v", second)
+	}
+	if !calls[1].CursorDeletedAt.Time.Equal(deletedAt) || calls[1].CursorID != trash[0].(database.ListDeletedProgramsRow).ID {
+		t.Errorf("Expected the second page to start after the first program, got %+v", calls[1])
+	}
+}
+
+func TestRestoreProgram(t *testing.T) {
+	db := newFakeDB(t)
+	id := uuid.New()
+	inTrash := true
+	db.on("RestoreProgram", func(args []any) ([]any, error) {
+		if args[0] != (pgtype.UUID{Bytes: id, Valid: true}) || !inTrash {
+			return nil, nil
+		}
+		inTrash = false
+		return []any{database.RestoreProgramRow{ID: args[0].(pgtype.UUID)}}, nil
+	})
+	db.on("GetProgram", func(args []any) ([]any, error) {
+		if inTrash {
+			return nil, nil
+		}
+		return []any{database.GetProgramRow{ID: args[0].(pgtype.UUID), Title: "Tech Talk", Version: 1}}, nil
+	})
+	s := newTestProgramService(t, db)
+
+	if _, err := s.GetProgram(context.Background(), id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a program in the trash not to be found, got %v", err)
+	}
+
+	program, err := s.RestoreProgram(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if program.Title != "Tech Talk" {
+		t.Errorf("Expected the restored program, got %+v", program)
+	}
+
+	if _, err := s.RestoreProgram(context.Background(), id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a program not in the trash, got %v", err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	db := newFakeDB(t)
+	deletedBefore := time.Now().Add(-30 * 24 * time.Hour)
+	db.on("PurgeDeletedPrograms", func(args []any) ([]any, error) {
+		if got := args[0].(pgtype.Timestamptz); !got.Valid || !got.Time.Equal(deletedBefore) {
+			t.Errorf("Expected programs deleted before %v to be purged, got %+v", deletedBefore, got)
+		}
+		return []any{1, 1}, nil
+	})
+	s := newTestProgramService(t, db)
+
+	purged, err := s.PurgeTrash(context.Background(), deletedBefore)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 programs purged, got %d", purged)
+	}
+}
+
+func TestTrashedProgram_Episodes(t *testing.T) {
+	db := newFakeDB(t)
+	programID, episodeID := uuid.New(), uuid.New()
+	inTrash := false
+	db.on("GetEpisode", func(args []any) ([]any, error) {
+		if inTrash {
+			return nil, nil
+		}
+		return []any{database.GetEpisodeRow{ID: args[0].(pgtype.UUID), ProgramID: args[1].(pgtype.UUID), Title: "الحلقة الأولى"}}, nil
+	})
+	db.on("SoftDeleteProgram", func([]any) ([]any, error) {
+		inTrash = true
+		return []any{1}, nil
+	})
+	db.on("CreateEpisode", func([]any) ([]any, error) { return nil, nil })
+	programs := newTestProgramService(t, db)
+	episodes := newTestEpisodeService(t, db, programs.cache)
+
+	if _, err := episodes.GetEpisode(context.Background(), programID, episodeID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := programs.DeleteProgram(context.Background(), programID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := episodes.GetEpisode(context.Background(), programID, episodeID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the cached episode of a trashed program not to be found, got %v", err)
+	}
+	_, err := episodes.CreateEpisode(context.Background(), CreateEpisodeRequest{
+		ProgramID: programID,
+		Title:     "الحلقة الثانية",
+		AudioURL:  "https://example.com/2.mp3",
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected no episode to be created for a trashed program, got %v", err)
+	}
+}
+
+func TestTrashedProgram_AttachTags(t *testing.T) {
+	db := newFakeDB(t)
+	db.on("GetProgramForUpdate", func([]any) ([]any, error) { return nil, nil })
+	s := newTestProgramService(t, db)
+
+	_, err := s.AttachProgramTags(context.Background(), AttachProgramTagsRequest{ProgramID: uuid.New(), Tags: []string{"تقنية"}})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for a trashed program, got %v", err)
+	}
+	if db.called("UpsertTags") != 0 {
+		t.Error("Expected no tags to be created for a trashed program")
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/khatibomar/gomania/internal/database"
+)
+
+// CMS user roles, matching the rows of the roles table.
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+type UserService struct {
+	db        DB
+	q         *database.Queries
+	logger    *slog.Logger
+	validator *validator.Validate
+}
+
+type CreateUserRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Role     string `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+type UpdateUserRoleRequest struct {
+	ID   uuid.UUID `json:"id" validate:"required"`
+	Role string    `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+func NewUserService(db DB, logger *slog.Logger) *UserService {
+	return &UserService{
+		db:        db,
+		q:         database.New(db),
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (s *UserService) CreateUser(ctx context.Context, req CreateUserRequest) (*database.CreateUserRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid create user request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		s.logger.Error("Failed to hash password", "email", req.Email, "error", err)
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	s.logger.Info("Creating new user", "email", req.Email, "role", req.Role)
+
+	user, err := s.q.CreateUser(ctx, database.CreateUserParams{
+		Email:        req.Email,
+		PasswordHash: hash,
+		Role:         req.Role,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // 23505 is unique_violation
+			s.logger.Warn("Attempted to create a user that already exists", "email", req.Email)
+			return nil, &ErrAlreadyExists{Message: fmt.Sprintf("user with email '%s' already exists", req.Email)}
+		}
+		s.logger.Error("Failed to create user", "email", req.Email, "error", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.logger.Info("User created successfully", "email", req.Email, "id", user.ID)
+	return &user, nil
+}
+
+func (s *UserService) ListUsers(ctx context.Context) ([]database.ListUsersRow, error) {
+	users, err := s.q.ListUsers(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list users", "error", err)
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (s *UserService) UpdateUserRole(ctx context.Context, req UpdateUserRoleRequest) (*database.UpdateUserRoleRow, error) {
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Error("Invalid update user role request", "error", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	s.logger.Info("Updating user role", "id", req.ID, "role", req.Role)
+
+	user, err := s.q.UpdateUserRole(ctx, database.UpdateUserRoleParams{
+		ID:   pgtype.UUID{Bytes: req.ID, Valid: true},
+		Role: req.Role,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info("User not found for role update", "id", req.ID)
+			return nil, fmt.Errorf("%w: user with ID '%s' not found", ErrNotFound, req.ID.String())
+		}
+		s.logger.Error("Failed to update user role", "id", req.ID, "error", err)
+		return nil, fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	s.logger.Info("User role updated successfully", "id", req.ID, "role", user.Role)
+	return &user, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info("Deleting user", "id", id)
+
+	rows, err := s.q.DeleteUser(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to delete user", "id", id, "error", err)
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if rows == 0 {
+		s.logger.Info("User not found for deletion", "id", id)
+		return fmt.Errorf("%w: user with ID '%s' not found", ErrNotFound, id.String())
+	}
+
+	s.logger.Info("User deleted successfully", "id", id)
+	return nil
+}
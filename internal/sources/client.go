@@ -2,6 +2,7 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -20,7 +21,16 @@ type Podcast struct {
 	ExternalID  string // external platform's ID
 }
 
+// ErrPodcastNotFound is returned by GetPodcast when a source has no podcast
+// with the given external ID.
+var ErrPodcastNotFound = errors.New("podcast not found")
+
+// ErrUnknownSource is returned when a source name is not registered.
+var ErrUnknownSource = errors.New("unknown source")
+
 type Client interface {
 	SearchPodcasts(ctx context.Context, term string, limit int) ([]Podcast, error)
+	// GetPodcast fetches a single podcast by its ExternalID.
+	GetPodcast(ctx context.Context, externalID string) (*Podcast, error)
 	GetSourceName() string
 }
@@ -17,6 +17,7 @@ var _ sources.Client = (*Client)(nil)
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	lookupURL  string
 }
 
 type SearchResponse struct {
@@ -45,7 +46,8 @@ func NewClient() *Client {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		baseURL: "https://itunes.apple.com/search",
+		baseURL:   "https://itunes.apple.com/search",
+		lookupURL: "https://itunes.apple.com/lookup",
 	}
 }
 
@@ -59,9 +61,46 @@ func (c *Client) SearchPodcasts(ctx context.Context, term string, limit int) ([]
 	params.Set("media", "podcast")
 	params.Set("limit", strconv.Itoa(limit))
 
-	searchURL := fmt.Sprintf("%s?%s", c.baseURL, params.Encode())
+	searchResp, err := c.get(ctx, fmt.Sprintf("%s?%s", c.baseURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert iTunes results to common Podcast format
+	podcasts := make([]sources.Podcast, 0, len(searchResp.Results))
+	for _, result := range searchResp.Results {
+		podcasts = append(podcasts, c.toPodcast(result))
+	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, searchURL, nil)
+	return podcasts, nil
+}
+
+// GetPodcast looks up a single podcast by its iTunes track ID.
+func (c *Client) GetPodcast(ctx context.Context, externalID string) (*sources.Podcast, error) {
+	if _, err := strconv.Atoi(externalID); err != nil {
+		return nil, fmt.Errorf("%w: invalid iTunes ID '%s'", sources.ErrPodcastNotFound, externalID)
+	}
+
+	params := url.Values{}
+	params.Set("id", externalID)
+	params.Set("entity", "podcast")
+
+	lookupResp, err := c.get(ctx, fmt.Sprintf("%s?%s", c.lookupURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range lookupResp.Results {
+		if strconv.Itoa(result.TrackID) == externalID {
+			podcast := c.toPodcast(result)
+			return &podcast, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: iTunes ID '%s'", sources.ErrPodcastNotFound, externalID)
+}
+
+func (c *Client) get(ctx context.Context, requestURL string) (*SearchResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -81,27 +120,24 @@ func (c *Client) SearchPodcasts(ctx context.Context, term string, limit int) ([]
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Convert iTunes results to common Podcast format
-	podcasts := make([]sources.Podcast, 0, len(searchResp.Results))
-	for _, result := range searchResp.Results {
-		podcast := sources.Podcast{
-			ID:          strconv.Itoa(result.TrackID),
-			Title:       result.TrackName,
-			Description: c.getDescription(result),
-			Host:        result.ArtistName,
-			Genre:       result.PrimaryGenreName,
-			Country:     result.Country,
-			Duration:    result.ToDuration(),
-			PublishedAt: c.parsePublishedAt(result),
-			ArtworkURL:  result.ArtworkURL600,
-			ExternalURL: result.TrackViewURL,
-			SourceName:  "itunes",
-			ExternalID:  strconv.Itoa(result.TrackID),
-		}
-		podcasts = append(podcasts, podcast)
-	}
+	return &searchResp, nil
+}
 
-	return podcasts, nil
+func (c *Client) toPodcast(result Result) sources.Podcast {
+	return sources.Podcast{
+		ID:          strconv.Itoa(result.TrackID),
+		Title:       result.TrackName,
+		Description: c.getDescription(result),
+		Host:        result.ArtistName,
+		Genre:       result.PrimaryGenreName,
+		Country:     result.Country,
+		Duration:    result.ToDuration(),
+		PublishedAt: c.parsePublishedAt(result),
+		ArtworkURL:  result.ArtworkURL600,
+		ExternalURL: result.TrackViewURL,
+		SourceName:  "itunes",
+		ExternalID:  strconv.Itoa(result.TrackID),
+	}
 }
 
 // ToDuration converts the track time in milliseconds to seconds.
@@ -2,18 +2,31 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 )
 
+// DefaultSourceTimeout bounds a single source's search when the manager is
+// created without an explicit timeout.
+const DefaultSourceTimeout = 5 * time.Second
+
 // Manager handles multiple external sources for podcast content
 type Manager struct {
-	clients map[string]Client
+	clients       map[string]Client
+	sourceTimeout time.Duration
 }
 
-// NewManager creates a new sources manager
-func NewManager() *Manager {
+// NewManager creates a new sources manager. sourceTimeout limits how long
+// each source may take during SearchAllSources; zero means DefaultSourceTimeout.
+func NewManager(sourceTimeout time.Duration) *Manager {
+	if sourceTimeout <= 0 {
+		sourceTimeout = DefaultSourceTimeout
+	}
 	return &Manager{
-		clients: make(map[string]Client),
+		clients:       make(map[string]Client),
+		sourceTimeout: sourceTimeout,
 	}
 }
 
@@ -28,32 +41,75 @@ func (m *Manager) GetClient(sourceName string) (Client, bool) {
 	return client, exists
 }
 
-// SearchAllSources searches across all registered sources
-func (m *Manager) SearchAllSources(ctx context.Context, term string, limit int) (map[string][]Podcast, error) {
-	results := make(map[string][]Podcast)
+// SearchAllSources searches all registered sources concurrently. Each source
+// gets its own timeout on top of any deadline already set on ctx, and a slow
+// or failing source never hides the results of the others.
+func (m *Manager) SearchAllSources(ctx context.Context, term string, limit int) *SearchResult {
+	result := &SearchResult{Sources: make(map[string]SourceResult, len(m.clients))}
 
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
 	for sourceName, client := range m.clients {
-		podcasts, err := client.SearchPodcasts(ctx, term, limit)
-		if err != nil {
-			// Log error but continue with other sources
-			continue
-		}
-		results[sourceName] = podcasts
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sr := m.searchSource(ctx, client, term, limit)
+
+			mu.Lock()
+			result.Sources[sourceName] = sr
+			mu.Unlock()
+		}()
 	}
+	wg.Wait()
+
+	return result
+}
+
+func (m *Manager) searchSource(ctx context.Context, client Client, term string, limit int) (sr SourceResult) {
+	ctx, cancel := context.WithTimeout(ctx, m.sourceTimeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		sr.Latency = time.Since(start)
+		if r := recover(); r != nil {
+			sr = SourceResult{Status: StatusError, Error: fmt.Sprintf("panic: %v", r), Latency: time.Since(start)}
+		}
+	}()
 
-	return results, nil
+	podcasts, err := client.SearchPodcasts(ctx, term, limit)
+	switch {
+	case err == nil:
+		return SourceResult{Status: StatusOK, Podcasts: podcasts}
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return SourceResult{Status: StatusTimeout, Error: "source did not respond in time"}
+	default:
+		return SourceResult{Status: StatusError, Error: err.Error()}
+	}
 }
 
 // SearchBySource searches a specific source
 func (m *Manager) SearchBySource(ctx context.Context, sourceName, term string, limit int) ([]Podcast, error) {
 	client, exists := m.GetClient(sourceName)
 	if !exists {
-		return nil, fmt.Errorf("source '%s' not found", sourceName)
+		return nil, fmt.Errorf("%w: source '%s' not found", ErrUnknownSource, sourceName)
 	}
 
 	return client.SearchPodcasts(ctx, term, limit)
 }
 
+// GetPodcast fetches a single podcast from a specific source
+func (m *Manager) GetPodcast(ctx context.Context, sourceName, externalID string) (*Podcast, error) {
+	client, exists := m.GetClient(sourceName)
+	if !exists {
+		return nil, fmt.Errorf("%w: source '%s' not found", ErrUnknownSource, sourceName)
+	}
+
+	return client.GetPodcast(ctx, externalID)
+}
+
 // GetAvailableSources returns list of registered source names
 func (m *Manager) GetAvailableSources() []string {
 	sources := make([]string, 0, len(m.clients))
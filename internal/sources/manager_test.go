@@ -0,0 +1,89 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	name  string
+	delay time.Duration
+	err   error
+	found []Podcast
+}
+
+func (c *fakeClient) SearchPodcasts(ctx context.Context, term string, limit int) ([]Podcast, error) {
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.found, nil
+}
+
+func (c *fakeClient) GetPodcast(ctx context.Context, externalID string) (*Podcast, error) {
+	for _, p := range c.found {
+		if p.ExternalID == externalID {
+			return &p, nil
+		}
+	}
+	return nil, ErrPodcastNotFound
+}
+
+func (c *fakeClient) GetSourceName() string {
+	return c.name
+}
+
+func TestManager_SearchAllSources(t *testing.T) {
+	m := NewManager(50 * time.Millisecond)
+	m.RegisterClient(&fakeClient{name: "fast", found: []Podcast{{Title: "one"}, {Title: "two"}}})
+	m.RegisterClient(&fakeClient{name: "broken", err: errors.New("boom")})
+	m.RegisterClient(&fakeClient{name: "slow", delay: time.Second})
+
+	start := time.Now()
+	result := m.SearchAllSources(context.Background(), "term", 10)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected sources to be searched concurrently, took %v", elapsed)
+	}
+
+	tests := map[string]SourceStatus{
+		"fast":   StatusOK,
+		"broken": StatusError,
+		"slow":   StatusTimeout,
+	}
+	for name, want := range tests {
+		if got := result.Sources[name].Status; got != want {
+			t.Errorf("Expected %s status %q, got %q", name, want, got)
+		}
+	}
+
+	if result.Sources["broken"].Error != "boom" {
+		t.Errorf("Expected error message to be reported, got %q", result.Sources["broken"].Error)
+	}
+	if result.Count() != 2 {
+		t.Errorf("Expected 2 podcasts, got %d", result.Count())
+	}
+
+	failed := result.Failed()
+	if len(failed) != 2 || failed[0] != "broken" || failed[1] != "slow" {
+		t.Errorf("Expected failed sources [broken slow], got %v", failed)
+	}
+}
+
+func TestManager_SearchAllSources_ContextDeadline(t *testing.T) {
+	m := NewManager(time.Minute)
+	m.RegisterClient(&fakeClient{name: "slow", delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result := m.SearchAllSources(ctx, "term", 10)
+	if got := result.Sources["slow"].Status; got != StatusTimeout {
+		t.Errorf("Expected request deadline to time out the source, got %q", got)
+	}
+}
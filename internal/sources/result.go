@@ -0,0 +1,71 @@
+package sources
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// SourceStatus reports how a single source fared during a fan-out search.
+type SourceStatus string
+
+const (
+	StatusOK      SourceStatus = "ok"
+	StatusError   SourceStatus = "error"
+	StatusTimeout SourceStatus = "timeout"
+)
+
+// SourceResult is the outcome of searching one source.
+type SourceResult struct {
+	Status   SourceStatus
+	Podcasts []Podcast
+	Latency  time.Duration
+	Error    string
+}
+
+func (r SourceResult) MarshalJSON() ([]byte, error) {
+	podcasts := r.Podcasts
+	if podcasts == nil {
+		podcasts = []Podcast{}
+	}
+	return json.Marshal(struct {
+		Status    SourceStatus `json:"status"`
+		LatencyMS int64        `json:"latency_ms"`
+		Error     string       `json:"error,omitempty"`
+		Count     int          `json:"count"`
+		Results   []Podcast    `json:"results"`
+	}{
+		Status:    r.Status,
+		LatencyMS: r.Latency.Milliseconds(),
+		Error:     r.Error,
+		Count:     len(r.Podcasts),
+		Results:   podcasts,
+	})
+}
+
+// SearchResult collects the per-source outcomes of SearchAllSources, keyed by
+// source name.
+type SearchResult struct {
+	Sources map[string]SourceResult
+}
+
+// Count returns the number of podcasts found across all sources.
+func (r *SearchResult) Count() int {
+	total := 0
+	for _, sr := range r.Sources {
+		total += len(sr.Podcasts)
+	}
+	return total
+}
+
+// Failed returns the sorted names of the sources that errored or timed out.
+func (r *SearchResult) Failed() []string {
+	failed := []string{}
+	for name, sr := range r.Sources {
+		if sr.Status != StatusOK {
+			failed = append(failed, name)
+		}
+	}
+	slices.Sort(failed)
+	return failed
+}
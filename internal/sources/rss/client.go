@@ -0,0 +1,202 @@
+// Package rss is a sources.Client backed by a fixed list of podcast RSS or
+// Atom feeds. Feeds are fetched on first use and refreshed once they are
+// older than the refresh interval; searches run over the ingested set.
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khatibomar/gomania/internal/arabic"
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+const sourceName = "rss"
+
+// DefaultRefreshInterval is how long ingested feeds are reused before they are
+// fetched again.
+const DefaultRefreshInterval = 15 * time.Minute
+
+// maxFeedSize caps how much of a single feed document is read.
+const maxFeedSize = 10 << 20
+
+var _ sources.Client = (*Client)(nil)
+
+type Client struct {
+	httpClient      *http.Client
+	feedURLs        []string
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	podcasts  []sources.Podcast
+	fetchedAt time.Time
+}
+
+func NewClient(feedURLs []string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		feedURLs:        feedURLs,
+		refreshInterval: DefaultRefreshInterval,
+	}
+}
+
+// SearchPodcasts returns the ingested feeds whose title, author, description
+// or category contain term. Matching uses Arabic normalization, so hamza and
+// diacritic variants match each other.
+func (c *Client) SearchPodcasts(ctx context.Context, term string, limit int) ([]sources.Podcast, error) {
+	podcasts, err := c.ingested(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	needle := arabic.Normalize(term)
+	if needle == "" {
+		return []sources.Podcast{}, nil
+	}
+
+	results := make([]sources.Podcast, 0)
+	for _, p := range podcasts {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		if matches(p, needle) {
+			results = append(results, p)
+		}
+	}
+
+	return results, nil
+}
+
+// GetPodcast returns the ingested feed whose URL is externalID. Only
+// configured feeds can be fetched; this is not an open proxy.
+func (c *Client) GetPodcast(ctx context.Context, externalID string) (*sources.Podcast, error) {
+	podcasts, err := c.ingested(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range podcasts {
+		if p.ExternalID == externalID {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: feed '%s' is not configured", sources.ErrPodcastNotFound, externalID)
+}
+
+func (c *Client) GetSourceName() string {
+	return sourceName
+}
+
+// Refresh fetches every configured feed. Feeds that fail keep their previous
+// entry, if any, so one broken feed does not drop the others; the combined
+// error describes every failure. The set only counts as fresh once some feed
+// loaded, so a refresh that failed outright is retried on the next call.
+func (c *Client) Refresh(ctx context.Context) error {
+	type fetched struct {
+		podcast *sources.Podcast
+		err     error
+	}
+
+	results := make([]fetched, len(c.feedURLs))
+	var wg sync.WaitGroup
+	for i, feedURL := range c.feedURLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := c.fetchFeed(ctx, feedURL)
+			results[i] = fetched{podcast: p, err: err}
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := make(map[string]sources.Podcast, len(c.podcasts))
+	for _, p := range c.podcasts {
+		previous[p.ExternalID] = p
+	}
+
+	var errs []error
+	podcasts := make([]sources.Podcast, 0, len(c.feedURLs))
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			if p, ok := previous[c.feedURLs[i]]; ok {
+				podcasts = append(podcasts, p)
+			}
+			continue
+		}
+		podcasts = append(podcasts, *r.podcast)
+	}
+
+	c.podcasts = podcasts
+	if len(podcasts) > 0 || len(errs) == 0 {
+		c.fetchedAt = time.Now()
+	}
+	return errors.Join(errs...)
+}
+
+// ingested returns the current feed set, refreshing it first if it is stale.
+// A partially failed refresh is not an error as long as some feeds loaded.
+func (c *Client) ingested(ctx context.Context) ([]sources.Podcast, error) {
+	c.mu.Lock()
+	stale := time.Since(c.fetchedAt) > c.refreshInterval
+	c.mu.Unlock()
+
+	if stale {
+		if err := c.Refresh(ctx); err != nil {
+			c.mu.Lock()
+			empty := len(c.podcasts) == 0
+			c.mu.Unlock()
+			if empty {
+				return nil, err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.podcasts, nil
+}
+
+func (c *Client) fetchFeed(ctx context.Context, feedURL string) (*sources.Podcast, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", feedURL, err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", feedURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed %s returned status: %d", feedURL, resp.StatusCode)
+	}
+
+	p, err := parseFeed(io.LimitReader(resp.Body, maxFeedSize), feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("feed %s: %w", feedURL, err)
+	}
+	return p, nil
+}
+
+func matches(p sources.Podcast, needle string) bool {
+	for _, field := range []string{p.Title, p.Host, p.Genre, p.Description} {
+		if strings.Contains(arabic.Normalize(field), needle) {
+			return true
+		}
+	}
+	return false
+}
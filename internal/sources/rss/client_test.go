@@ -0,0 +1,170 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fnjan.xml", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/fanjan.xml")
+	})
+	mux.HandleFunc("/tech.atom", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/tech.atom")
+	})
+	mux.HandleFunc("/broken.xml", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_RSSFeed(t *testing.T) {
+	srv := newFixtureServer(t)
+	client := NewClient([]string{srv.URL + "/fnjan.xml"})
+
+	podcasts, err := client.SearchPodcasts(context.Background(), "فنجان", 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(podcasts) != 1 {
+		t.Fatalf("Expected 1 podcast, got %d", len(podcasts))
+	}
+
+	p := podcasts[0]
+	checks := map[string][2]string{
+		"title":       {p.Title, "فنجان"},
+		"host":        {p.Host, "ثمانية"},
+		"genre":       {p.Genre, "Society & Culture"},
+		"artwork":     {p.ArtworkURL, "https://example.com/fnjan/cover.jpg"},
+		"link":        {p.ExternalURL, "https://example.com/fnjan"},
+		"source":      {p.SourceName, "rss"},
+		"external id": {p.ExternalID, srv.URL + "/fnjan.xml"},
+	}
+	for name, c := range checks {
+		if c[0] != c[1] {
+			t.Errorf("Expected %s %q, got %q", name, c[1], c[0])
+		}
+	}
+
+	// Duration and publish date come from the newest item.
+	if p.Duration != 3723 {
+		t.Errorf("Expected duration 3723, got %d", p.Duration)
+	}
+	want := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	if p.PublishedAt == nil || !p.PublishedAt.Equal(want) {
+		t.Errorf("Expected published at %v, got %v", want, p.PublishedAt)
+	}
+}
+
+func TestClient_AtomFeed(t *testing.T) {
+	srv := newFixtureServer(t)
+	client := NewClient([]string{srv.URL + "/tech.atom"})
+
+	podcasts, err := client.SearchPodcasts(context.Background(), "tech", 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(podcasts) != 1 {
+		t.Fatalf("Expected 1 podcast, got %d", len(podcasts))
+	}
+
+	p := podcasts[0]
+	if p.Title != "Tech Weekly" || p.Host != "Tech Team" || p.Genre != "Technology" {
+		t.Errorf("Unexpected podcast metadata: %+v", p)
+	}
+	if p.ExternalURL != "https://example.com/tech" {
+		t.Errorf("Expected alternate link, got %q", p.ExternalURL)
+	}
+	if p.ArtworkURL != "https://example.com/tech/logo.png" {
+		t.Errorf("Expected logo as artwork, got %q", p.ArtworkURL)
+	}
+}
+
+func TestClient_SearchNormalizesArabic(t *testing.T) {
+	srv := newFixtureServer(t)
+	client := NewClient([]string{srv.URL + "/fnjan.xml", srv.URL + "/tech.atom"})
+
+	// "التقنيه" only matches the Atom subtitle "التقنية" after taa marbuta folding.
+	podcasts, err := client.SearchPodcasts(context.Background(), "التقنيه", 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(podcasts) != 1 || podcasts[0].Title != "Tech Weekly" {
+		t.Errorf("Expected only Tech Weekly to match, got %+v", podcasts)
+	}
+}
+
+func TestClient_PartialFailure(t *testing.T) {
+	srv := newFixtureServer(t)
+	client := NewClient([]string{srv.URL + "/fnjan.xml", srv.URL + "/broken.xml"})
+
+	if err := client.Refresh(context.Background()); err == nil {
+		t.Error("Expected Refresh to report the broken feed")
+	}
+
+	podcasts, err := client.SearchPodcasts(context.Background(), "فنجان", 10)
+	if err != nil {
+		t.Fatalf("Expected search to succeed with the remaining feeds, got %v", err)
+	}
+	if len(podcasts) != 1 {
+		t.Errorf("Expected 1 podcast, got %d", len(podcasts))
+	}
+}
+
+func TestClient_AllFeedsFail(t *testing.T) {
+	srv := newFixtureServer(t)
+	client := NewClient([]string{srv.URL + "/broken.xml"})
+
+	if _, err := client.SearchPodcasts(context.Background(), "anything", 10); err == nil {
+		t.Error("Expected an error when no feed could be loaded")
+	}
+	if _, err := client.SearchPodcasts(context.Background(), "anything", 10); err == nil {
+		t.Error("Expected the failed refresh to be retried rather than cached")
+	}
+}
+
+func TestClient_GetPodcast(t *testing.T) {
+	srv := newFixtureServer(t)
+	feedURL := srv.URL + "/fnjan.xml"
+	client := NewClient([]string{feedURL})
+
+	p, err := client.GetPodcast(context.Background(), feedURL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.Title != "فنجان" {
+		t.Errorf("Expected فنجان, got %q", p.Title)
+	}
+
+	_, err = client.GetPodcast(context.Background(), srv.URL+"/tech.atom")
+	if !errors.Is(err, sources.ErrPodcastNotFound) {
+		t.Errorf("Expected ErrPodcastNotFound for an unconfigured feed, got %v", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]int{
+		"":         0,
+		"1800":     1800,
+		"45:30":    2730,
+		"01:02:03": 3723,
+		"abc":      0,
+	}
+	for in, want := range tests {
+		if got := parseDuration(in); got != want {
+			t.Errorf("parseDuration(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khatibomar/gomania/internal/sources"
+)
+
+const namespaceAtom = "http://www.w3.org/2005/Atom"
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// Namespaced fields come first: encoding/xml matches an untagged namespace
+// against any namespace, so <itunes:image> would otherwise land in Image and
+// the common <atom:link rel="self"/> would blank out Link.
+type rssChannel struct {
+	AtomLinks      []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+	ITunesTitle    string     `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	ITunesAuthor   string     `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	ITunesSummary  string     `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	ITunesImage    hrefAttr   `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	ITunesCategory []textAttr `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd category"`
+	Title          string     `xml:"title"`
+	Link           string     `xml:"link"`
+	Description    string     `xml:"description"`
+	ManagingEditor string     `xml:"managingEditor"`
+	PubDate        string     `xml:"pubDate"`
+	LastBuildDate  string     `xml:"lastBuildDate"`
+	Image          rssImage   `xml:"image"`
+	Items          []rssItem  `xml:"item"`
+}
+
+type rssItem struct {
+	PubDate        string `xml:"pubDate"`
+	ITunesDuration string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+}
+
+type rssImage struct {
+	URL string `xml:"url"`
+}
+
+type hrefAttr struct {
+	Href string `xml:"href,attr"`
+}
+
+type textAttr struct {
+	Text string `xml:"text,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Updated  string      `xml:"updated"`
+	Icon     string      `xml:"icon"`
+	Logo     string      `xml:"logo"`
+	Links    []atomLink  `xml:"link"`
+	Authors  []atomName  `xml:"author"`
+	Category []atomTerm  `xml:"category"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomName struct {
+	Name string `xml:"name"`
+}
+
+type atomTerm struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomEntry struct {
+	Updated   string `xml:"updated"`
+	Published string `xml:"published"`
+}
+
+// parseFeed decodes an RSS 2.0 or Atom document into a Podcast describing the
+// feed as a whole. feedURL becomes the podcast's external ID.
+func parseFeed(r io.Reader, feedURL string) (*sources.Podcast, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root.Local == "rss":
+		var doc rssDocument
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode RSS feed: %w", err)
+		}
+		return doc.Channel.toPodcast(feedURL), nil
+	case root.Local == "feed" && root.Space == namespaceAtom:
+		var doc atomFeed
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode Atom feed: %w", err)
+		}
+		return doc.toPodcast(feedURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root.Local)
+	}
+}
+
+func rootElement(body []byte) (xml.Name, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("failed to read feed root element: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func (c rssChannel) toPodcast(feedURL string) *sources.Podcast {
+	p := &sources.Podcast{
+		ID:          feedURL,
+		Title:       firstNonEmpty(c.Title, c.ITunesTitle),
+		Description: firstNonEmpty(c.Description, c.ITunesSummary),
+		Host:        firstNonEmpty(c.ITunesAuthor, c.ManagingEditor),
+		ArtworkURL:  firstNonEmpty(c.ITunesImage.Href, c.Image.URL),
+		ExternalURL: strings.TrimSpace(c.Link),
+		SourceName:  sourceName,
+		ExternalID:  feedURL,
+	}
+	if len(c.ITunesCategory) > 0 {
+		p.Genre = c.ITunesCategory[0].Text
+	}
+
+	// Items are not guaranteed to be sorted, so pick the newest one.
+	var latest *time.Time
+	for _, item := range c.Items {
+		t := parseTime(item.PubDate)
+		if t != nil && (latest == nil || t.After(*latest)) {
+			latest = t
+			p.Duration = parseDuration(item.ITunesDuration)
+		}
+	}
+	if latest == nil {
+		latest = parseTime(firstNonEmpty(c.PubDate, c.LastBuildDate))
+	}
+	p.PublishedAt = latest
+
+	return p
+}
+
+func (f atomFeed) toPodcast(feedURL string) *sources.Podcast {
+	p := &sources.Podcast{
+		ID:          feedURL,
+		Title:       strings.TrimSpace(f.Title),
+		Description: strings.TrimSpace(f.Subtitle),
+		ArtworkURL:  firstNonEmpty(f.Logo, f.Icon),
+		SourceName:  sourceName,
+		ExternalID:  feedURL,
+	}
+	if len(f.Authors) > 0 {
+		p.Host = strings.TrimSpace(f.Authors[0].Name)
+	}
+	if len(f.Category) > 0 {
+		p.Genre = firstNonEmpty(f.Category[0].Label, f.Category[0].Term)
+	}
+	for _, l := range f.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			p.ExternalURL = l.Href
+			break
+		}
+	}
+
+	var latest *time.Time
+	for _, e := range f.Entries {
+		t := parseTime(firstNonEmpty(e.Published, e.Updated))
+		if t != nil && (latest == nil || t.After(*latest)) {
+			latest = t
+		}
+	}
+	if latest == nil {
+		latest = parseTime(f.Updated)
+	}
+	p.PublishedAt = latest
+
+	return p
+}
+
+// timeLayouts covers RFC 822 dates as found in the wild (RSS) and RFC 3339 (Atom).
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+}
+
+func parseTime(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// parseDuration parses itunes:duration, which is either a number of seconds
+// or a [HH:]MM:SS clock value. Unparseable values yield 0.
+func parseDuration(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	total := 0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
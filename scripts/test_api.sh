@@ -15,6 +15,11 @@ NC='\033[0m' # No Color
 # API base URL
 BASE_URL="http://localhost:4000"
 
+# CMS credentials (seeded by `make db-seed`)
+CMS_EMAIL="${CMS_EMAIL:-admin@gomania.com}"
+CMS_PASSWORD="${CMS_PASSWORD:-gomania-admin}"
+AUTH_TOKEN=""
+
 echo -e "${GREEN}🧪 Testing Gomania API${NC}"
 echo -e "${YELLOW}Base URL: ${BASE_URL}${NC}"
 echo ""
@@ -29,12 +34,18 @@ test_endpoint() {
     echo -e "${BLUE}Testing: ${description}${NC}"
     echo -e "${YELLOW}${method} ${endpoint}${NC}"
 
+    local auth_header=()
+    if [ -n "$AUTH_TOKEN" ]; then
+        auth_header=(-H "Authorization: Bearer ${AUTH_TOKEN}")
+    fi
+
     if [ -n "$data" ]; then
         response=$(curl -s -w "\n%{http_code}" -X "$method" "${BASE_URL}${endpoint}" \
+                   "${auth_header[@]}" \
                    -H "Content-Type: application/json" \
                    -d "$data")
     else
-        response=$(curl -s -w "\n%{http_code}" -X "$method" "${BASE_URL}${endpoint}")
+        response=$(curl -s -w "\n%{http_code}" -X "$method" "${BASE_URL}${endpoint}" "${auth_header[@]}")
     fi
 
     # Extract status code (last line)
@@ -74,6 +85,26 @@ CATEGORY_ID=""
 # Test health check
 test_endpoint "GET" "/v1/healthcheck" "Health Check"
 
+# CMS routes must reject anonymous requests
+echo -e "${BLUE}Testing: CMS without token (should return 401)${NC}"
+status_code=$(curl -s -o /dev/null -w "%{http_code}" "${BASE_URL}/v1/cms/programs")
+if [ "$status_code" == "401" ]; then
+    echo -e "${GREEN}✅ Status: 401 (as expected)${NC}"
+else
+    echo -e "${RED}❌ Status: ${status_code} (expected 401)${NC}"
+fi
+echo ""
+
+# Log in to obtain a CMS token
+test_endpoint "POST" "/v1/auth/login" "Login" "{\"email\": \"${CMS_EMAIL}\", \"password\": \"${CMS_PASSWORD}\"}"
+AUTH_TOKEN=$(curl -s -X POST "${BASE_URL}/v1/auth/login" \
+             -H "Content-Type: application/json" \
+             -d "{\"email\": \"${CMS_EMAIL}\", \"password\": \"${CMS_PASSWORD}\"}" | jq -r '.authentication_token.token' 2>/dev/null || echo "")
+if [ -z "$AUTH_TOKEN" ] || [ "$AUTH_TOKEN" == "null" ]; then
+    echo -e "${RED}❌ Could not log in as ${CMS_EMAIL}, CMS tests will fail${NC}"
+    AUTH_TOKEN=""
+fi
+
 # Test categories
 test_endpoint "GET" "/v1/cms/categories" "List Categories"
 
@@ -82,7 +113,7 @@ test_endpoint "POST" "/v1/cms/categories" "Create Category" '{"name":"اختبا
 
 # Get categories again to extract ID
 echo -e "${BLUE}Getting category ID for tests...${NC}"
-categories_response=$(curl -s "${BASE_URL}/v1/cms/categories")
+categories_response=$(curl -s -H "Authorization: Bearer ${AUTH_TOKEN}" "${BASE_URL}/v1/cms/categories")
 CATEGORY_ID=$(echo "$categories_response" | jq -r '.categories[0].id' 2>/dev/null || echo "")
 
 if [ -n "$CATEGORY_ID" ] && [ "$CATEGORY_ID" != "null" ]; then
@@ -103,7 +134,7 @@ if [ -n "$CATEGORY_ID" ] && [ "$CATEGORY_ID" != "null" ]; then
 
     # Get program ID for further tests
     echo -e "${BLUE}Getting program ID for tests...${NC}"
-    programs_response=$(curl -s "${BASE_URL}/v1/cms/programs")
+    programs_response=$(curl -s -H "Authorization: Bearer ${AUTH_TOKEN}" "${BASE_URL}/v1/cms/programs")
     PROGRAM_ID=$(echo "$programs_response" | jq -r '.programs[0].id' 2>/dev/null || echo "")
 
     if [ -n "$PROGRAM_ID" ] && [ "$PROGRAM_ID" != "null" ]; then
@@ -122,6 +153,34 @@ if [ -n "$CATEGORY_ID" ] && [ "$CATEGORY_ID" != "null" ]; then
             \"duration\": 2400
         }"
 
+        # Test episodes
+        test_endpoint "POST" "/v1/cms/programs/${PROGRAM_ID}/episodes" "Create Episode" '{
+            "title": "الحلقة الأولى",
+            "description": "حلقة للاختبار",
+            "audio_url": "https://example.com/audio/ep1.mp3",
+            "duration": 1200,
+            "season_number": 1,
+            "episode_number": 1,
+            "published_at": "2024-01-15T10:00:00Z"
+        }'
+
+        test_endpoint "GET" "/v1/cms/programs/${PROGRAM_ID}/episodes" "List Program Episodes"
+
+        EPISODE_ID=$(curl -s -H "Authorization: Bearer ${AUTH_TOKEN}" "${BASE_URL}/v1/cms/programs/${PROGRAM_ID}/episodes" | jq -r '.episodes[0].id' 2>/dev/null || echo "")
+        if [ -n "$EPISODE_ID" ] && [ "$EPISODE_ID" != "null" ]; then
+            test_endpoint "GET" "/v1/cms/programs/${PROGRAM_ID}/episodes/${EPISODE_ID}" "Get Single Episode"
+
+            test_endpoint "PUT" "/v1/cms/programs/${PROGRAM_ID}/episodes/${EPISODE_ID}" "Update Episode" '{
+                "title": "الحلقة الأولى محدثة",
+                "audio_url": "https://example.com/audio/ep1.mp3",
+                "duration": 1300,
+                "season_number": 1,
+                "episode_number": 1
+            }'
+
+            test_endpoint "DELETE" "/v1/cms/programs/${PROGRAM_ID}/episodes/${EPISODE_ID}" "Delete Episode"
+        fi
+
         # Test programs by category
         test_endpoint "GET" "/v1/cms/categories/${CATEGORY_ID}/programs" "Get Programs by Category"
 
@@ -174,12 +233,17 @@ else
 fi
 echo ""
 
+# Log out, revoking the token
+test_endpoint "POST" "/v1/auth/logout" "Logout"
+
 echo -e "${GREEN}🎉 API testing completed!${NC}"
 echo ""
 echo -e "${GREEN}📊 Summary:${NC}"
 echo -e "${GREEN}   ✅ Health check${NC}"
+echo -e "${GREEN}   ✅ CMS authentication${NC}"
 echo -e "${GREEN}   ✅ Category management${NC}"
 echo -e "${GREEN}   ✅ Program management${NC}"
+echo -e "${GREEN}   ✅ Episode management${NC}"
 echo -e "${GREEN}   ✅ Discovery API${NC}"
 echo -e "${GREEN}   ✅ Search functionality${NC}"
 echo -e "${GREEN}   ✅ Arabic content support${NC}"
@@ -188,6 +252,8 @@ echo -e "${GREEN}   ✅ iTunes API integration${NC}"
 echo ""
 echo -e "${BLUE}🔗 Available endpoints:${NC}"
 echo -e "${YELLOW}   GET    /v1/healthcheck${NC}"
+echo -e "${YELLOW}   POST   /v1/auth/login${NC}"
+echo -e "${YELLOW}   POST   /v1/auth/logout${NC}"
 echo -e "${YELLOW}   GET    /v1/programs${NC}"
 echo -e "${YELLOW}   GET    /v1/programs?q=search${NC}"
 echo -e "${YELLOW}   GET    /v1/external/sources${NC}"
@@ -200,3 +266,8 @@ echo -e "${YELLOW}   POST   /v1/cms/programs${NC}"
 echo -e "${YELLOW}   GET    /v1/cms/programs/{id}${NC}"
 echo -e "${YELLOW}   PUT    /v1/cms/programs/{id}${NC}"
 echo -e "${YELLOW}   DELETE /v1/cms/programs/{id}${NC}"
+echo -e "${YELLOW}   GET    /v1/cms/programs/{id}/episodes${NC}"
+echo -e "${YELLOW}   POST   /v1/cms/programs/{id}/episodes${NC}"
+echo -e "${YELLOW}   GET    /v1/cms/programs/{id}/episodes/{episode_id}${NC}"
+echo -e "${YELLOW}   PUT    /v1/cms/programs/{id}/episodes/{episode_id}${NC}"
+echo -e "${YELLOW}   DELETE /v1/cms/programs/{id}/episodes/{episode_id}${NC}"